@@ -0,0 +1,154 @@
+// Package app wires the repositories, services, middlewares and routes of the API together
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"madang_api/config"
+	"madang_api/middleware"
+	"madang_api/ratelimit"
+	"madang_api/repositories"
+	"madang_api/routes"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Container holds the single instance of every repository and service of the application
+type Container struct {
+	Config *config.Config
+	DB     *gorm.DB
+
+	Users           repositories.UserRepository
+	Restaurants     repositories.RestaurantRepository
+	Categories      repositories.CategoryRepository
+	Foods           repositories.FoodRepository
+	Tables          repositories.TableRepository
+	Addons          repositories.AddonRepository
+	Orders          repositories.OrderRepository
+	Payments        repositories.PaymentRepository
+	Transactions    repositories.TransactionRepository
+	Notifications   repositories.NotificationRepository
+	Recommendations repositories.RecommendationRepository
+
+	UserService           *services.UserService
+	RestaurantService     *services.RestaurantService
+	CategoryService       *services.CategoryService
+	FoodService           *services.FoodService
+	TableService          *services.TableService
+	AddonService          *services.AddonService
+	OrderService          *services.OrderService
+	PaymentService        *services.PaymentService
+	TransactionService    *services.TransactionService
+	InventoryService      *services.InventoryService
+	MenuService           *services.MenuService
+	SearchService         *services.SearchService
+	OpeningHoursService   *services.OpeningHoursService
+	VerificationService   *services.VerificationService
+	NotificationService   *services.NotificationService
+	StaffService          *services.StaffService
+	BrandService          *services.BrandService
+	RecommendationService *services.RecommendationService
+	FeedService           *services.FeedService
+
+	HealthService *services.HealthService
+
+	// Middleware holds the settings and lookups of the middlewares of this container's routes
+	Middleware *middleware.Middleware
+
+	workers        sync.WaitGroup
+	stopWorkers    context.CancelFunc
+	workersMu      sync.Mutex
+	workersRunning map[string]bool
+}
+
+// NewContainer builds the repositories on db, the services on top of them and configures the middlewares
+func NewContainer(cfg *config.Config, db *gorm.DB) *Container {
+	c := &Container{Config: cfg, DB: db}
+
+	c.Users = repositories.NewUserRepository(db)
+	c.Restaurants = repositories.NewRestaurantRepository(db)
+	c.Categories = repositories.NewCategoryRepository(db)
+	c.Foods = repositories.NewFoodRepository(db)
+	c.Tables = repositories.NewTableRepository(db)
+	c.Addons = repositories.NewAddonRepository(db)
+	c.Orders = repositories.NewOrderRepository(db)
+	c.Payments = repositories.NewPaymentRepository(db)
+	c.Transactions = repositories.NewTransactionRepository(db)
+	c.Notifications = repositories.NewNotificationRepository(db)
+	c.Recommendations = repositories.NewRecommendationRepository(db)
+
+	c.UserService = services.NewUserService(cfg, c.Users)
+	c.RestaurantService = services.NewRestaurantService(c.Restaurants, c.Users)
+	c.CategoryService = services.NewCategoryService(c.Categories)
+	c.FoodService = services.NewFoodService(c.Foods, c.Recommendations)
+	c.TableService = services.NewTableService(c.Tables, c.Recommendations)
+	c.AddonService = services.NewAddonService(c.Addons)
+	c.OrderService = services.NewOrderService(db, c.Orders, c.Restaurants)
+	c.PaymentService = services.NewPaymentService(c.Payments)
+	c.TransactionService = services.NewTransactionService(c.Transactions)
+	c.InventoryService = services.NewInventoryService(db)
+	c.MenuService = services.NewMenuService(db)
+	c.SearchService = services.NewSearchService(c.Restaurants, c.Foods, c.Addons, c.Tables, c.Categories)
+	c.OpeningHoursService = services.NewOpeningHoursService(db)
+	c.VerificationService = services.NewVerificationService(db)
+	c.NotificationService = services.NewNotificationService(c.Notifications)
+	c.BrandService = services.NewBrandService(db)
+	c.StaffService = services.NewStaffService(db, c.BrandService)
+	c.RecommendationService = services.NewRecommendationService(db)
+	c.FeedService = services.NewFeedService(cfg, db, c.RestaurantService, c.CategoryService, c.FoodService, c.TableService)
+	c.HealthService = services.NewHealthService(db, c.Workers)
+
+	c.Middleware = middleware.New(cfg, middleware.Dependencies{
+		Users:       c.Users,
+		Restaurants: c.Restaurants,
+		Orders:      c.Orders,
+		Staff:       c.StaffService,
+		Brands:      c.BrandService,
+		RateLimits:  ratelimit.NewMemoryStore(),
+	})
+	return c
+}
+
+// Router sets up every route of the API on a new gin engine
+func (c *Container) Router() *gin.Engine {
+	router := gin.New()
+	// the client IP rate limits and logs rely on comes from X-Forwarded-For only behind a trusted proxy,
+	// anyone else could pick their own. The proxies are validated with the configuration
+	if err := router.SetTrustedProxies(c.Config.Proxies()); err != nil {
+		slog.Error("Invalid trusted proxies, trusting none", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	router.Use(middleware.RequestID, middleware.Tracing, middleware.RequestLogger, middleware.Metrics, middleware.Recovery, c.Middleware.SignCursors)
+
+	// the probes and the metrics come first and answer without authentication. They are not rate limited:
+	// the routes only get the middlewares added before them
+	routes.SetupHealthRoutes(router, c.HealthService)
+	routes.SetupMetricsRoutes(router, c.Config.MetricsToken)
+	router.Use(c.Middleware.RateLimit)
+
+	routes.SetupUserRoutes(router, c.Middleware, c.UserService)
+	routes.SetupRestaurantRoutes(router, c.Middleware, c.RestaurantService)
+	routes.SetupCategoryRoutes(router, c.Middleware, c.CategoryService)
+	routes.SetupFoodRoutes(router, c.Middleware, c.FoodService)
+	routes.SetupTableRoutes(router, c.Middleware, c.TableService)
+	routes.SetupAddonRoutes(router, c.Middleware, c.AddonService)
+	routes.SetupOrderRoutes(router, c.Middleware, c.OrderService)
+	routes.SetupPaymentRoutes(router, c.Middleware, c.PaymentService)
+	routes.SetupTransactionRoutes(router, c.Middleware, c.TransactionService)
+	routes.SetupInventoryRoutes(router, c.Middleware, c.InventoryService)
+	routes.SetupMenuRoutes(router, c.Middleware, c.MenuService)
+	routes.SetupSearchRoutes(router, c.Middleware, c.SearchService)
+	routes.SetupOpeningHoursRoutes(router, c.Middleware, c.OpeningHoursService)
+	routes.SetupVerificationRoutes(router, c.Middleware, c.VerificationService)
+	routes.SetupNotificationRoutes(router, c.Middleware, c.NotificationService)
+	routes.SetupStaffRoutes(router, c.Middleware, c.StaffService)
+	routes.SetupBrandRoutes(router, c.Middleware, c.BrandService)
+	routes.SetupRecommendationRoutes(router, c.Middleware, c.RecommendationService)
+	routes.SetupInitRoutes(router, c.Middleware, c.FeedService)
+
+	return router
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+)
+
+// StartWorkers starts the background jobs of the application. They run until StopWorkers
+func (c *Container) StartWorkers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopWorkers = cancel
+
+	// Keep the recommendations up to date in the background
+	c.runWorker("recommendations", func() {
+		c.RecommendationService.Start(ctx, c.Config.RecommendationInterval)
+	})
+}
+
+func (c *Container) runWorker(name string, run func()) {
+	c.setWorkerRunning(name, true)
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		run()
+		c.setWorkerRunning(name, false)
+		slog.Info("Worker stopped", "worker", name)
+	}()
+	slog.Info("Worker started", "worker", name)
+}
+
+func (c *Container) setWorkerRunning(name string, running bool) {
+	c.workersMu.Lock()
+	defer c.workersMu.Unlock()
+	if c.workersRunning == nil {
+		c.workersRunning = make(map[string]bool)
+	}
+	c.workersRunning[name] = running
+}
+
+// Workers tells which of the started workers are still running. It is empty before StartWorkers
+func (c *Container) Workers() map[string]bool {
+	c.workersMu.Lock()
+	defer c.workersMu.Unlock()
+	workers := make(map[string]bool, len(c.workersRunning))
+	for name, running := range c.workersRunning {
+		workers[name] = running
+	}
+	return workers
+}
+
+// StopWorkers keeps the workers from starting new jobs and waits for the jobs in progress, at most until
+// ctx is done
+func (c *Container) StopWorkers(ctx context.Context) error {
+	if c.stopWorkers == nil {
+		return nil
+	}
+	c.stopWorkers()
+	// the API is not ready anymore from now on, even while the jobs in progress finish
+	c.workersMu.Lock()
+	for name := range c.workersRunning {
+		c.workersRunning[name] = false
+	}
+	c.workersMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
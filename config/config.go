@@ -1,16 +1,209 @@
 package config
 
-import "madang_api/models"
-
-func SyncDatabase() {
-	DB.AutoMigrate(&models.User{})
-	DB.AutoMigrate(&models.Restaurant{})
-	DB.AutoMigrate(&models.Category{})
-	DB.AutoMigrate(&models.Food{})
-	DB.AutoMigrate(&models.Table{})
-	DB.AutoMigrate(&models.Addon{})
-	DB.AutoMigrate(&models.Order{})
-	DB.AutoMigrate(&models.Payment{})
-	DB.AutoMigrate(&models.Transaction{})
-	// DB.AutoMigrate(&models.Rating{})
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the application. Every field is read from, by increasing priority, its
+// default, the YAML file named by CONFIG_FILE (config.yaml when present), the .env file in development
+// and the environment variable named by its env tag
+type Config struct {
+	Env                    string        `env:"GO_ENV" yaml:"env"`
+	Port                   string        `env:"PORT" yaml:"port" default:"8080"`
+	DatabaseURL            string        `env:"DB_URL" yaml:"db_url" required:"true" secret:"true"`
+	JWTSecret              string        `env:"JWT_SECRET" yaml:"jwt_secret" required:"true" secret:"true"`
+	CursorSecret           string        `env:"CURSOR_SECRET" yaml:"cursor_secret" secret:"true"` // defaults to JWTSecret
+	DefaultRestaurantID    uint          `env:"DEFAULT_RESTAURANT_ID" yaml:"default_restaurant_id"`
+	RecommendationInterval time.Duration `env:"RECOMMENDATION_INTERVAL" yaml:"recommendation_interval" default:"1h"`
+	FeedSectionTimeout     time.Duration `env:"FEED_SECTION_TIMEOUT" yaml:"feed_section_timeout" default:"2s"`
+	FeedCacheTTL           time.Duration `env:"FEED_CACHE_TTL" yaml:"feed_cache_ttl" default:"1m"`
+	ReadTimeout            time.Duration `env:"HTTP_READ_TIMEOUT" yaml:"http_read_timeout" default:"15s"`
+	WriteTimeout           time.Duration `env:"HTTP_WRITE_TIMEOUT" yaml:"http_write_timeout" default:"30s"`
+	IdleTimeout            time.Duration `env:"HTTP_IDLE_TIMEOUT" yaml:"http_idle_timeout" default:"60s"`
+	ShutdownTimeout        time.Duration `env:"SHUTDOWN_TIMEOUT" yaml:"shutdown_timeout" default:"30s"` // how long in-flight requests and workers get to finish
+	LogLevel               string        `env:"LOG_LEVEL" yaml:"log_level" default:"info"`              // debug, info, warn or error
+	LogFormat              string        `env:"LOG_FORMAT" yaml:"log_format" default:"json"`            // json or text
+	LogSlowQueries         bool          `env:"DB_LOG_SLOW_QUERIES" yaml:"db_log_slow_queries"`
+	SlowQueryThreshold     time.Duration `env:"DB_SLOW_QUERY_THRESHOLD" yaml:"db_slow_query_threshold" default:"200ms"`
+	MetricsToken           string        `env:"METRICS_TOKEN" yaml:"metrics_token" secret:"true"`        // bearer token /metrics requires, open when empty
+	TracingExporter        string        `env:"TRACING_EXPORTER" yaml:"tracing_exporter" default:"none"` // otlp, stdout or none
+	TracingEndpoint        string        `env:"TRACING_OTLP_ENDPOINT" yaml:"tracing_otlp_endpoint"`      // e.g. http://localhost:4318/v1/traces
+	RateLimitEnabled       bool          `env:"RATE_LIMIT_ENABLED" yaml:"rate_limit_enabled" default:"true"`
+	RateLimitWindow        time.Duration `env:"RATE_LIMIT_WINDOW" yaml:"rate_limit_window" default:"1m"`
+	RateLimitIPRequests    uint          `env:"RATE_LIMIT_IP_REQUESTS" yaml:"rate_limit_ip_requests" default:"300"`     // per client IP, route and window
+	RateLimitUserRequests  uint          `env:"RATE_LIMIT_USER_REQUESTS" yaml:"rate_limit_user_requests" default:"600"` // per logged in user, route and window
+	RateLimitAuthRequests  uint          `env:"RATE_LIMIT_AUTH_REQUESTS" yaml:"rate_limit_auth_requests" default:"10"`  // per client IP, /api/auth route and window
+	LoginMaxFailures       uint          `env:"LOGIN_MAX_FAILURES" yaml:"login_max_failures" default:"5"`               // failed logins in a row locking the account, 0 never locks
+	LoginLockout           time.Duration `env:"LOGIN_LOCKOUT_DURATION" yaml:"login_lockout_duration" default:"15m"`
+	TrustedProxies         string        `env:"TRUSTED_PROXIES" yaml:"trusted_proxies"` // comma separated IPs or CIDRs allowed to set X-Forwarded-For, none when empty
+}
+
+const defaultConfigFile = "config.yaml"
+
+// Load reads and validates the configuration. It fails on the first unreadable source and reports
+// every missing or invalid field at once
+func Load() (*Config, error) {
+	cfg := &Config{}
+	fields := reflect.TypeOf(cfg).Elem()
+	values := reflect.ValueOf(cfg).Elem()
+
+	for i := 0; i < fields.NumField(); i++ {
+		if value, ok := fields.Field(i).Tag.Lookup("default"); ok {
+			if err := setField(values.Field(i), value); err != nil {
+				return nil, fmt.Errorf("default of %s: %w", fields.Field(i).Name, err)
+			}
+		}
+	}
+
+	if err := loadFile(fields, values); err != nil {
+		return nil, err
+	}
+
+	if os.Getenv("GO_ENV") == "development" {
+		// values already in the environment win over the ones in .env
+		if err := godotenv.Load(".env"); err != nil {
+			return nil, fmt.Errorf("loading .env: %w", err)
+		}
+		slog.Info(".env file loaded")
+	}
+
+	var problems []string
+	for i := 0; i < fields.NumField(); i++ {
+		name := fields.Field(i).Tag.Get("env")
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			if err := setField(values.Field(i), value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+	}
+
+	if cfg.CursorSecret == "" {
+		cfg.CursorSecret = cfg.JWTSecret
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return cfg, nil
+}
+
+// loadFile applies the YAML config file. CONFIG_FILE must exist when set, config.yaml is optional
+func loadFile(fields reflect.Type, values reflect.Value) error {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		path = defaultConfigFile
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	var file map[string]interface{}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).Tag.Get("yaml")
+		value, ok := file[key]
+		if !ok || value == nil {
+			continue
+		}
+		if err := setField(values.Field(i), fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("%s in %s: %w", key, path, err)
+		}
+	}
+	return nil
+}
+
+// setField parses value into a field of one of the types used by Config
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case time.Duration:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(duration))
+	case bool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(flag)
+	case uint:
+		number, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetUint(number)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// validate lists the required fields that are missing and the durations that are not positive
+func (cfg *Config) validate() []string {
+	fields := reflect.TypeOf(cfg).Elem()
+	values := reflect.ValueOf(cfg).Elem()
+	var problems []string
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if field.Tag.Get("required") == "true" && values.Field(i).IsZero() {
+			problems = append(problems, field.Tag.Get("env")+" is required")
+		}
+		if duration, ok := values.Field(i).Interface().(time.Duration); ok && duration <= 0 {
+			problems = append(problems, field.Tag.Get("env")+" must be positive")
+		}
+	}
+	for _, proxy := range cfg.Proxies() {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problems = append(problems, fmt.Sprintf("TRUSTED_PROXIES: invalid IP or CIDR %q", proxy))
+			}
+		}
+	}
+	return problems
+}
+
+// Proxies lists the trusted proxies, the client IP is only taken from X-Forwarded-For when they send it
+func (cfg *Config) Proxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// String lists the configuration with the secrets redacted, so it can be logged
+func (cfg Config) String() string {
+	fields := reflect.TypeOf(cfg)
+	values := reflect.ValueOf(cfg)
+	parts := make([]string, 0, fields.NumField())
+	for i := 0; i < fields.NumField(); i++ {
+		value := fmt.Sprint(values.Field(i).Interface())
+		if fields.Field(i).Tag.Get("secret") == "true" && value != "" {
+			value = "[redacted]"
+		}
+		parts = append(parts, fields.Field(i).Tag.Get("env")+"="+value)
+	}
+	return strings.Join(parts, " ")
 }
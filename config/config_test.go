@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setEnv gives Load a clean environment: every variable of Config is cleared, then the given ones set
+func setEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+	t.Setenv("GO_ENV", "test")
+	t.Setenv("CONFIG_FILE", "") // there is no config.yaml next to the tests
+	for _, name := range []string{"PORT", "DB_URL", "JWT_SECRET", "CURSOR_SECRET", "LOG_LEVEL", "FEED_CACHE_TTL", "RATE_LIMIT_IP_REQUESTS", "RATE_LIMIT_ENABLED", "TRUSTED_PROXIES"} {
+		t.Setenv(name, "")
+	}
+	for name, value := range values {
+		t.Setenv(name, value)
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestLoadDefaultsAndEnvironment(t *testing.T) {
+	setEnv(t, map[string]string{"DB_URL": "postgres://db", "JWT_SECRET": "jwt", "RATE_LIMIT_ENABLED": "false", "RATE_LIMIT_IP_REQUESTS": "50"})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "8080" || cfg.FeedCacheTTL != time.Minute || cfg.LogLevel != "info" {
+		t.Errorf("expected the defaults, got %s", cfg)
+	}
+	if cfg.DatabaseURL != "postgres://db" || cfg.RateLimitEnabled || cfg.RateLimitIPRequests != 50 {
+		t.Errorf("expected the environment to override the defaults, got %s", cfg)
+	}
+	if cfg.CursorSecret != "jwt" {
+		t.Errorf("expected the cursor secret to default to the JWT secret, got %q", cfg.CursorSecret)
+	}
+}
+
+func TestLoadFileUnderEnvironment(t *testing.T) {
+	setEnv(t, map[string]string{"JWT_SECRET": "jwt", "PORT": "9100"})
+	writeConfigFile(t, "db_url: postgres://file\nport: 9000\nlog_level: debug\nfeed_cache_ttl: 5m\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DatabaseURL != "postgres://file" || cfg.LogLevel != "debug" || cfg.FeedCacheTTL != 5*time.Minute {
+		t.Errorf("expected the values of the file, got %s", cfg)
+	}
+	if cfg.Port != "9100" {
+		t.Errorf("expected the environment to win over the file, got port %s", cfg.Port)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	setEnv(t, map[string]string{"FEED_CACHE_TTL": "-1s", "RATE_LIMIT_IP_REQUESTS": "many", "TRUSTED_PROXIES": "10.0.0.1, 10.0.0.0/8, proxy"})
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected the configuration to be invalid")
+	}
+	for _, problem := range []string{"DB_URL is required", "JWT_SECRET is required", "FEED_CACHE_TTL must be positive", `RATE_LIMIT_IP_REQUESTS: invalid number "many"`, `invalid IP or CIDR "proxy"`} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected %q in %q", problem, err)
+		}
+	}
+}
+
+func TestLoadFailsOnUnreadableFile(t *testing.T) {
+	setEnv(t, map[string]string{"DB_URL": "postgres://db", "JWT_SECRET": "jwt"})
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := Load(); err == nil {
+		t.Error("expected a CONFIG_FILE that does not exist to fail")
+	}
+
+	writeConfigFile(t, "port: [8080\n")
+	if _, err := Load(); err == nil {
+		t.Error("expected a malformed config file to fail")
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := Config{Port: "8080", DatabaseURL: "postgres://user:password@db", JWTSecret: "jwt"}
+	logged := cfg.String()
+	if strings.Contains(logged, "password") || strings.Contains(logged, "jwt") || !strings.Contains(logged, "PORT=8080") {
+		t.Errorf("expected the secrets to be redacted, got %s", logged)
+	}
+	if !strings.Contains(logged, "METRICS_TOKEN= ") {
+		t.Errorf("expected an empty secret to stay empty, got %s", logged)
+	}
+}
+
+func TestProxies(t *testing.T) {
+	cfg := Config{TrustedProxies: " 10.0.0.1 ,,192.168.0.0/16 "}
+	proxies := cfg.Proxies()
+	if len(proxies) != 2 || proxies[0] != "10.0.0.1" || proxies[1] != "192.168.0.0/16" {
+		t.Errorf("unexpected proxies %q", proxies)
+	}
+}
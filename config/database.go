@@ -1,26 +1,29 @@
 package config
 
 import (
-	"log"
+	"log/slog"
+	"madang_api/logging"
+	"madang_api/tracing"
 	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
-
-func ConnectToDB() {
-	var err error
-	dsn := os.Getenv("DB_URL")
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent), // Disable logging
+// ConnectToDB opens the connection pool to the database, the application exits when it cannot
+func ConnectToDB(cfg *Config) *gorm.DB {
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{
+		Logger: logging.NewGormLogger(cfg.SlowQueryThreshold, cfg.LogSlowQueries),
 	})
 
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	} else {
-		log.Println("Database connected successfully")
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		slog.Error("Failed to set up the tracing of queries", "error", err)
+		os.Exit(1)
 	}
+	slog.Info("Database connected")
+	return db
 }
@@ -11,7 +11,7 @@ import (
 
 // AddonControllerImpl implements the AddonController interface
 type AddonController struct {
-	AddonService services.AddonService
+	AddonService *services.AddonService
 }
 
 // AddonController defines the methods for handling addon related operations
@@ -85,20 +85,19 @@ func (f *AddonController) UpdateAddon(c *gin.Context) {
 	}
 
 	// Check if the addon item exists
-	_, err := f.AddonService.GetAddon(addonId)
+	addon, err := f.AddonService.GetAddon(addonId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Addon not found", err.Error())
 		return
 	}
 
-	var addon models.Addon
 	addon.Name = body.Name
 	addon.Type = body.Type
 	addon.Price = body.Price
 
 	// Call the UpdateAddon service
-	updatedAddon, err := f.AddonService.UpdateAddon(&addon)
+	updatedAddon, err := f.AddonService.UpdateAddon(addon)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update addon", err.Error())
 		return
@@ -143,10 +142,23 @@ func (f *AddonController) GetAddon(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Addon retrieved successfully", addon)
 }
 
+// addonListSpec is what the addons lists can be sorted and filtered by
+var addonListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "price": "price", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"restaurant_id": utils.IDColumn("restaurant_id"), "type": utils.TextColumn("type")},
+	DefaultSort: "id",
+}
+
 // GetAllAddons retrieves all addon items
 func (f *AddonController) GetAllAddons(c *gin.Context) {
+	query, err := utils.ParseListQuery(c, addonListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the GetAllAddons service
-	addons, err := f.AddonService.GetAllAddons()
+	addons, pagination, err := f.AddonService.GetAllAddons(query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve addons", err.Error())
@@ -154,7 +166,7 @@ func (f *AddonController) GetAllAddons(c *gin.Context) {
 	}
 
 	// Return the list of addons
-	utils.SuccessResponse(c, http.StatusOK, "Addons retrieved successfully", addons)
+	utils.PaginatedResponse(c, http.StatusOK, "Addons retrieved successfully", addons, pagination)
 }
 
 // GetRestaurantAddons retrieves all the addons of a particular restaurant
@@ -164,8 +176,14 @@ func (f *AddonController) GetRestaurantAddons(c *gin.Context) {
 		return
 	}
 
-	// Call the GetRestaurantAddons service
-	addons, err := f.AddonService.GetRestaurantAddons(restaurantId)
+	query, err := utils.ParseListQuery(c, addonListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	// Call the GetAllAddons service
+	addons, pagination, err := f.AddonService.GetAllAddons(query.Where("restaurant_id", restaurantId))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve addons", err.Error())
@@ -173,16 +191,20 @@ func (f *AddonController) GetRestaurantAddons(c *gin.Context) {
 	}
 
 	// Return the list of addons
-	utils.SuccessResponse(c, http.StatusOK, "Addons retrieved successfully", addons)
+	utils.PaginatedResponse(c, http.StatusOK, "Addons retrieved successfully", addons, pagination)
 }
 
 // SearchAddon
 func (f *AddonController) SearchAddon(c *gin.Context) {
 	// Get the search query parameter
 	query := c.Query("q")
+	restaurantId, valid := utils.OptionalQueryID(c, "restaurant_id")
+	if !valid {
+		return
+	}
 
 	// Call the SearchAddon service
-	addons, err := f.AddonService.SearchAddons(query)
+	addons, err := f.AddonService.SearchAddons(query, restaurantId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search for addons", err.Error())
@@ -0,0 +1,74 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type addon struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Price        float64 `json:"price"`
+	RestaurantID uint    `json:"restaurant_id"`
+}
+
+func TestAddonLifecycle(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+
+	var created addon
+	h.Request(t, http.MethodPost, "/api/addons/", token, map[string]interface{}{
+		"name": "Balloons", "type": "decoration", "price": 4, "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &created)
+	path := fmt.Sprintf("/api/addons/%d", created.ID)
+
+	var updated addon
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"name": "Balloons", "type": "decoration", "price": 5}).
+		Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.ID != created.ID || updated.Price != 5 || updated.RestaurantID != h.Fixtures.Restaurant.ID {
+		t.Fatalf("unexpected addon after the update %+v", updated)
+	}
+
+	var addons []addon
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/addons/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &addons)
+	if len(addons) != 2 {
+		t.Fatalf("the update must not add an addon, got %d addons", len(addons))
+	}
+
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusInternalServerError)
+}
+
+func TestAddonRequiresMenuPermission(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	h.Request(t, http.MethodPost, "/api/addons/", token, map[string]interface{}{
+		"name": "Balloons", "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("/api/addons/%d", h.Fixtures.Addon.ID), token, nil).Expect(t, http.StatusForbidden)
+}
+
+func TestBrowseAddons(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	var got addon
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/addons/%d", h.Fixtures.Addon.ID), token, nil).Expect(t, http.StatusOK).Decode(t, &got)
+	if got.Name != h.Fixtures.Addon.Name {
+		t.Fatalf("expected the fixture addon, got %+v", got)
+	}
+
+	for _, path := range []string{"/api/addons/?type=flower", "/api/addons/search?q=flower"} {
+		var addons []addon
+		h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &addons)
+		if len(addons) != 1 || addons[0].ID != h.Fixtures.Addon.ID {
+			t.Errorf("%s: expected the fixture addon, got %+v", path, addons)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+package controllers
+
+import (
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BrandController struct {
+	BrandService *services.BrandService
+}
+
+// BrandControllerInterface defines the methods for managing brands and their branches
+type BrandControllerInterface interface {
+	CreateBrand(c *gin.Context)
+	GetAllBrands(c *gin.Context)
+	GetBrand(c *gin.Context)
+	UpdateBrand(c *gin.Context)
+	DeleteBrand(c *gin.Context)
+	GetBrandReport(c *gin.Context)
+	SyncBrand(c *gin.Context)
+	AddBranch(c *gin.Context)
+	RemoveBranch(c *gin.Context)
+	SetBranchManager(c *gin.Context)
+	AddTemplateFood(c *gin.Context)
+	UpdateTemplateFood(c *gin.Context)
+	DeleteTemplateFood(c *gin.Context)
+	AddTemplateAddon(c *gin.Context)
+	UpdateTemplateAddon(c *gin.Context)
+	DeleteTemplateAddon(c *gin.Context)
+	GetBranchOverrides(c *gin.Context)
+	SetBranchOverride(c *gin.Context)
+	DeleteBranchOverride(c *gin.Context)
+}
+
+// CreateBrand creates a brand owned by the logged in user
+func (ctrl *BrandController) CreateBrand(c *gin.Context) {
+	var body struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Image       string `json:"image"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	var brand models.Brand
+	brand.Name = body.Name
+	brand.Description = body.Description
+	brand.Image = body.Image
+	brand.UserID = loggedInUser.(models.User).ID
+
+	newBrand, err := ctrl.BrandService.CreateBrand(c.Request.Context(), &brand)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create brand", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Brand created successfully", newBrand)
+}
+
+// brandListSpec is what the brands lists can be sorted and filtered by
+var brandListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"user_id": utils.IDColumn("user_id")},
+	DefaultSort: "id",
+}
+
+// GetAllBrands retrieves every brand
+func (ctrl *BrandController) GetAllBrands(c *gin.Context) {
+	query, err := utils.ParseListQuery(c, brandListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	brands, pagination, err := ctrl.BrandService.GetAllBrands(c.Request.Context(), query)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve brands", err.Error())
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "Brands retrieved successfully", brands, pagination)
+}
+
+// GetBrand retrieves a brand with its menu template and branches
+func (ctrl *BrandController) GetBrand(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	brand, err := ctrl.BrandService.GetBrand(c.Request.Context(), brandID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Brand not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand retrieved successfully", brand)
+}
+
+// UpdateBrand updates the details of a brand
+func (ctrl *BrandController) UpdateBrand(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Image       string `json:"image"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	var brand models.Brand
+	brand.ID = brandID
+	brand.Name = body.Name
+	brand.Description = body.Description
+	brand.Image = body.Image
+
+	updatedBrand, err := ctrl.BrandService.UpdateBrand(c.Request.Context(), &brand)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update brand", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand updated successfully", updatedBrand)
+}
+
+// DeleteBrand deletes a brand, leaving its branches as independent restaurants
+func (ctrl *BrandController) DeleteBrand(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.BrandService.DeleteBrand(c.Request.Context(), brandID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to delete brand", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand deleted successfully", nil)
+}
+
+// GetBrandReport aggregates the activity of every branch. ?from and ?to are optional YYYY-MM-DD dates, both included
+func (ctrl *BrandController) GetBrandReport(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var from, to *time.Time
+	if raw := c.Query("from"); raw != "" {
+		date, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date", "from must be formatted as YYYY-MM-DD")
+			return
+		}
+		from = &date
+	}
+	if raw := c.Query("to"); raw != "" {
+		date, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date", "to must be formatted as YYYY-MM-DD")
+			return
+		}
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+
+	report, err := ctrl.BrandService.GetBrandReport(c.Request.Context(), brandID, from, to)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to build brand report", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand report retrieved successfully", report)
+}
+
+// SyncBrand pushes the brand menu to every branch again
+func (ctrl *BrandController) SyncBrand(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.BrandService.SyncBrand(c.Request.Context(), brandID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to sync brand menu", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand menu synced successfully", nil)
+}
+
+// AddBranch attaches a restaurant to a brand
+func (ctrl *BrandController) AddBranch(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		RestaurantID uint `json:"restaurant_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	restaurant, err := ctrl.BrandService.AddBranch(c.Request.Context(), loggedInUser.(models.User), brandID, body.RestaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add branch", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Branch added successfully", restaurant)
+}
+
+// RemoveBranch detaches a restaurant from its brand
+func (ctrl *BrandController) RemoveBranch(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	restaurantID, valid := utils.ValidateID(c, "restaurant_id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.BrandService.RemoveBranch(c.Request.Context(), brandID, restaurantID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to remove branch", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Branch removed successfully", nil)
+}
+
+// SetBranchManager changes the manager of a branch
+func (ctrl *BrandController) SetBranchManager(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	restaurantID, valid := utils.ValidateID(c, "restaurant_id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	restaurant, err := ctrl.BrandService.SetBranchManager(c.Request.Context(), brandID, restaurantID, body.UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to change branch manager", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Branch manager changed successfully", restaurant)
+}
+
+type brandFoodBody struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Image        string  `json:"image"`
+	Price        float64 `json:"price"`
+	Category     string  `json:"category"`
+	CategoryType string  `json:"category_type"`
+}
+
+func (body brandFoodBody) food(brandID uint) models.BrandFood {
+	return models.BrandFood{
+		BrandID:      brandID,
+		Name:         body.Name,
+		Description:  body.Description,
+		Image:        body.Image,
+		Price:        body.Price,
+		Category:     body.Category,
+		CategoryType: body.CategoryType,
+	}
+}
+
+// AddTemplateFood adds a food to the brand menu
+func (ctrl *BrandController) AddTemplateFood(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body brandFoodBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	food := body.food(brandID)
+	newFood, err := ctrl.BrandService.AddTemplateFood(c.Request.Context(), &food)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add food to brand menu", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Food added to brand menu successfully", newFood)
+}
+
+// UpdateTemplateFood changes a food of the brand menu
+func (ctrl *BrandController) UpdateTemplateFood(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	foodID, valid := utils.ValidateID(c, "food_id")
+	if !valid {
+		return
+	}
+
+	var body brandFoodBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	food := body.food(brandID)
+	food.ID = foodID
+	updatedFood, err := ctrl.BrandService.UpdateTemplateFood(c.Request.Context(), &food)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update brand menu food", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand menu food updated successfully", updatedFood)
+}
+
+// DeleteTemplateFood removes a food from the brand menu and every branch
+func (ctrl *BrandController) DeleteTemplateFood(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	foodID, valid := utils.ValidateID(c, "food_id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.BrandService.DeleteTemplateFood(c.Request.Context(), brandID, foodID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to delete brand menu food", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand menu food deleted successfully", nil)
+}
+
+type brandAddonBody struct {
+	Name  string  `json:"name"`
+	Type  string  `json:"type"`
+	Price float64 `json:"price"`
+}
+
+// AddTemplateAddon adds an addon to the brand menu
+func (ctrl *BrandController) AddTemplateAddon(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body brandAddonBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	addon := models.BrandAddon{BrandID: brandID, Name: body.Name, Type: body.Type, Price: body.Price}
+	newAddon, err := ctrl.BrandService.AddTemplateAddon(c.Request.Context(), &addon)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add addon to brand menu", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Addon added to brand menu successfully", newAddon)
+}
+
+// UpdateTemplateAddon changes an addon of the brand menu
+func (ctrl *BrandController) UpdateTemplateAddon(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	addonID, valid := utils.ValidateID(c, "addon_id")
+	if !valid {
+		return
+	}
+
+	var body brandAddonBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	addon := models.BrandAddon{ID: addonID, BrandID: brandID, Name: body.Name, Type: body.Type, Price: body.Price}
+	updatedAddon, err := ctrl.BrandService.UpdateTemplateAddon(c.Request.Context(), &addon)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update brand menu addon", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand menu addon updated successfully", updatedAddon)
+}
+
+// DeleteTemplateAddon removes an addon from the brand menu and every branch
+func (ctrl *BrandController) DeleteTemplateAddon(c *gin.Context) {
+	brandID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	addonID, valid := utils.ValidateID(c, "addon_id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.BrandService.DeleteTemplateAddon(c.Request.Context(), brandID, addonID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to delete brand menu addon", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Brand menu addon deleted successfully", nil)
+}
+
+// GetBranchOverrides retrieves the price and availability overrides of a branch
+func (ctrl *BrandController) GetBranchOverrides(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	overrides, err := ctrl.BrandService.GetBranchOverrides(c.Request.Context(), restaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve branch overrides", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Branch overrides retrieved successfully", overrides)
+}
+
+// SetBranchOverride changes the price or availability of a brand menu item for one branch
+func (ctrl *BrandController) SetBranchOverride(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		BrandFoodID  *uint    `json:"brand_food_id"`
+		BrandAddonID *uint    `json:"brand_addon_id"`
+		Price        *float64 `json:"price"`
+		Available    *bool    `json:"available"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	override := models.BranchOverride{
+		RestaurantID: restaurantID,
+		BrandFoodID:  body.BrandFoodID,
+		BrandAddonID: body.BrandAddonID,
+		Price:        body.Price,
+		Available:    body.Available,
+	}
+	newOverride, err := ctrl.BrandService.SetBranchOverride(c.Request.Context(), &override)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to set branch override", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Branch override set successfully", newOverride)
+}
+
+// DeleteBranchOverride puts a branch back on the brand menu value
+func (ctrl *BrandController) DeleteBranchOverride(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	overrideID, valid := utils.ValidateID(c, "override_id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.BrandService.DeleteBranchOverride(c.Request.Context(), restaurantID, overrideID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to delete branch override", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Branch override deleted successfully", nil)
+}
@@ -0,0 +1,220 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/models"
+	"madang_api/testutil"
+)
+
+type brand struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	UserID uint   `json:"user_id"`
+}
+
+// createBrand has the manager create a brand with the fixture restaurant as its only branch
+func createBrand(t *testing.T, h *testutil.Harness, manager string) brand {
+	t.Helper()
+	var created brand
+	h.Request(t, http.MethodPost, "/api/brands/", manager, map[string]string{"name": "Test Brand"}).
+		Expect(t, http.StatusCreated).Decode(t, &created)
+	h.Request(t, http.MethodPost, fmt.Sprintf("/api/brands/%d/branches", created.ID), manager, map[string]uint{
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusCreated)
+	return created
+}
+
+func TestBrandCRUD(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+
+	// customers cannot own brands
+	h.Request(t, http.MethodPost, "/api/brands/", customer, map[string]string{"name": "Nope"}).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPost, "/api/brands/", manager, map[string]string{}).Expect(t, http.StatusBadRequest)
+
+	var created brand
+	h.Request(t, http.MethodPost, "/api/brands/", manager, map[string]string{"name": "Test Brand"}).
+		Expect(t, http.StatusCreated).Decode(t, &created)
+	if created.UserID != h.Fixtures.Manager.ID {
+		t.Fatalf("expected the manager to own the brand, got %+v", created)
+	}
+	h.Request(t, http.MethodPost, "/api/brands/", manager, map[string]string{"name": "Test Brand"}).Expect(t, http.StatusBadRequest)
+
+	path := fmt.Sprintf("/api/brands/%d", created.ID)
+	var fetched brand
+	h.Request(t, http.MethodGet, path, customer, nil).Expect(t, http.StatusOK).Decode(t, &fetched)
+	if fetched.Name != "Test Brand" {
+		t.Fatalf("unexpected brand %+v", fetched)
+	}
+	var brands []brand
+	h.Request(t, http.MethodGet, "/api/brands/", customer, nil).Expect(t, http.StatusOK).Decode(t, &brands)
+	if len(brands) != 1 {
+		t.Fatalf("expected 1 brand, got %d", len(brands))
+	}
+
+	// only the owner changes the brand
+	h.Request(t, http.MethodPut, path, customer, map[string]string{"name": "Stolen"}).Expect(t, http.StatusForbidden)
+	var updated brand
+	h.Request(t, http.MethodPut, path, manager, map[string]string{"name": "Renamed Brand"}).Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.Name != "Renamed Brand" {
+		t.Fatalf("expected the brand to be renamed, got %+v", updated)
+	}
+
+	h.Request(t, http.MethodDelete, path, customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodDelete, path, manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, customer, nil).Expect(t, http.StatusNotFound)
+}
+
+func TestBrandMenuSyncsToBranches(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+	created := createBrand(t, h, manager)
+	path := fmt.Sprintf("/api/brands/%d", created.ID)
+
+	// a restaurant belongs to one brand at most
+	h.Request(t, http.MethodPost, path+"/branches", manager, map[string]uint{"restaurant_id": h.Fixtures.Restaurant.ID}).
+		Expect(t, http.StatusBadRequest)
+
+	var template struct {
+		ID uint `json:"id"`
+	}
+	h.Request(t, http.MethodPost, path+"/foods", customer, map[string]interface{}{"name": "Bulgogi", "price": 15}).
+		Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPost, path+"/foods", manager, map[string]interface{}{"name": "Bulgogi", "price": 15, "category": "Mains"}).
+		Expect(t, http.StatusCreated).Decode(t, &template)
+	h.Request(t, http.MethodPost, path+"/foods", manager, map[string]interface{}{"name": "bulgogi", "price": 15}).
+		Expect(t, http.StatusBadRequest)
+	assertFoodCount(t, h, customer, 2)
+
+	// the branch overrides the price of the template food
+	overrides := fmt.Sprintf("/api/restaurants/%d/overrides/", h.Fixtures.Restaurant.ID)
+	h.Request(t, http.MethodPut, overrides, customer, map[string]interface{}{"brand_food_id": template.ID, "price": 13}).
+		Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPut, overrides, manager, map[string]interface{}{"price": 13}).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPut, overrides, manager, map[string]interface{}{"brand_food_id": template.ID, "price": 13}).
+		Expect(t, http.StatusOK)
+	if price := brandFoodPrice(t, h, customer, "Bulgogi"); price != 13 {
+		t.Fatalf("expected the overridden price 13, got %v", price)
+	}
+
+	var list []struct {
+		ID uint `json:"id"`
+	}
+	h.Request(t, http.MethodGet, overrides, manager, nil).Expect(t, http.StatusOK).Decode(t, &list)
+	if len(list) != 1 {
+		t.Fatalf("expected 1 override, got %d", len(list))
+	}
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%s%d", overrides, list[0].ID), manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodPost, path+"/sync", manager, nil).Expect(t, http.StatusOK)
+	if price := brandFoodPrice(t, h, customer, "Bulgogi"); price != 15 {
+		t.Fatalf("expected the brand price 15 once the override is gone, got %v", price)
+	}
+
+	h.Request(t, http.MethodPut, fmt.Sprintf("%s/foods/%d", path, template.ID), manager, map[string]interface{}{"price": -1}).
+		Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%s/foods/%d", path, template.ID), manager, nil).Expect(t, http.StatusOK)
+	assertFoodCount(t, h, customer, 1)
+
+	var addon struct {
+		ID uint `json:"id"`
+	}
+	h.Request(t, http.MethodPost, path+"/addons", manager, map[string]interface{}{"name": "Candles", "type": "decoration", "price": 2}).
+		Expect(t, http.StatusCreated).Decode(t, &addon)
+	h.Request(t, http.MethodPut, fmt.Sprintf("%s/addons/%d", path, addon.ID), manager, map[string]interface{}{"price": 4}).
+		Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%s/addons/%d", path, addon.ID), manager, nil).Expect(t, http.StatusOK)
+
+	// the branch keeps its own items once it leaves the brand
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%s/branches/%d", path, h.Fixtures.Restaurant.ID), manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%s/branches/%d", path, h.Fixtures.Restaurant.ID), manager, nil).
+		Expect(t, http.StatusBadRequest)
+	assertFoodCount(t, h, customer, 1)
+}
+
+func TestBrandTemplateDeleteKeepsOrderedCopies(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+	path := fmt.Sprintf("/api/brands/%d", createBrand(t, h, manager).ID)
+
+	// the branch keeps its own food rather than getting a second one with the same name
+	h.Request(t, http.MethodPost, path+"/foods", manager, map[string]interface{}{"name": h.Fixtures.Food.Name, "price": 20}).
+		Expect(t, http.StatusCreated)
+	assertFoodCount(t, h, customer, 1)
+
+	var template struct {
+		ID uint `json:"id"`
+	}
+	h.Request(t, http.MethodPost, path+"/foods", manager, map[string]interface{}{"name": "Bulgogi", "price": 15}).
+		Expect(t, http.StatusCreated).Decode(t, &template)
+	var branchFood models.Food
+	if err := h.DB.Where("brand_food_id = ?", template.ID).First(&branchFood).Error; err != nil {
+		t.Fatalf("the template food was not copied to the branch: %v", err)
+	}
+	h.Request(t, http.MethodPost, "/api/orders/", customer, map[string]interface{}{
+		"user_id":       h.Fixtures.Customer.ID,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+		"foods":         []map[string]interface{}{{"id": branchFood.ID, "quantity": 1}},
+		"total_price":   15,
+		"status":        "pending",
+	}).Expect(t, http.StatusCreated)
+
+	// the ordered copy outlives the template, unavailable and no longer synced
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%s/foods/%d", path, template.ID), manager, nil).Expect(t, http.StatusOK)
+	if err := h.DB.First(&branchFood, branchFood.ID).Error; err != nil {
+		t.Fatalf("the ordered branch food is gone: %v", err)
+	}
+	if branchFood.Available || branchFood.BrandFoodID != nil {
+		t.Fatalf("expected the ordered branch food to be unavailable and unlinked, got %+v", branchFood)
+	}
+}
+
+func TestBrandReportAndBranchManager(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+	created := createBrand(t, h, manager)
+	path := fmt.Sprintf("/api/brands/%d", created.ID)
+
+	placeOrder(t, h, customer)
+
+	var report struct {
+		Totals struct {
+			Orders int64 `json:"orders"`
+		} `json:"totals"`
+		Branches []struct {
+			RestaurantID uint `json:"restaurant_id"`
+		} `json:"branches"`
+	}
+	h.Request(t, http.MethodGet, path+"/report", manager, nil).Expect(t, http.StatusOK).Decode(t, &report)
+	if report.Totals.Orders != 1 || len(report.Branches) != 1 {
+		t.Fatalf("unexpected brand report %+v", report)
+	}
+	h.Request(t, http.MethodGet, path+"/report?from=yesterday", manager, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, path+"/report", customer, nil).Expect(t, http.StatusForbidden)
+
+	branch := fmt.Sprintf("%s/branches/%d/manager", path, h.Fixtures.Restaurant.ID)
+	// only managers can run a branch
+	h.Request(t, http.MethodPut, branch, manager, map[string]uint{"user_id": h.Fixtures.Customer.ID}).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPut, branch, manager, map[string]uint{"user_id": h.Fixtures.Manager.ID}).Expect(t, http.StatusOK)
+}
+
+// brandFoodPrice returns the price of the food of the fixture restaurant with the given name
+func brandFoodPrice(t *testing.T, h *testutil.Harness, token string, name string) float64 {
+	t.Helper()
+	var foods []food
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/foods/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &foods)
+	for _, f := range foods {
+		if f.Name == name {
+			return f.Price
+		}
+	}
+	t.Fatalf("no food named %s", name)
+	return 0
+}
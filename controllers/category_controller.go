@@ -10,7 +10,7 @@ import (
 )
 
 type CategoryController struct {
-	CategoryService services.CategoryService
+	CategoryService *services.CategoryService
 }
 
 type CategoryControllerInterface interface {
@@ -22,8 +22,21 @@ type CategoryControllerInterface interface {
 	GetRestaurantCategories(c *gin.Context)
 }
 
+// categoryListSpec is what the categories lists can be sorted and filtered by
+var categoryListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"restaurant_id": utils.IDColumn("restaurant_id"), "type": utils.TextColumn("type")},
+	DefaultSort: "id",
+}
+
 func (ctrl *CategoryController) GetAllCategories(c *gin.Context) {
-	categories, err := ctrl.CategoryService.GetCategories()
+	query, err := utils.ParseListQuery(c, categoryListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	categories, pagination, err := ctrl.CategoryService.GetCategories(query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err.Error())
@@ -31,7 +44,7 @@ func (ctrl *CategoryController) GetAllCategories(c *gin.Context) {
 	}
 
 	// Return the list of foods
-	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
+	utils.PaginatedResponse(c, http.StatusOK, "Categories retrieved successfully", categories, pagination)
 }
 
 func (ctrl *CategoryController) GetCategory(c *gin.Context) {
@@ -95,9 +108,8 @@ func (ctrl *CategoryController) UpdateCategory(c *gin.Context) {
 
 	// Bind the request body to a Category struct
 	var body struct {
-		Name         string `json:"name"`
-		Type         string `json:"type"`
-		RestaurantID uint   `json:"restaurant_id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
 	}
 
 	// Validate the request body
@@ -121,20 +133,14 @@ func (ctrl *CategoryController) UpdateCategory(c *gin.Context) {
 		body.Type = existingCategory.Type
 	}
 
-	if body.RestaurantID == 0 {
-		body.RestaurantID = existingCategory.RestaurantID
-	}
-
 	if err := utils.ValidateStruct(c, body); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
 		return
 	}
 
-	var category models.Category
-	category.ID = categoryID
+	category := existingCategory
 	category.Name = body.Name
 	category.Type = body.Type
-	category.RestaurantID = body.RestaurantID
 
 	// Call the service to update the category
 	updatedCategory, err := ctrl.CategoryService.UpdateCategory(&category)
@@ -182,8 +188,14 @@ func (ctrl *CategoryController) GetRestaurantCategories(c *gin.Context) {
 		return
 	}
 
+	query, err := utils.ParseListQuery(c, categoryListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the service to retrieve the categories for the restaurant
-	categories, err := ctrl.CategoryService.GetRestaurantCategories(restaurantID)
+	categories, pagination, err := ctrl.CategoryService.GetCategories(query.Where("restaurant_id", restaurantID))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err.Error())
@@ -191,5 +203,5 @@ func (ctrl *CategoryController) GetRestaurantCategories(c *gin.Context) {
 	}
 
 	// Return the list of categories
-	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
+	utils.PaginatedResponse(c, http.StatusOK, "Categories retrieved successfully", categories, pagination)
 }
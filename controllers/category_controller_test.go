@@ -0,0 +1,75 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+func TestCategoryLifecycle(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+
+	var category struct {
+		ID           uint   `json:"ID"`
+		Name         string `json:"name"`
+		Type         string `json:"type"`
+		RestaurantID uint   `json:"restaurant_id"`
+	}
+	h.Request(t, http.MethodPost, "/api/categories/", token, map[string]interface{}{
+		"name": "Desserts", "type": "food", "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &category)
+	path := fmt.Sprintf("/api/categories/%d", category.ID)
+
+	// the category stays in its restaurant whatever the body says
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"name": "Sweets", "restaurant_id": h.Fixtures.Restaurant.ID + 1}).
+		Expect(t, http.StatusOK).Decode(t, &category)
+	if category.Name != "Sweets" || category.Type != "food" || category.RestaurantID != h.Fixtures.Restaurant.ID {
+		t.Fatalf("unexpected category after the update %+v", category)
+	}
+
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &category)
+	if category.Name != "Sweets" {
+		t.Fatalf("the update was not stored, got %+v", category)
+	}
+
+	var categories []map[string]interface{}
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/categories/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &categories)
+	if len(categories) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(categories))
+	}
+
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusNotFound)
+}
+
+func TestCategoryRequiresMenuPermission(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	h.Request(t, http.MethodPost, "/api/categories/", token, map[string]interface{}{
+		"name": "Desserts", "type": "food", "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPost, "/api/categories/", token, map[string]interface{}{"name": "Desserts", "type": "food"}).
+		Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("/api/categories/%d", h.Fixtures.Category.ID), token, nil).
+		Expect(t, http.StatusForbidden)
+
+	// listing is open to every user
+	var categories []map[string]interface{}
+	h.Request(t, http.MethodGet, "/api/categories/", token, nil).Expect(t, http.StatusOK).Decode(t, &categories)
+	if len(categories) != 1 {
+		t.Fatalf("expected the fixture category, got %d", len(categories))
+	}
+}
+
+func TestAdminManagesEveryCategory(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "admin")
+
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/categories/%d", h.Fixtures.Category.ID), token, map[string]interface{}{"type": "drink"}).
+		Expect(t, http.StatusOK)
+}
@@ -11,7 +11,7 @@ import (
 
 // FoodControllerImpl implements the FoodController interface
 type FoodController struct {
-	FoodService services.FoodService
+	FoodService *services.FoodService
 }
 
 // FoodController defines the methods for handling food related operations
@@ -71,12 +71,11 @@ func (f *FoodController) UpdateFood(c *gin.Context) {
 		return
 	}
 	var body struct {
-		Name         string  `json:"name"`
-		Description  string  `json:"description"`
-		Image        string  `json:"image"`
-		Price        float64 `json:"price"`
-		CategoryId   uint    `json:"category_id"`
-		RestaurantId uint    `json:"restaurant_id"`
+		Name        string  `json:"name"`
+		Description string  `json:"description"`
+		Image       string  `json:"image"`
+		Price       float64 `json:"price"`
+		CategoryId  uint    `json:"category_id"`
 	}
 
 	// Validate the request body
@@ -113,25 +112,20 @@ func (f *FoodController) UpdateFood(c *gin.Context) {
 		body.CategoryId = existingFood.CategoryId
 	}
 
-	if body.RestaurantId == 0 {
-		body.RestaurantId = existingFood.RestaurantID
-	}
-
 	if err := utils.ValidateStruct(c, body); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
 		return
 	}
 
-	var food models.Food
+	food := existingFood
 	food.Name = body.Name
 	food.Description = body.Description
 	food.Image = body.Image
 	food.Price = body.Price
 	food.CategoryId = body.CategoryId
-	food.RestaurantID = body.RestaurantId
 
 	// Call the UpdateFood service
-	updatedFood, err := f.FoodService.UpdateFood(&food)
+	updatedFood, err := f.FoodService.UpdateFood(food)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update food", err.Error())
 		return
@@ -183,10 +177,23 @@ func (f *FoodController) GetFood(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Food retrieved successfully", food)
 }
 
+// foodListSpec is what the foods lists can be sorted and filtered by
+var foodListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "price": "price", "average_rating": "average_rating", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"restaurant_id": utils.IDColumn("restaurant_id"), "category_id": utils.IDColumn("category_id"), "available": utils.BoolColumn("available")},
+	DefaultSort: "id",
+}
+
 // GetAllFoods retrieves all food items
 func (f *FoodController) GetAllFoods(c *gin.Context) {
+	query, err := utils.ParseListQuery(c, foodListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the GetAllFoods service
-	foods, err := f.FoodService.GetAllFoods()
+	foods, pagination, err := f.FoodService.GetAllFoods(query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve foods", err.Error())
@@ -194,7 +201,7 @@ func (f *FoodController) GetAllFoods(c *gin.Context) {
 	}
 
 	// Return the list of foods
-	utils.SuccessResponse(c, http.StatusOK, "Foods retrieved successfully", foods)
+	utils.PaginatedResponse(c, http.StatusOK, "Foods retrieved successfully", foods, pagination)
 }
 
 // GetRestaurantFoods retrieves all the foods of a particular restaurant
@@ -204,8 +211,14 @@ func (f *FoodController) GetRestaurantFoods(c *gin.Context) {
 		return
 	}
 
-	// Call the GetRestaurantFoods service
-	foods, err := f.FoodService.GetRestaurantFoods(restaurantId)
+	query, err := utils.ParseListQuery(c, foodListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	// Call the GetAllFoods service
+	foods, pagination, err := f.FoodService.GetAllFoods(query.Where("restaurant_id", restaurantId))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve foods", err.Error())
@@ -213,16 +226,20 @@ func (f *FoodController) GetRestaurantFoods(c *gin.Context) {
 	}
 
 	// Return the list of foods
-	utils.SuccessResponse(c, http.StatusOK, "Foods retrieved successfully", foods)
+	utils.PaginatedResponse(c, http.StatusOK, "Foods retrieved successfully", foods, pagination)
 }
 
 // SearchFood
 func (f *FoodController) SearchFood(c *gin.Context) {
 	// Get the search query parameter
 	query := c.Query("q")
+	restaurantId, valid := utils.OptionalQueryID(c, "restaurant_id")
+	if !valid {
+		return
+	}
 
 	// Call the SearchFood service
-	foods, err := f.FoodService.SearchFoods(query)
+	foods, err := f.FoodService.SearchFoods(query, restaurantId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search for foods", err.Error())
@@ -239,8 +256,10 @@ func (f *FoodController) RecommendedFoods(c *gin.Context) {
 	if !valid {
 		return
 	}
+	loggedInUser, _ := c.Get("user")
+
 	// Call the RecommendedFoods service
-	foods, err := f.FoodService.GetRecommendedFoods(restaurantId)
+	foods, err := f.FoodService.GetRecommendedFoods(c.Request.Context(), loggedInUser.(models.User).ID, restaurantId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recommended foods", err.Error())
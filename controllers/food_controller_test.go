@@ -0,0 +1,95 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type food struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Price        float64 `json:"price"`
+	RestaurantID uint    `json:"restaurant_id"`
+	CategoryID   uint    `json:"category_id"`
+}
+
+func TestFoodLifecycle(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+
+	var created food
+	h.Request(t, http.MethodPost, "/api/foods/", token, map[string]interface{}{
+		"name": "Japchae", "description": "Glass noodles", "price": 10.5,
+		"restaurant_id": h.Fixtures.Restaurant.ID, "category_id": h.Fixtures.Category.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &created)
+	path := fmt.Sprintf("/api/foods/%d", created.ID)
+
+	// the food stays in its restaurant whatever the body says
+	var updated food
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"price": 11, "restaurant_id": h.Fixtures.Restaurant.ID + 1}).
+		Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.ID != created.ID || updated.Price != 11 || updated.Name != "Japchae" || updated.RestaurantID != h.Fixtures.Restaurant.ID {
+		t.Fatalf("unexpected food after the update %+v", updated)
+	}
+
+	var foods []food
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/foods/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &foods)
+	if len(foods) != 2 {
+		t.Fatalf("the update must not add a food, got %d foods", len(foods))
+	}
+
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusInternalServerError)
+}
+
+func TestAddFoodRejectsDuplicateName(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+
+	h.Request(t, http.MethodPost, "/api/foods/", token, map[string]interface{}{
+		"name": h.Fixtures.Food.Name, "price": 1, "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusInternalServerError)
+}
+
+func TestFoodRequiresMenuPermission(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+	path := fmt.Sprintf("/api/foods/%d", h.Fixtures.Food.ID)
+
+	h.Request(t, http.MethodPost, "/api/foods/", token, map[string]interface{}{
+		"name": "Japchae", "price": 1, "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"price": 1}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodDelete, "/api/foods/999", token, nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestBrowseFoods(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	var got food
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/foods/%d", h.Fixtures.Food.ID), token, nil).Expect(t, http.StatusOK).Decode(t, &got)
+	if got.Name != h.Fixtures.Food.Name {
+		t.Fatalf("expected the fixture food, got %+v", got)
+	}
+
+	lists := []string{
+		"/api/foods/",
+		"/api/foods/?sort=-price&restaurant_id=" + fmt.Sprint(h.Fixtures.Restaurant.ID),
+		"/api/foods/search?q=bibim",
+		fmt.Sprintf("/api/foods/restaurant/%d/recommended", h.Fixtures.Restaurant.ID),
+	}
+	for _, path := range lists {
+		var foods []food
+		h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &foods)
+		if len(foods) != 1 || foods[0].ID != h.Fixtures.Food.ID {
+			t.Errorf("%s: expected the fixture food, got %+v", path, foods)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"madang_api/buildinfo"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	HealthService *services.HealthService
+}
+
+// HealthControllerInterface defines the probes of the orchestrator
+type HealthControllerInterface interface {
+	Healthz(c *gin.Context)
+	Readyz(c *gin.Context)
+	Version(c *gin.Context)
+}
+
+// Healthz answers as long as the process can serve requests at all
+func (ctrl *HealthController) Healthz(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Service is alive", nil)
+}
+
+// Readyz checks the dependencies of the API and answers 503 with the failing ones when it cannot serve traffic
+func (ctrl *HealthController) Readyz(c *gin.Context) {
+	readiness := ctrl.HealthService.CheckReadiness(c.Request.Context())
+	if !readiness.Ready {
+		utils.ErrorResponseWithData(c, http.StatusServiceUnavailable, "Service is not ready", "Service Unavailable", readiness)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Service is ready", readiness)
+}
+
+// Version returns the version, commit and build time of the running binary
+func (ctrl *HealthController) Version(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Version retrieved successfully", buildinfo.Get())
+}
@@ -0,0 +1,76 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type readiness struct {
+	Ready  bool `json:"ready"`
+	Checks map[string]struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"checks"`
+}
+
+func TestHealthProbes(t *testing.T) {
+	h := testutil.New(t)
+
+	h.Request(t, http.MethodGet, "/healthz", "", nil).Expect(t, http.StatusOK)
+
+	var version struct {
+		Version   string `json:"version"`
+		GoVersion string `json:"go_version"`
+	}
+	h.Request(t, http.MethodGet, "/version", "", nil).Expect(t, http.StatusOK).Decode(t, &version)
+	if version.Version == "" || version.GoVersion == "" {
+		t.Fatalf("unexpected version %+v", version)
+	}
+
+	// the harness does not start the background workers
+	var status readiness
+	h.Request(t, http.MethodGet, "/readyz", "", nil).Expect(t, http.StatusServiceUnavailable).Decode(t, &status)
+	if status.Ready || status.Checks["database"].Status != "ok" || status.Checks["migrations"].Status != "ok" || status.Checks["workers"].Status != "failing" {
+		t.Fatalf("expected only the workers to fail, got %+v", status)
+	}
+
+	h.Container.StartWorkers()
+	t.Cleanup(func() {
+		if err := h.Container.StopWorkers(context.Background()); err != nil {
+			t.Errorf("stopping the workers: %v", err)
+		}
+	})
+	h.Request(t, http.MethodGet, "/readyz", "", nil).Expect(t, http.StatusOK).Decode(t, &status)
+	if !status.Ready {
+		t.Fatalf("expected the service to be ready, got %+v", status)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+	placeOrder(t, h, customer)
+
+	body := h.Request(t, http.MethodGet, "/metrics", "", nil).Expect(t, http.StatusOK).Body
+	for _, series := range []string{
+		`madang_http_requests_total{method="POST",route="/api/orders/",status="201"}`,
+		`madang_http_request_duration_seconds_bucket{method="POST",route="/api/orders/",le="0.005"}`,
+		fmt.Sprintf(`madang_orders_created_total{restaurant_id="%d",status="pending"}`, h.Fixtures.Restaurant.ID),
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("expected the series %s in the metrics", series)
+		}
+	}
+
+	// with a token configured the scraper has to send it
+	h.Container.Config.MetricsToken = "scrape-token"
+	h.Router = h.Container.Router()
+	h.Request(t, http.MethodGet, "/metrics", "", nil).Expect(t, http.StatusUnauthorized)
+	h.Request(t, http.MethodGet, "/metrics", customer, nil).Expect(t, http.StatusUnauthorized)
+	h.Request(t, http.MethodGet, "/metrics", "scrape-token", nil).Expect(t, http.StatusOK)
+}
@@ -5,16 +5,13 @@ import (
 	"madang_api/services"
 	"madang_api/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type InitController struct {
-	UserService     services.UserService
-	CategoryService services.CategoryService
-	FoodService     services.FoodService
-	TableService    services.TableService
-	OrderService    services.OrderService
+	FeedService *services.FeedService
 }
 
 // InitController defines the methods for handling initial items been loaded
@@ -22,7 +19,8 @@ type InitControllerInterface interface {
 	LoadData(ctx *gin.Context)
 }
 
-// Get Initialize items
+// Get Initialize items, i.e. the home feed of the logged in user.
+// ?restaurant_id picks the restaurant whose menu is shown, ?lat and ?lng enable the nearby restaurants
 func (f *InitController) LoadData(c *gin.Context) {
 	// Get the authenticated user from the context
 	loggedInUser, exists := c.Get("user")
@@ -34,95 +32,54 @@ func (f *InitController) LoadData(c *gin.Context) {
 	// Extract user ID from the user object
 	userId := loggedInUser.(models.User).ID
 
-	// Declare a variable to store the items
-	var items struct {
-		User              UserResponse      `json:"user"`
-		Categories        []models.Category `json:"categories"`
-		Foods             []models.Food     `json:"foods"`
-		Tables            []models.Table    `json:"tables"`
-		RecommendedFoods  []models.Food     `json:"recommended_foods"`
-		RecommendedTables []models.Table    `json:"recommended_tables"`
-		Orders            []models.Order    `json:"orders"`
-	}
-
-	//Get the User Details
-	user, err := f.UserService.GetUserByID(userId)
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve user", err.Error())
-		return
-	}
-
-	userDetails := UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Avatar:    user.Avatar,
-		Role:      user.Role,
-		Active:    user.Active,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
-
-	// Assign the retrieved user to the items struct
-	items.User = userDetails
-
-	//call the Restaurant Category service
-	categories, err := f.CategoryService.GetRestaurantCategories(4)
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err.Error())
-	}
-	items.Categories = categories
-
-	// Call the Restaurant Food service
-	foods, err := f.FoodService.GetRestaurantFoods(4)
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve foods", err.Error())
+	var options services.FeedOptions
+	restaurantID, valid := utils.OptionalQueryID(c, "restaurant_id")
+	if !valid {
 		return
 	}
-
-	// Assign the retrieved foods to the items struct
-	items.Foods = foods
-
-	//call recommended foods service
-	recommendedFoods, err := f.FoodService.GetRecommendedFoods(4)
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recommended foods", err.Error())
-	}
-	// Assign the retrieved foods to the items struct
-	items.RecommendedFoods = recommendedFoods
-
-	// Call the Table service
-	tables, err := f.TableService.GetRestaurantTables(4) // Declare 'tables' here
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve tables", err.Error())
-		return
+	options.RestaurantID = restaurantID
+	if c.Query("lat") != "" || c.Query("lng") != "" {
+		latitude, err := strconv.ParseFloat(c.Query("lat"), 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid latitude", err.Error())
+			return
+		}
+		longitude, err := strconv.ParseFloat(c.Query("lng"), 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid longitude", err.Error())
+			return
+		}
+		options.Latitude = &latitude
+		options.Longitude = &longitude
 	}
 
-	// Assign the retrieved tables to the items struct
-	items.Tables = tables
-
-	//Call the Recommended Table service
-	recommendedTables, err := f.TableService.GetRecommendedTables(4)
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recommended tables", err.Error())
+	// the user was loaded by AuthMiddleware, the feed sections are loaded concurrently
+	user := loggedInUser.(models.User)
+	feed := f.FeedService.GetHomeFeed(c.Request.Context(), userId, options)
+
+	items := struct {
+		User UserResponse `json:"user"`
+		*services.HomeFeed
+	}{
+		User: UserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			Phone:     user.Phone,
+			Avatar:    user.Avatar,
+			Role:      user.Role,
+			Active:    user.Active,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		},
+		HomeFeed: feed,
 	}
-	items.RecommendedTables = recommendedTables
 
-	// Call the Order service
-	orders, err := f.OrderService.GetUserOrders(userId)
-	// Handle error
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
+	message := "Home feed retrieved successfully"
+	if len(feed.FailedSections) > 0 {
+		message = "Home feed retrieved with some sections missing"
 	}
-	items.Orders = orders
 
 	// Return the items
-	utils.SuccessResponse(c, http.StatusOK, "Foods and tables retrieved successfully", items)
+	utils.SuccessResponse(c, http.StatusOK, message, items)
 }
@@ -0,0 +1,97 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+	"madang_api/tracing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHomeFeed(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+	placed := placeOrder(t, h, customer)
+
+	var feed struct {
+		User struct {
+			ID uint `json:"id"`
+		} `json:"user"`
+		Restaurant *struct {
+			ID uint `json:"id"`
+		} `json:"restaurant"`
+		Foods             []food `json:"foods"`
+		NearbyRestaurants []struct {
+			ID uint `json:"id"`
+		} `json:"nearby_restaurants"`
+		ActiveOrders   []order `json:"active_orders"`
+		FailedSections []struct {
+			Section string `json:"section"`
+			Error   string `json:"error"`
+		} `json:"failed_sections"`
+	}
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/inits/?restaurant_id=%d&lat=37.57&lng=126.98", h.Fixtures.Restaurant.ID), customer, nil).
+		Expect(t, http.StatusOK).Decode(t, &feed)
+	if len(feed.FailedSections) != 0 {
+		t.Fatalf("expected every section to load, got %+v", feed.FailedSections)
+	}
+	if feed.User.ID != h.Fixtures.Customer.ID || feed.Restaurant == nil || feed.Restaurant.ID != h.Fixtures.Restaurant.ID {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+	if len(feed.Foods) != 1 || len(feed.NearbyRestaurants) != 1 {
+		t.Fatalf("expected the fixture food and restaurant, got %+v", feed)
+	}
+	if len(feed.ActiveOrders) != 1 || feed.ActiveOrders[0].ID != placed.ID {
+		t.Fatalf("expected the placed order to be active, got %+v", feed.ActiveOrders)
+	}
+
+	h.Request(t, http.MethodGet, "/api/inits/?lat=north&lng=126.98", customer, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/inits/?restaurant_id=abc", customer, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/inits/", "", nil).Expect(t, http.StatusUnauthorized)
+}
+
+func TestHomeFeedSectionsAreTraced(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+
+	if _, err := tracing.Setup(context.Background(), "none", ""); err != nil {
+		t.Fatalf("setting up tracing: %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/inits/?restaurant_id=%d&lat=37.57&lng=126.98", h.Fixtures.Restaurant.ID), customer, nil).
+		Expect(t, http.StatusOK)
+
+	// every section runs its queries within its own span
+	sections := map[trace.SpanID]string{}
+	for _, span := range recorder.Ended() {
+		if span.Name() == "FeedService.section" {
+			for _, attr := range span.Attributes() {
+				if attr.Key == "feed.section" {
+					sections[span.SpanContext().SpanID()] = attr.Value.AsString()
+				}
+			}
+		}
+	}
+	queried := map[string]bool{}
+	for _, span := range recorder.Ended() {
+		if name, ok := sections[span.Parent().SpanID()]; ok && span.SpanKind() == trace.SpanKindClient {
+			queried[name] = true
+		}
+	}
+	for _, name := range []string{"restaurant", "categories", "foods", "tables", "recommended_foods", "recommended_tables", "nearby_restaurants"} {
+		if !queried[name] {
+			t.Errorf("the queries of the %s section are not in its trace, got spans %v", name, spanNames(recorder.Ended()))
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryController struct {
+	InventoryService *services.InventoryService
+}
+
+// InventoryControllerInterface defines the methods for handling ingredient and stock related operations
+type InventoryControllerInterface interface {
+	AddIngredient(c *gin.Context)
+	UpdateIngredient(c *gin.Context)
+	DeleteIngredient(c *gin.Context)
+	GetIngredient(c *gin.Context)
+	GetRestaurantIngredients(c *gin.Context)
+	GetLowStockIngredients(c *gin.Context)
+	AdjustStock(c *gin.Context)
+	GetStockHistory(c *gin.Context)
+	GetFoodRecipe(c *gin.Context)
+	SetFoodRecipe(c *gin.Context)
+	GetAddonRecipe(c *gin.Context)
+	SetAddonRecipe(c *gin.Context)
+}
+
+// AddIngredient handles the addition of a new ingredient
+func (ctrl *InventoryController) AddIngredient(c *gin.Context) {
+	var body struct {
+		Name              string  `json:"name"`
+		Unit              string  `json:"unit"`
+		Quantity          float64 `json:"quantity"`
+		LowStockThreshold float64 `json:"low_stock_threshold"`
+		RestaurantID      uint    `json:"restaurant_id"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(c, body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	var ingredient models.Ingredient
+	ingredient.Name = body.Name
+	ingredient.Unit = body.Unit
+	ingredient.Quantity = body.Quantity
+	ingredient.LowStockThreshold = body.LowStockThreshold
+	ingredient.RestaurantID = body.RestaurantID
+
+	newIngredient, err := ctrl.InventoryService.AddIngredient(&ingredient)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to add ingredient", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Ingredient added successfully", newIngredient)
+}
+
+// UpdateIngredient handles the update of an ingredient's details
+func (ctrl *InventoryController) UpdateIngredient(c *gin.Context) {
+	ingredientID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Name              *string  `json:"name"`
+		Unit              *string  `json:"unit"`
+		LowStockThreshold *float64 `json:"low_stock_threshold"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	existingIngredient, err := ctrl.InventoryService.GetIngredient(ingredientID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Ingredient not found", err.Error())
+		return
+	}
+
+	if body.Name != nil {
+		existingIngredient.Name = *body.Name
+	}
+	if body.Unit != nil {
+		existingIngredient.Unit = *body.Unit
+	}
+	if body.LowStockThreshold != nil {
+		existingIngredient.LowStockThreshold = *body.LowStockThreshold
+	}
+
+	updatedIngredient, err := ctrl.InventoryService.UpdateIngredient(existingIngredient)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update ingredient", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient updated successfully", updatedIngredient)
+}
+
+// DeleteIngredient handles the deletion of an ingredient
+func (ctrl *InventoryController) DeleteIngredient(c *gin.Context) {
+	ingredientID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.InventoryService.DeleteIngredient(ingredientID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete ingredient", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient deleted successfully", nil)
+}
+
+// GetIngredient retrieves a specific ingredient
+func (ctrl *InventoryController) GetIngredient(c *gin.Context) {
+	ingredientID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	ingredient, err := ctrl.InventoryService.GetIngredient(ingredientID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Ingredient not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ingredient retrieved successfully", ingredient)
+}
+
+// ingredientListSpec is what the ingredients lists can be sorted and filtered by
+var ingredientListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "quantity": "quantity", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"unit": utils.TextColumn("unit")},
+	DefaultSort: "name",
+}
+
+// GetRestaurantIngredients retrieves all the ingredients of a particular restaurant
+func (ctrl *InventoryController) GetRestaurantIngredients(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	query, err := utils.ParseListQuery(c, ingredientListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	ingredients, pagination, err := ctrl.InventoryService.GetRestaurantIngredients(restaurantID, query)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve ingredients", err.Error())
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "Ingredients retrieved successfully", ingredients, pagination)
+}
+
+// GetLowStockIngredients retrieves the ingredients of a restaurant that are running low
+func (ctrl *InventoryController) GetLowStockIngredients(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	ingredients, err := ctrl.InventoryService.GetLowStockIngredients(restaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve low stock ingredients", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Low stock ingredients retrieved successfully", ingredients)
+}
+
+// AdjustStock handles a manual stock adjustment of an ingredient
+func (ctrl *InventoryController) AdjustStock(c *gin.Context) {
+	ingredientID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Change float64 `json:"change" binding:"required"`
+		Reason string  `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(c, body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	loggedInUser, exists := c.Get("user")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "bad request", "User not found")
+		return
+	}
+
+	ingredient, err := ctrl.InventoryService.AdjustStock(ingredientID, body.Change, body.Reason, loggedInUser.(models.User).ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to adjust stock", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock adjusted successfully", ingredient)
+}
+
+// GetStockHistory retrieves the stock adjustment history of an ingredient
+func (ctrl *InventoryController) GetStockHistory(c *gin.Context) {
+	ingredientID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	history, err := ctrl.InventoryService.GetStockHistory(ingredientID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve stock history", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stock history retrieved successfully", history)
+}
+
+type recipeLine struct {
+	IngredientID uint    `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity"`
+}
+
+// GetFoodRecipe retrieves the ingredients of a food
+func (ctrl *InventoryController) GetFoodRecipe(c *gin.Context) {
+	foodID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	recipe, err := ctrl.InventoryService.GetFoodRecipe(foodID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recipe", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recipe retrieved successfully", recipe)
+}
+
+// SetFoodRecipe replaces the ingredients of a food
+func (ctrl *InventoryController) SetFoodRecipe(c *gin.Context) {
+	foodID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Ingredients []recipeLine `json:"ingredients"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	var recipe []models.FoodIngredient
+	for _, line := range body.Ingredients {
+		recipe = append(recipe, models.FoodIngredient{
+			IngredientID: line.IngredientID,
+			Quantity:     line.Quantity,
+		})
+	}
+
+	newRecipe, err := ctrl.InventoryService.SetFoodRecipe(foodID, recipe)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update recipe", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recipe updated successfully", newRecipe)
+}
+
+// GetAddonRecipe retrieves the ingredients of an addon
+func (ctrl *InventoryController) GetAddonRecipe(c *gin.Context) {
+	addonID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	recipe, err := ctrl.InventoryService.GetAddonRecipe(addonID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recipe", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recipe retrieved successfully", recipe)
+}
+
+// SetAddonRecipe replaces the ingredients of an addon
+func (ctrl *InventoryController) SetAddonRecipe(c *gin.Context) {
+	addonID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Ingredients []recipeLine `json:"ingredients"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	var recipe []models.AddonIngredient
+	for _, line := range body.Ingredients {
+		recipe = append(recipe, models.AddonIngredient{
+			IngredientID: line.IngredientID,
+			Quantity:     line.Quantity,
+		})
+	}
+
+	newRecipe, err := ctrl.InventoryService.SetAddonRecipe(addonID, recipe)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update recipe", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recipe updated successfully", newRecipe)
+}
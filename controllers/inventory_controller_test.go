@@ -0,0 +1,84 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type ingredient struct {
+	ID       uint    `json:"id"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+}
+
+func TestConfirmedOrderTakesIngredientsOutOfStock(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+
+	var rice ingredient
+	h.Request(t, http.MethodPost, "/api/ingredients/", manager, map[string]interface{}{
+		"name": "Rice", "unit": "g", "quantity": 10, "low_stock_threshold": 2, "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &rice)
+	path := fmt.Sprintf("/api/ingredients/%d", rice.ID)
+
+	recipe := fmt.Sprintf("/api/recipes/food/%d", h.Fixtures.Food.ID)
+	h.Request(t, http.MethodPut, recipe, manager, map[string]interface{}{
+		"ingredients": []map[string]interface{}{{"ingredient_id": rice.ID, "quantity": 1}},
+	}).Expect(t, http.StatusOK)
+	var lines []map[string]interface{}
+	h.Request(t, http.MethodGet, recipe, manager, nil).Expect(t, http.StatusOK).Decode(t, &lines)
+	if len(lines) != 1 {
+		t.Fatalf("expected a single recipe line, got %d", len(lines))
+	}
+
+	placed := placeOrder(t, h, h.LoginAs(t, "customer"))
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/orders/%d/status", placed.ID), manager, map[string]string{"status": "confirmed"}).
+		Expect(t, http.StatusOK)
+
+	h.Request(t, http.MethodGet, path, manager, nil).Expect(t, http.StatusOK).Decode(t, &rice)
+	if rice.Quantity != 8 {
+		t.Fatalf("the 2 ordered foods must use 2 of the 10 rice, %v left", rice.Quantity)
+	}
+
+	h.Request(t, http.MethodPost, path+"/adjust", manager, map[string]interface{}{"change": -7, "reason": "waste"}).
+		Expect(t, http.StatusOK).Decode(t, &rice)
+	h.Request(t, http.MethodPost, path+"/adjust", manager, map[string]interface{}{"change": -5, "reason": "waste"}).
+		Expect(t, http.StatusBadRequest)
+
+	var low []ingredient
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/ingredients/restaurant/%d/low-stock", h.Fixtures.Restaurant.ID), manager, nil).
+		Expect(t, http.StatusOK).Decode(t, &low)
+	if len(low) != 1 || low[0].ID != rice.ID {
+		t.Fatalf("expected the rice to be low on stock, got %+v", low)
+	}
+
+	var history []map[string]interface{}
+	h.Request(t, http.MethodGet, path+"/history", manager, nil).Expect(t, http.StatusOK).Decode(t, &history)
+	if len(history) != 2 {
+		t.Fatalf("expected the order and the waste in the history, got %d adjustments", len(history))
+	}
+
+	h.Request(t, http.MethodDelete, path, manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, manager, nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestIngredientsRequireMenuPermission(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+
+	h.Request(t, http.MethodPost, "/api/ingredients/", customer, map[string]interface{}{
+		"name": "Rice", "unit": "g", "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/recipes/food/%d", h.Fixtures.Food.ID), customer, map[string]interface{}{}).
+		Expect(t, http.StatusForbidden)
+
+	// the stock and the recipes are only shown to the staff
+	restaurant := fmt.Sprintf("/api/ingredients/restaurant/%d", h.Fixtures.Restaurant.ID)
+	h.Request(t, http.MethodGet, restaurant, customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, restaurant+"/low-stock", customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/recipes/food/%d", h.Fixtures.Food.ID), customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/recipes/addon/%d", h.Fixtures.Addon.ID), customer, nil).Expect(t, http.StatusForbidden)
+}
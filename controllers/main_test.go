@@ -0,0 +1,11 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"madang_api/testutil"
+)
+
+func TestMain(m *testing.M) {
+	testutil.Main(m)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MenuController struct {
+	MenuService *services.MenuService
+}
+
+// MenuControllerInterface defines the methods for bulk menu operations
+type MenuControllerInterface interface {
+	ExportMenu(c *gin.Context)
+	ImportMenu(c *gin.Context)
+}
+
+// ExportMenu returns the full menu of a restaurant as JSON or, with ?format=csv, as a CSV download
+func (ctrl *MenuController) ExportMenu(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	menu, err := ctrl.MenuService.ExportMenu(restaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export menu", err.Error())
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		utils.SuccessResponse(c, http.StatusOK, "Menu exported successfully", menu)
+	case "csv":
+		var buf bytes.Buffer
+		if err := services.WriteMenuCSV(&buf, menu); err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export menu", err.Error())
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=menu_%d.csv", restaurantID))
+		c.Data(http.StatusOK, "text/csv", buf.Bytes())
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid format", "format must be json or csv")
+	}
+}
+
+// ImportMenu replaces the menu of a restaurant with the uploaded one. The menu is read from a
+// "file" form upload or from the raw request body, as JSON or, with ?format=csv, as CSV.
+// With ?dry_run=true only the planned creates, updates and deletes are returned
+func (ctrl *MenuController) ImportMenu(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	dryRun := c.Query("dry_run") == "true"
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var menu *services.Menu
+	switch format {
+	case "json":
+		menu = &services.Menu{}
+		if err := json.NewDecoder(reader).Decode(menu); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return
+		}
+	case "csv":
+		parsed, err := services.ReadMenuCSV(reader)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return
+		}
+		menu = parsed
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid format", "format must be json or csv")
+		return
+	}
+
+	plan, err := ctrl.MenuService.ImportMenu(restaurantID, menu, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to import menu", err.Error())
+		return
+	}
+	if len(plan.Errors) > 0 {
+		utils.ErrorResponseWithData(c, http.StatusUnprocessableEntity, "Validation error", strings.Join(plan.Errors, "; "), plan)
+		return
+	}
+
+	if dryRun {
+		utils.SuccessResponse(c, http.StatusOK, "Menu import preview generated successfully", plan)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Menu imported successfully", plan)
+}
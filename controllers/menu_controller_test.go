@@ -0,0 +1,111 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/testutil"
+)
+
+func TestExportAndImportMenu(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	menuPath := fmt.Sprintf("/api/menus/restaurant/%d", h.Fixtures.Restaurant.ID)
+
+	var menu services.Menu
+	h.Request(t, http.MethodGet, menuPath+"/export", manager, nil).Expect(t, http.StatusOK).Decode(t, &menu)
+	if len(menu.Foods) != 1 || menu.Foods[0].Name != h.Fixtures.Food.Name || menu.Foods[0].Category != h.Fixtures.Category.Name {
+		t.Fatalf("unexpected exported foods %+v", menu.Foods)
+	}
+
+	csv := h.Request(t, http.MethodGet, menuPath+"/export?format=csv", manager, nil).Expect(t, http.StatusOK)
+	if !strings.Contains(csv.Body, h.Fixtures.Food.Name) {
+		t.Fatalf("the csv export misses the fixture food: %s", csv.Body)
+	}
+
+	menu.Foods = append(menu.Foods, services.MenuFood{Name: "Japchae", Price: 10, Category: h.Fixtures.Category.Name})
+
+	var plan services.MenuImportPlan
+	h.Request(t, http.MethodPost, menuPath+"/import?dry_run=true", manager, menu).Expect(t, http.StatusOK).Decode(t, &plan)
+	if plan.Applied || len(plan.Creates) != 1 || plan.Creates[0].Name != "Japchae" || len(plan.Deletes) != 0 {
+		t.Fatalf("unexpected dry run plan %+v", plan)
+	}
+	assertFoodCount(t, h, manager, 1)
+
+	h.Request(t, http.MethodPost, menuPath+"/import", manager, menu).Expect(t, http.StatusOK).Decode(t, &plan)
+	if !plan.Applied {
+		t.Fatalf("the import was not applied %+v", plan)
+	}
+	assertFoodCount(t, h, manager, 2)
+
+	h.Request(t, http.MethodGet, menuPath+"/export?format=xml", manager, nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestImportKeepsOrderedItems(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	menuPath := fmt.Sprintf("/api/menus/restaurant/%d", h.Fixtures.Restaurant.ID)
+	placeOrder(t, h, h.LoginAs(t, "customer"))
+
+	// the ordered food and table are left out, with the category holding them
+	menu := services.Menu{
+		Categories: []services.MenuCategory{{Name: "Drinks", Type: "food"}},
+		Foods:      []services.MenuFood{{Name: "Sikhye", Price: 3, Category: "Drinks"}},
+	}
+
+	var plan services.MenuImportPlan
+	h.Request(t, http.MethodPost, menuPath+"/import?dry_run=true", manager, menu).Expect(t, http.StatusOK).Decode(t, &plan)
+	if len(plan.Deactivates) != 1 || plan.Deactivates[0].Name != h.Fixtures.Food.Name {
+		t.Fatalf("expected the ordered food to be deactivated, got %+v", plan)
+	}
+	kept := map[string]bool{}
+	for _, change := range plan.Kept {
+		kept[change.Type+":"+change.Name] = true
+	}
+	if !kept["table:"+h.Fixtures.Table.Name] || !kept["category:"+h.Fixtures.Category.Name] {
+		t.Fatalf("expected the ordered table and its category to be kept, got %+v", plan.Kept)
+	}
+	for _, change := range plan.Deletes {
+		if change.Type != "addon" {
+			t.Fatalf("only the addon no order refers to can be deleted, got %+v", plan.Deletes)
+		}
+	}
+
+	h.Request(t, http.MethodPost, menuPath+"/import", manager, menu).Expect(t, http.StatusOK).Decode(t, &plan)
+	if !plan.Applied {
+		t.Fatalf("the import was not applied %+v", plan)
+	}
+	var ordered models.Food
+	if err := h.DB.First(&ordered, h.Fixtures.Food.ID).Error; err != nil {
+		t.Fatalf("the ordered food is gone: %v", err)
+	}
+	if ordered.Available {
+		t.Fatal("the ordered food is still available")
+	}
+	if err := h.DB.First(&models.Table{}, h.Fixtures.Table.ID).Error; err != nil {
+		t.Fatalf("the ordered table is gone: %v", err)
+	}
+}
+
+func TestMenuRequiresPermission(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+	menuPath := fmt.Sprintf("/api/menus/restaurant/%d", h.Fixtures.Restaurant.ID)
+
+	h.Request(t, http.MethodGet, menuPath+"/export", customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPost, menuPath+"/import", customer, services.Menu{}).Expect(t, http.StatusForbidden)
+}
+
+func assertFoodCount(t *testing.T, h *testutil.Harness, token string, count int) {
+	t.Helper()
+	var foods []food
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/foods/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &foods)
+	if len(foods) != count {
+		t.Fatalf("expected %d foods, got %d", count, len(foods))
+	}
+}
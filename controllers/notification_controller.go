@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	NotificationService *services.NotificationService
+}
+
+// NotificationControllerInterface defines the methods for reading notifications
+type NotificationControllerInterface interface {
+	GetMyNotifications(c *gin.Context)
+	MarkAsRead(c *gin.Context)
+}
+
+// notificationListSpec is what the notifications lists can be sorted and filtered by
+var notificationListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"type": utils.TextColumn("type")},
+	DefaultSort: "-id",
+}
+
+// GetMyNotifications retrieves the notifications of the logged in user
+func (ctrl *NotificationController) GetMyNotifications(c *gin.Context) {
+	loggedInUser, exists := c.Get("user")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "bad request", "User not found")
+		return
+	}
+
+	query, err := utils.ParseListQuery(c, notificationListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	notifications, pagination, err := ctrl.NotificationService.GetUserNotifications(loggedInUser.(models.User).ID, query)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve notifications", err.Error())
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "Notifications retrieved successfully", notifications, pagination)
+}
+
+// MarkAsRead marks a notification of the logged in user as read
+func (ctrl *NotificationController) MarkAsRead(c *gin.Context) {
+	notificationID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	loggedInUser, exists := c.Get("user")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "bad request", "User not found")
+		return
+	}
+
+	notification, err := ctrl.NotificationService.MarkAsRead(loggedInUser.(models.User).ID, notificationID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Notification not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", notification)
+}
@@ -0,0 +1,48 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type notification struct {
+	ID     uint    `json:"id"`
+	UserID uint    `json:"user_id"`
+	Type   string  `json:"type"`
+	ReadAt *string `json:"read_at"`
+}
+
+func TestNotificationsOfTheUser(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+
+	// inviting a user to the staff notifies them
+	h.Request(t, http.MethodPost, fmt.Sprintf("/api/staff/restaurant/%d/invite", h.Fixtures.Restaurant.ID), manager, map[string]string{
+		"email": h.Fixtures.Customer.Email, "role": "waiter",
+	}).Expect(t, http.StatusCreated)
+
+	var notifications []notification
+	h.Request(t, http.MethodGet, "/api/notifications/", customer, nil).Expect(t, http.StatusOK).Decode(t, &notifications)
+	if len(notifications) != 1 || notifications[0].Type != "staff_invitation" || notifications[0].ReadAt != nil {
+		t.Fatalf("expected an unread invitation notification, got %+v", notifications)
+	}
+	path := fmt.Sprintf("/api/notifications/%d/read", notifications[0].ID)
+
+	// the notification belongs to the customer only
+	h.Request(t, http.MethodPut, path, manager, nil).Expect(t, http.StatusNotFound)
+
+	var read notification
+	h.Request(t, http.MethodPut, path, customer, nil).Expect(t, http.StatusOK).Decode(t, &read)
+	if read.ReadAt == nil {
+		t.Fatalf("expected the notification to be read, got %+v", read)
+	}
+
+	h.Request(t, http.MethodGet, "/api/notifications/", manager, nil).Expect(t, http.StatusOK).Decode(t, &notifications)
+	if len(notifications) != 0 {
+		t.Fatalf("the manager has no notification, got %+v", notifications)
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OpeningHoursController struct {
+	OpeningHoursService *services.OpeningHoursService
+}
+
+// OpeningHoursControllerInterface defines the methods for managing restaurant opening hours
+type OpeningHoursControllerInterface interface {
+	GetOpeningHours(c *gin.Context)
+	SetWeeklySchedule(c *gin.Context)
+	AddException(c *gin.Context)
+	DeleteException(c *gin.Context)
+}
+
+// GetOpeningHours retrieves the schedule of a restaurant and whether it is open now
+func (ctrl *OpeningHoursController) GetOpeningHours(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	hours, err := ctrl.OpeningHoursService.GetOpeningHours(restaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Restaurant not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Opening hours fetched successfully", hours)
+}
+
+// SetWeeklySchedule replaces the weekly schedule of a restaurant
+func (ctrl *OpeningHoursController) SetWeeklySchedule(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Timezone  string `json:"timezone"`
+		Intervals []struct {
+			DayOfWeek int    `json:"day_of_week"`
+			OpensAt   string `json:"opens_at"`
+			ClosesAt  string `json:"closes_at"`
+		} `json:"intervals"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	var intervals []models.OpeningInterval
+	for _, interval := range body.Intervals {
+		intervals = append(intervals, models.OpeningInterval{
+			DayOfWeek: interval.DayOfWeek,
+			OpensAt:   interval.OpensAt,
+			ClosesAt:  interval.ClosesAt,
+		})
+	}
+
+	hours, err := ctrl.OpeningHoursService.SetWeeklySchedule(restaurantID, body.Timezone, intervals)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update opening hours", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Opening hours updated successfully", hours)
+}
+
+// AddException adds a holiday closure or special opening hours on a date
+func (ctrl *OpeningHoursController) AddException(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Date     string `json:"date" binding:"required"`
+		Closed   bool   `json:"closed"`
+		OpensAt  string `json:"opens_at"`
+		ClosesAt string `json:"closes_at"`
+		Reason   string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	var exception models.OpeningException
+	exception.RestaurantID = restaurantID
+	exception.Date = body.Date
+	exception.Closed = body.Closed
+	exception.OpensAt = body.OpensAt
+	exception.ClosesAt = body.ClosesAt
+	exception.Reason = body.Reason
+
+	newException, err := ctrl.OpeningHoursService.AddException(&exception)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add opening hours exception", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Opening hours exception added successfully", newException)
+}
+
+// DeleteException removes a date specific exception
+func (ctrl *OpeningHoursController) DeleteException(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	exceptionID, valid := utils.ValidateID(c, "exception_id")
+	if !valid {
+		return
+	}
+
+	if err := ctrl.OpeningHoursService.DeleteException(restaurantID, exceptionID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to delete opening hours exception", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Opening hours exception deleted successfully", nil)
+}
@@ -0,0 +1,82 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"madang_api/services"
+	"madang_api/testutil"
+)
+
+func TestWeeklyScheduleAndExceptions(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	path := fmt.Sprintf("/api/restaurants/%d/hours/", h.Fixtures.Restaurant.ID)
+
+	var hours services.OpeningHours
+	h.Request(t, http.MethodGet, path, manager, nil).Expect(t, http.StatusOK).Decode(t, &hours)
+	if !hours.OpenNow || len(hours.Intervals) != 0 {
+		t.Fatalf("a restaurant without a schedule is always open, got %+v", hours)
+	}
+
+	// open every day around the clock but closed today
+	var intervals []map[string]interface{}
+	for day := 0; day < 7; day++ {
+		intervals = append(intervals, map[string]interface{}{"day_of_week": day, "opens_at": "00:00", "closes_at": "23:59"})
+	}
+	h.Request(t, http.MethodPut, path, manager, map[string]interface{}{"timezone": "UTC", "intervals": intervals}).
+		Expect(t, http.StatusOK).Decode(t, &hours)
+	if len(hours.Intervals) != 7 {
+		t.Fatalf("expected 7 intervals, got %d", len(hours.Intervals))
+	}
+
+	var exception struct {
+		ID uint `json:"id"`
+	}
+	h.Request(t, http.MethodPost, path+"exceptions", manager, map[string]interface{}{
+		"date": time.Now().UTC().Format("2006-01-02"), "closed": true, "reason": "Holiday",
+	}).Expect(t, http.StatusCreated).Decode(t, &exception)
+
+	h.Request(t, http.MethodGet, path, manager, nil).Expect(t, http.StatusOK).Decode(t, &hours)
+	if hours.OpenNow || hours.NextOpening == nil || len(hours.Exceptions) != 1 {
+		t.Fatalf("expected the restaurant to be closed for the day, got %+v", hours)
+	}
+
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%sexceptions/%d", path, exception.ID), manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("%sexceptions/%d", path, exception.ID), manager, nil).Expect(t, http.StatusNotFound)
+}
+
+func TestExceptionsWithoutWeeklySchedule(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	path := fmt.Sprintf("/api/restaurants/%d/hours/", h.Fixtures.Restaurant.ID)
+
+	// a restaurant open around the clock still closes for the day
+	today := time.Now().UTC()
+	h.Request(t, http.MethodPost, path+"exceptions", manager, map[string]interface{}{
+		"date": today.Format("2006-01-02"), "closed": true, "reason": "Holiday",
+	}).Expect(t, http.StatusCreated)
+
+	var hours services.OpeningHours
+	h.Request(t, http.MethodGet, path, manager, nil).Expect(t, http.StatusOK).Decode(t, &hours)
+	tomorrow := time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, time.UTC)
+	if hours.OpenNow || hours.NextOpening == nil || !hours.NextOpening.Equal(tomorrow) {
+		t.Fatalf("expected the restaurant to be closed until midnight, got %+v", hours)
+	}
+}
+
+func TestInvalidOpeningHours(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	path := fmt.Sprintf("/api/restaurants/%d/hours/", h.Fixtures.Restaurant.ID)
+
+	h.Request(t, http.MethodPut, path, manager, map[string]interface{}{
+		"intervals": []map[string]interface{}{{"day_of_week": 7, "opens_at": "09:00", "closes_at": "17:00"}},
+	}).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPut, path, manager, map[string]interface{}{"timezone": "Nowhere/City"}).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPost, path+"exceptions", manager, map[string]interface{}{"date": "tomorrow", "closed": true}).
+		Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/restaurants/999/hours/", manager, nil).Expect(t, http.StatusNotFound)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"madang_api/models"
 	"madang_api/services"
 	"madang_api/utils"
@@ -11,7 +12,7 @@ import (
 
 // OrderControllerImpl implements the OrderController interface
 type OrderController struct {
-	OrderService services.OrderService
+	OrderService *services.OrderService
 }
 
 // OrderController defines the methods for handling order related operations
@@ -61,7 +62,9 @@ func (ctrl *OrderController) AddOrder(c *gin.Context) {
 	order.UserID = body.UserID
 	order.TableID = &body.TableID
 	order.RestaurantID = body.RestaurantID
-	order.Status = body.Status
+	if body.Status != "" {
+		order.Status = body.Status
+	}
 
 	// Convert body.Foods to []models.FoodOrder
 	var foodOrders []models.FoodOrder
@@ -88,7 +91,7 @@ func (ctrl *OrderController) AddOrder(c *gin.Context) {
 	var addonOrders []models.AddonOrder
 	for _, addon := range body.Addons {
 		addonOrder := models.AddonOrder{
-			Addon:    models.Addon{ID: addon.ID},
+			AddonID:  addon.ID,
 			Quantity: addon.Quantity,
 		}
 		addonOrders = append(addonOrders, addonOrder)
@@ -99,7 +102,7 @@ func (ctrl *OrderController) AddOrder(c *gin.Context) {
 	order.SpecialNotes = body.SpecialNotes
 
 	// Call the AddOrder service
-	newOrder, err := ctrl.OrderService.AddOrder(&order)
+	newOrder, err := ctrl.OrderService.AddOrder(c.Request.Context(), &order)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to add order", err.Error())
 		return
@@ -115,10 +118,8 @@ func (f *OrderController) UpdateOrder(c *gin.Context) {
 		return
 	}
 	var body struct {
-		UserID       uint `json:"user_id"`
-		TableID      uint `json:"table_id"`
-		RestaurantID uint `json:"restaurant_id"`
-		Foods        []struct {
+		TableID uint `json:"table_id"`
+		Foods   []struct {
 			ID       uint `json:"id"`
 			Quantity int  `json:"quantity"`
 		} `json:"foods"`
@@ -146,17 +147,17 @@ func (f *OrderController) UpdateOrder(c *gin.Context) {
 	}
 
 	// Check if the order item exists
-	_, err := f.OrderService.GetOrder(orderId)
+	order, err := f.OrderService.GetOrder(c.Request.Context(), orderId)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Order not found", err.Error())
 		return
 	}
 
-	var order models.Order
-	order.UserID = body.UserID
+	// the customer and the restaurant of an order never change
 	order.TableID = &body.TableID
-	order.RestaurantID = body.RestaurantID
-	order.Status = body.Status
+	if body.Status != "" {
+		order.Status = body.Status
+	}
 
 	// Convert body.Foods to []models.FoodOrder
 	var foodOrders []models.FoodOrder
@@ -181,7 +182,7 @@ func (f *OrderController) UpdateOrder(c *gin.Context) {
 	var addonOrders []models.AddonOrder
 	for _, addon := range body.Addons {
 		addonOrders = append(addonOrders, models.AddonOrder{
-			Addon:    models.Addon{ID: addon.ID},
+			AddonID:  addon.ID,
 			Quantity: addon.Quantity,
 		})
 	}
@@ -191,7 +192,11 @@ func (f *OrderController) UpdateOrder(c *gin.Context) {
 	order.SpecialNotes = body.SpecialNotes
 
 	// Call the UpdateOrder service
-	updatedOrder, err := f.OrderService.UpdateOrder(&order)
+	updatedOrder, err := f.OrderService.UpdateOrder(c.Request.Context(), order)
+	if errors.Is(err, services.ErrOrderStatusChange) || errors.Is(err, services.ErrOrderItemsLocked) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update order", err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update order", err.Error())
 		return
@@ -200,6 +205,31 @@ func (f *OrderController) UpdateOrder(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Order updated successfully", updatedOrder)
 }
 
+// UpdateOrderStatus handles moving an order to a new status
+func (f *OrderController) UpdateOrderStatus(c *gin.Context) {
+	orderId, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+	var body struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	// Call the UpdateOrderStatus service
+	updatedOrder, err := f.OrderService.UpdateOrderStatus(c.Request.Context(), orderId, body.Status)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update order status", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order status updated successfully", updatedOrder)
+}
+
 // DeleteOrder handles the deletion of a order item
 func (f *OrderController) DeleteOrder(c *gin.Context) {
 	orderId, valid := utils.ValidateID(c, "id")
@@ -208,8 +238,12 @@ func (f *OrderController) DeleteOrder(c *gin.Context) {
 	}
 
 	// Call the DeleteOrder service
-	err := f.OrderService.DeleteOrder(orderId)
+	err := f.OrderService.DeleteOrder(c.Request.Context(), orderId)
 	// Handle error
+	if errors.Is(err, services.ErrOrderNotDeletable) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete order", err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete order", err.Error())
 		return
@@ -226,7 +260,7 @@ func (f *OrderController) GetOrder(c *gin.Context) {
 	}
 
 	// Call the GetOrder service
-	order, err := f.OrderService.GetOrder(orderId)
+	order, err := f.OrderService.GetOrder(c.Request.Context(), orderId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve order", err.Error())
@@ -236,10 +270,26 @@ func (f *OrderController) GetOrder(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Order retrieved successfully", order)
 }
 
+// orderListSpec is what the orders lists can be sorted and filtered by
+var orderListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "total_price": "total_price", "status": "status", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"status": utils.TextColumn("status"), "restaurant_id": utils.IDColumn("restaurant_id"), "user_id": utils.IDColumn("user_id"), "table_id": utils.IDColumn("table_id")},
+	DefaultSort: "-created_at",
+}
+
+// orderFeedSpec pages the orders of a restaurant or a user by cursor on (created_at, id)
+var orderFeedSpec = utils.ListSpec{Sorts: orderListSpec.Sorts, Filters: orderListSpec.Filters, DefaultSort: "-created_at", Keyset: true}
+
 // GetAllOrders retrieves all order items
 func (f *OrderController) GetAllOrders(c *gin.Context) {
+	query, err := utils.ParseListQuery(c, orderListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the GetAllOrders service
-	orders, err := f.OrderService.GetAllOrders()
+	orders, pagination, err := f.OrderService.GetAllOrders(c.Request.Context(), query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
@@ -247,7 +297,7 @@ func (f *OrderController) GetAllOrders(c *gin.Context) {
 	}
 
 	// Return the list of orders
-	utils.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", orders)
+	utils.PaginatedResponse(c, http.StatusOK, "Orders retrieved successfully", orders, pagination)
 }
 
 // GetRestaurantOrders retrieves all the orders of a particular restaurant
@@ -257,8 +307,14 @@ func (f *OrderController) GetRestaurantOrders(c *gin.Context) {
 		return
 	}
 
-	// Call the GetRestaurantOrders service
-	orders, err := f.OrderService.GetRestaurantOrders(restaurantId)
+	query, err := utils.ParseListQuery(c, orderFeedSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	// Call the GetAllOrders service
+	orders, pagination, err := f.OrderService.GetAllOrders(c.Request.Context(), query.Where("restaurant_id", restaurantId))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
@@ -266,7 +322,7 @@ func (f *OrderController) GetRestaurantOrders(c *gin.Context) {
 	}
 
 	// Return the list of orders
-	utils.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", orders)
+	utils.PaginatedResponse(c, http.StatusOK, "Orders retrieved successfully", orders, pagination)
 }
 
 // SearchOrder
@@ -275,7 +331,7 @@ func (f *OrderController) SearchOrder(c *gin.Context) {
 	query := c.Query("q")
 
 	// Call the SearchOrder service
-	orders, err := f.OrderService.SearchOrders(query)
+	orders, err := f.OrderService.SearchOrders(c.Request.Context(), query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search for orders", err.Error())
@@ -293,8 +349,14 @@ func (f *OrderController) GetUserOrders(c *gin.Context) {
 		return
 	}
 
-	// Call the GetUserOrders service
-	orders, err := f.OrderService.GetUserOrders(userId)
+	query, err := utils.ParseListQuery(c, orderFeedSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	// Call the GetAllOrders service
+	orders, pagination, err := f.OrderService.GetAllOrders(c.Request.Context(), query.Where("user_id", userId))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
@@ -302,15 +364,20 @@ func (f *OrderController) GetUserOrders(c *gin.Context) {
 	}
 
 	// Return the list of orders
-	utils.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", orders)
+	utils.PaginatedResponse(c, http.StatusOK, "Orders retrieved successfully", orders, pagination)
 }
 
 // GetOrdersByStatus retrieves all the orders of a particular status
 func (f *OrderController) GetOrdersByStatus(c *gin.Context) {
 	status := c.Query("status")
+	query, err := utils.ParseListQuery(c, orderListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
 
-	// Call the GetOrdersByStatus service
-	orders, err := f.OrderService.GetOrdersByStatus(status)
+	// Call the GetAllOrders service
+	orders, pagination, err := f.OrderService.GetAllOrders(c.Request.Context(), query.Where("status", status))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve orders", err.Error())
@@ -318,5 +385,5 @@ func (f *OrderController) GetOrdersByStatus(c *gin.Context) {
 	}
 
 	// Return the list of orders
-	utils.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", orders)
+	utils.PaginatedResponse(c, http.StatusOK, "Orders retrieved successfully", orders, pagination)
 }
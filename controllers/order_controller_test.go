@@ -0,0 +1,319 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"madang_api/models"
+	"madang_api/testutil"
+	"madang_api/tracing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type order struct {
+	ID           uint    `json:"id"`
+	UserID       uint    `json:"user_id"`
+	RestaurantID uint    `json:"restaurant_id"`
+	TotalPrice   float64 `json:"total_price"`
+	Status       string  `json:"status"`
+	SpecialNotes string  `json:"special_notes"`
+	FoodOrders   []struct {
+		FoodID   uint `json:"food_id"`
+		Quantity int  `json:"quantity"`
+	} `json:"food_orders"`
+}
+
+// placeOrder orders two of the fixture food and the fixture table as the customer
+func placeOrder(t *testing.T, h *testutil.Harness, token string) order {
+	t.Helper()
+	var placed order
+	h.Request(t, http.MethodPost, "/api/orders/", token, map[string]interface{}{
+		"user_id":       h.Fixtures.Customer.ID,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+		"table_id":      h.Fixtures.Table.ID,
+		"foods":         []map[string]interface{}{{"id": h.Fixtures.Food.ID, "quantity": 2}},
+		"tables":        []map[string]interface{}{{"table_id": h.Fixtures.Table.ID}},
+		"total_price":   h.Fixtures.Food.Price*2 + h.Fixtures.Table.Price,
+		"status":        "pending",
+	}).Expect(t, http.StatusCreated).Decode(t, &placed)
+	return placed
+}
+
+func TestPlaceAndManageOrder(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+	manager := h.LoginAs(t, "manager")
+
+	placed := placeOrder(t, h, customer)
+	if placed.ID == 0 || placed.Status != "pending" || len(placed.FoodOrders) != 1 || placed.FoodOrders[0].Quantity != 2 {
+		t.Fatalf("unexpected order %+v", placed)
+	}
+	path := fmt.Sprintf("/api/orders/%d", placed.ID)
+
+	// only the staff of the restaurant moves the order along
+	h.Request(t, http.MethodPut, path+"/status", customer, map[string]string{"status": "confirmed"}).Expect(t, http.StatusForbidden)
+
+	var confirmed order
+	h.Request(t, http.MethodPut, path+"/status", manager, map[string]string{"status": "confirmed"}).
+		Expect(t, http.StatusOK).Decode(t, &confirmed)
+	if confirmed.Status != "confirmed" {
+		t.Fatalf("expected the order to be confirmed, got %s", confirmed.Status)
+	}
+
+	// a confirmed order is completed or cancelled, never sent back or moved to a made up status
+	h.Request(t, http.MethodPut, path+"/status", manager, map[string]string{"status": "pending"}).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPut, path+"/status", manager, map[string]string{"status": "eaten"}).Expect(t, http.StatusBadRequest)
+
+	// the lists across every restaurant are for admins
+	var orders []order
+	h.Request(t, http.MethodGet, "/api/orders/status?status=confirmed", customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, "/api/orders/status?status=confirmed", manager, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, "/api/orders/status?status=confirmed", h.LoginAs(t, "admin"), nil).
+		Expect(t, http.StatusOK).Decode(t, &orders)
+	if len(orders) != 1 || orders[0].ID != placed.ID {
+		t.Fatalf("expected the confirmed order, got %+v", orders)
+	}
+
+	// a confirmed order holds deducted stock, it is cancelled before it can be deleted
+	h.Request(t, http.MethodDelete, path, customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodDelete, path, manager, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPut, path+"/status", manager, map[string]string{"status": "cancelled"}).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, path, manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, customer, nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestUpdateOrder(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	placed := placeOrder(t, h, h.LoginAs(t, "customer"))
+	other := h.AddUser(t, "other@madang.test", "customer")
+
+	// the customer and the restaurant of the order stay whatever the body says
+	var updated order
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/orders/%d", placed.ID), manager, map[string]interface{}{
+		"user_id":       other.ID,
+		"restaurant_id": h.Fixtures.Restaurant.ID + 1,
+		"table_id":      h.Fixtures.Table.ID,
+		"total_price":   placed.TotalPrice,
+		"status":        "pending",
+		"special_notes": "No onions",
+	}).Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.ID != placed.ID || updated.SpecialNotes != "No onions" {
+		t.Fatalf("unexpected order after the update %+v", updated)
+	}
+	if updated.UserID != h.Fixtures.Customer.ID || updated.RestaurantID != h.Fixtures.Restaurant.ID {
+		t.Fatalf("the update must keep the customer and the restaurant, got %+v", updated)
+	}
+
+	var orders []order
+	h.Request(t, http.MethodGet, "/api/orders/", manager, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, "/api/orders/", h.LoginAs(t, "admin"), nil).Expect(t, http.StatusOK).Decode(t, &orders)
+	if len(orders) != 1 {
+		t.Fatalf("the update must not add an order, got %d orders", len(orders))
+	}
+
+	// the status only moves through the status endpoint, which also moves the stock
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/orders/%d", placed.ID), manager, map[string]interface{}{
+		"table_id":    h.Fixtures.Table.ID,
+		"total_price": placed.TotalPrice,
+		"status":      "confirmed",
+	}).Expect(t, http.StatusBadRequest)
+}
+
+func TestConfirmedOrderItemsAreLocked(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	placed := placeOrder(t, h, h.LoginAs(t, "customer"))
+	path := fmt.Sprintf("/api/orders/%d", placed.ID)
+
+	rice := models.Ingredient{Name: "Rice", Unit: "g", Quantity: 1000, RestaurantID: h.Fixtures.Restaurant.ID}
+	if err := h.DB.Create(&rice).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.Create(&models.FoodIngredient{FoodID: h.Fixtures.Food.ID, IngredientID: rice.ID, Quantity: 100}).Error; err != nil {
+		t.Fatal(err)
+	}
+	edit := func(quantity int) map[string]interface{} {
+		return map[string]interface{}{
+			"table_id":    h.Fixtures.Table.ID,
+			"foods":       []map[string]interface{}{{"id": h.Fixtures.Food.ID, "quantity": quantity}},
+			"tables":      []map[string]interface{}{{"table_id": h.Fixtures.Table.ID}},
+			"total_price": placed.TotalPrice,
+			"status":      "confirmed",
+		}
+	}
+
+	h.Request(t, http.MethodPut, path+"/status", manager, map[string]string{"status": "confirmed"}).Expect(t, http.StatusOK)
+	assertIngredientQuantity(t, h, rice.ID, 800)
+
+	// the two portions deducted are the ones restored by the cancellation
+	h.Request(t, http.MethodPut, path, manager, edit(5)).Expect(t, http.StatusBadRequest)
+	var unchanged order
+	h.Request(t, http.MethodPut, path, manager, edit(2)).Expect(t, http.StatusOK).Decode(t, &unchanged)
+	if len(unchanged.FoodOrders) != 1 || unchanged.FoodOrders[0].Quantity != 2 {
+		t.Fatalf("expected the items to stay, got %+v", unchanged.FoodOrders)
+	}
+	h.Request(t, http.MethodPut, path+"/status", manager, map[string]string{"status": "cancelled"}).Expect(t, http.StatusOK)
+	assertIngredientQuantity(t, h, rice.ID, 1000)
+}
+
+func TestOrderRefusesItemsOfAnotherRestaurant(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+
+	other := models.Restaurant{Name: "Other", Address: "2 Test Street", UserID: h.AddUser(t, "other@madang.test", "manager").ID, Timezone: "UTC", Active: true, Verified: true}
+	if err := h.DB.Create(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	addon := models.Addon{Name: "Balloons", Type: "decoration", Price: 2, RestaurantID: other.ID}
+	if err := h.DB.Create(&addon).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	h.Request(t, http.MethodPost, "/api/orders/", customer, map[string]interface{}{
+		"user_id":       h.Fixtures.Customer.ID,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+		"addons":        []map[string]interface{}{{"id": addon.ID, "quantity": 1}},
+		"status":        "pending",
+	}).Expect(t, http.StatusInternalServerError)
+
+	// nor can a pending order be edited into one
+	placed := placeOrder(t, h, customer)
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/orders/%d", placed.ID), h.LoginAs(t, "manager"), map[string]interface{}{
+		"user_id":       h.Fixtures.Customer.ID,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+		"table_id":      h.Fixtures.Table.ID,
+		"addons":        []map[string]interface{}{{"id": addon.ID, "quantity": 1}},
+		"status":        "pending",
+	}).Expect(t, http.StatusInternalServerError)
+}
+
+func assertIngredientQuantity(t *testing.T, h *testutil.Harness, id uint, quantity float64) {
+	t.Helper()
+	var ingredient models.Ingredient
+	if err := h.DB.First(&ingredient, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ingredient.Quantity != quantity {
+		t.Fatalf("expected %v %s of %s in stock, got %v", quantity, ingredient.Unit, ingredient.Name, ingredient.Quantity)
+	}
+}
+
+func TestOrderRefusesUnavailableFood(t *testing.T) {
+	h := testutil.New(t)
+	if err := h.DB.Model(&h.Fixtures.Food).Update("available", false).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	h.Request(t, http.MethodPost, "/api/orders/", h.LoginAs(t, "customer"), map[string]interface{}{
+		"user_id":       h.Fixtures.Customer.ID,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+		"foods":         []map[string]interface{}{{"id": h.Fixtures.Food.ID, "quantity": 1}},
+		"status":        "pending",
+	}).Expect(t, http.StatusInternalServerError)
+}
+
+func TestOrderFeeds(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+	manager := h.LoginAs(t, "manager")
+	placed := placeOrder(t, h, customer)
+
+	var orders []order
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/orders/user/%d", h.Fixtures.Customer.ID), customer, nil).
+		Expect(t, http.StatusOK).Decode(t, &orders)
+	if len(orders) != 1 || orders[0].ID != placed.ID {
+		t.Fatalf("expected the order of the customer, got %+v", orders)
+	}
+
+	// another customer sees neither the orders nor the order of the customer
+	other := h.AddUser(t, "other@madang.test", "customer")
+	otherToken := h.Login(t, other.Email)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/orders/user/%d", h.Fixtures.Customer.ID), otherToken, nil).
+		Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/orders/%d", placed.ID), otherToken, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/orders/%d", placed.ID), customer, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/orders/%d", placed.ID), manager, nil).Expect(t, http.StatusOK)
+
+	restaurantOrders := fmt.Sprintf("/api/orders/restaurant/%d", h.Fixtures.Restaurant.ID)
+	h.Request(t, http.MethodGet, restaurantOrders, customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, restaurantOrders, manager, nil).Expect(t, http.StatusOK).Decode(t, &orders)
+	if len(orders) != 1 || orders[0].ID != placed.ID {
+		t.Fatalf("expected the order of the restaurant, got %+v", orders)
+	}
+
+	h.Request(t, http.MethodGet, restaurantOrders+"?cursor=forged", manager, nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestOrderRefusedWhileRestaurantClosed(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+
+	// the only opening is in three days, so the restaurant is closed now
+	day := (int(time.Now().UTC().Weekday()) + 3) % 7
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/restaurants/%d/hours/", h.Fixtures.Restaurant.ID), h.LoginAs(t, "manager"), map[string]interface{}{
+		"timezone":  "UTC",
+		"intervals": []map[string]interface{}{{"day_of_week": day, "opens_at": "09:00", "closes_at": "10:00"}},
+	}).Expect(t, http.StatusOK)
+
+	h.Request(t, http.MethodPost, "/api/orders/", customer, map[string]interface{}{
+		"user_id": h.Fixtures.Customer.ID, "restaurant_id": h.Fixtures.Restaurant.ID, "status": "pending",
+	}).Expect(t, http.StatusInternalServerError)
+}
+
+func TestOrderTracing(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+
+	if _, err := tracing.Setup(context.Background(), "none", ""); err != nil {
+		t.Fatalf("setting up tracing: %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	placed := placeOrder(t, h, customer)
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	server, service := spans["POST /api/orders/"], spans["OrderService.AddOrder"]
+	if server == nil || service == nil || spans["INSERT food_orders"] == nil {
+		t.Fatalf("expected the spans of the request, the service and the queries, got %v", spanNames(recorder.Ended()))
+	}
+	if service.Parent().SpanID() != server.SpanContext().SpanID() || spans["INSERT food_orders"].SpanContext().TraceID() != server.SpanContext().TraceID() {
+		t.Fatal("expected the service and query spans in the trace of the request")
+	}
+
+	// the trace of the caller is continued
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/orders/%d", placed.ID), nil)
+	request.Header.Set("Authorization", "Bearer "+customer)
+	request.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	h.Do(t, request).Expect(t, http.StatusOK)
+	for _, span := range recorder.Ended() {
+		if span.Name() == "GET /api/orders/:id" {
+			if span.SpanContext().TraceID().String() != traceID {
+				t.Fatalf("expected the request span in the trace %s, got %s", traceID, span.SpanContext().TraceID())
+			}
+			return
+		}
+	}
+	t.Fatalf("no span for the request, got %v", spanNames(recorder.Ended()))
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	return names
+}
@@ -10,7 +10,7 @@ import (
 )
 
 type PaymentController struct {
-	PaymentService services.PaymentService
+	PaymentService *services.PaymentService
 }
 
 type PaymentControllerInterface interface {
@@ -22,8 +22,24 @@ type PaymentControllerInterface interface {
 	GetRestaurantPayments(c *gin.Context)
 }
 
+// paymentListSpec is what the payments lists can be sorted and filtered by
+var paymentListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "amount": "amount", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"status": utils.TextColumn("status"), "method": utils.TextColumn("method"), "restaurant_id": utils.IDColumn("restaurant_id"), "order_id": utils.IDColumn("order_id")},
+	DefaultSort: "-created_at",
+}
+
+// paymentFeedSpec pages the payments of a restaurant by cursor on (created_at, id)
+var paymentFeedSpec = utils.ListSpec{Sorts: paymentListSpec.Sorts, Filters: paymentListSpec.Filters, DefaultSort: "-created_at", Keyset: true}
+
 func (ctrl *PaymentController) GetAllPayments(c *gin.Context) {
-	payments, err := ctrl.PaymentService.GetPayments()
+	query, err := utils.ParseListQuery(c, paymentListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	payments, pagination, err := ctrl.PaymentService.GetPayments(c.Request.Context(), query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve payments", err.Error())
@@ -31,7 +47,7 @@ func (ctrl *PaymentController) GetAllPayments(c *gin.Context) {
 	}
 
 	// Return the list of payments
-	utils.SuccessResponse(c, http.StatusOK, "Payments retrieved successfully", payments)
+	utils.PaginatedResponse(c, http.StatusOK, "Payments retrieved successfully", payments, pagination)
 }
 
 func (ctrl *PaymentController) GetPayment(c *gin.Context) {
@@ -42,7 +58,7 @@ func (ctrl *PaymentController) GetPayment(c *gin.Context) {
 	}
 
 	// Call the service to retrieve the payment
-	payment, err := ctrl.PaymentService.GetPayment(paymentID)
+	payment, err := ctrl.PaymentService.GetPayment(c.Request.Context(), paymentID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve payment", err.Error())
 		return
@@ -80,7 +96,7 @@ func (ctrl *PaymentController) CreatePayment(c *gin.Context) {
 	payment.RestaurantID = body.RestaurantID
 
 	// Call the service to create the payment
-	newPayment, err := ctrl.PaymentService.CreatePayment(&payment)
+	newPayment, err := ctrl.PaymentService.CreatePayment(c.Request.Context(), &payment)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create payment", err.Error())
 		return
@@ -112,13 +128,12 @@ func (ctrl *PaymentController) UpdatePayment(c *gin.Context) {
 		return
 	}
 	//check fig the payment exists
-	_, err := ctrl.PaymentService.GetPayment(paymentID)
+	payment, err := ctrl.PaymentService.GetPayment(c.Request.Context(), paymentID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Payment not found", err.Error())
 		return
 	}
 
-	var payment models.Payment
 	payment.OrderID = body.OrderID
 	payment.Method = body.Method
 	payment.Status = body.Status
@@ -126,7 +141,7 @@ func (ctrl *PaymentController) UpdatePayment(c *gin.Context) {
 	payment.RestaurantID = body.RestaurantID
 
 	// Call the service to update the payment
-	updatedPayment, err := ctrl.PaymentService.UpdatePayment(&payment)
+	updatedPayment, err := ctrl.PaymentService.UpdatePayment(c.Request.Context(), &payment)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update payment", err.Error())
@@ -145,7 +160,7 @@ func (ctrl *PaymentController) DeletePayment(c *gin.Context) {
 	}
 
 	//check if the payment exists
-	_, errExist := ctrl.PaymentService.GetPayment(paymentID)
+	_, errExist := ctrl.PaymentService.GetPayment(c.Request.Context(), paymentID)
 	// Handle error
 	if errExist != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Payment not found", errExist.Error())
@@ -153,7 +168,7 @@ func (ctrl *PaymentController) DeletePayment(c *gin.Context) {
 	}
 
 	// Call the service to delete the payment
-	err := ctrl.PaymentService.DeletePayment(paymentID)
+	err := ctrl.PaymentService.DeletePayment(c.Request.Context(), paymentID)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete payment", err.Error())
@@ -171,8 +186,14 @@ func (ctrl *PaymentController) GetRestaurantPayments(c *gin.Context) {
 		return
 	}
 
+	query, err := utils.ParseListQuery(c, paymentFeedSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the service to retrieve the payments for the restaurant
-	payments, err := ctrl.PaymentService.GetRestaurantPayments(restaurantID)
+	payments, pagination, err := ctrl.PaymentService.GetPayments(c.Request.Context(), query.Where("restaurant_id", restaurantID))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve payments", err.Error())
@@ -180,5 +201,5 @@ func (ctrl *PaymentController) GetRestaurantPayments(c *gin.Context) {
 	}
 
 	// Return the list of payments
-	utils.SuccessResponse(c, http.StatusOK, "Payments retrieved successfully", payments)
+	utils.PaginatedResponse(c, http.StatusOK, "Payments retrieved successfully", payments, pagination)
 }
@@ -0,0 +1,62 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type payment struct {
+	ID      uint    `json:"id"`
+	OrderID uint    `json:"order_id"`
+	Amount  float64 `json:"amount"`
+	Method  string  `json:"method"`
+	Status  string  `json:"status"`
+}
+
+func TestPaymentLifecycle(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+	placed := placeOrder(t, h, token)
+
+	var created payment
+	h.Request(t, http.MethodPost, "/api/payments/", token, map[string]interface{}{
+		"order_id": placed.ID, "method": "credit_card", "status": "pending", "amount": placed.TotalPrice,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &created)
+	path := fmt.Sprintf("/api/payments/%d", created.ID)
+
+	var updated payment
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{
+		"order_id": placed.ID, "method": "credit_card", "status": "completed", "amount": placed.TotalPrice,
+		"restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.ID != created.ID || updated.Status != "completed" {
+		t.Fatalf("unexpected payment after the update %+v", updated)
+	}
+
+	var payments []payment
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/payments/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &payments)
+	if len(payments) != 1 || payments[0].Status != "completed" {
+		t.Fatalf("expected the single updated payment, got %+v", payments)
+	}
+
+	h.Request(t, http.MethodGet, "/api/payments/?status=pending", token, nil).Expect(t, http.StatusOK).Decode(t, &payments)
+	if len(payments) != 0 {
+		t.Fatalf("expected no pending payment, got %+v", payments)
+	}
+
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusNotFound)
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusInternalServerError)
+}
+
+func TestPaymentsRequireToken(t *testing.T) {
+	h := testutil.New(t)
+
+	h.Request(t, http.MethodGet, "/api/payments/", "", nil).Expect(t, http.StatusUnauthorized)
+	h.Request(t, http.MethodPost, "/api/payments/", "", map[string]interface{}{"amount": 1}).Expect(t, http.StatusUnauthorized)
+}
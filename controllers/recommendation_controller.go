@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationController struct {
+	RecommendationService *services.RecommendationService
+}
+
+// RecommendationControllerInterface defines the methods for managing recommendations
+type RecommendationControllerInterface interface {
+	RefreshRecommendations(c *gin.Context)
+}
+
+// RefreshRecommendations recomputes the recommendations now instead of waiting for the background job
+func (ctrl *RecommendationController) RefreshRecommendations(c *gin.Context) {
+	if err := ctrl.RecommendationService.RefreshRecommendations(c.Request.Context()); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to refresh recommendations", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Recommendations refreshed successfully", nil)
+}
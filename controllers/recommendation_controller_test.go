@@ -0,0 +1,18 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+func TestRefreshRecommendations(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+	admin := h.LoginAs(t, "admin")
+	placeOrder(t, h, customer)
+
+	h.Request(t, http.MethodPost, "/api/recommendations/refresh", customer, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPost, "/api/recommendations/refresh", admin, nil).Expect(t, http.StatusOK)
+}
@@ -5,12 +5,13 @@ import (
 	"madang_api/services"
 	"madang_api/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type RestaurantController struct {
-	RestaurantService services.RestaurantService
+	RestaurantService *services.RestaurantService
 }
 
 type RestaurantControllerInterface interface {
@@ -23,15 +24,19 @@ type RestaurantControllerInterface interface {
 	FilterRestaurant(c *gin.Context)
 	GetAllVerifiedRestaurants(c *gin.Context)
 	GetUserRestaurants(c *gin.Context)
+	GetNearbyRestaurants(c *gin.Context)
+	TransferRestaurant(c *gin.Context)
 }
 
 // Add a new restaurant
 func (ctrl *RestaurantController) CreateRestaurant(c *gin.Context) {
 	var body struct {
-		Name     string `json:"name"`
-		Address  string `json:"address"`
-		Location string `json:"location"`
-		UserID   uint   `json:"user_id"`
+		Name      string   `json:"name"`
+		Address   string   `json:"address"`
+		Location  string   `json:"location"`
+		UserID    uint     `json:"user_id"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -48,6 +53,8 @@ func (ctrl *RestaurantController) CreateRestaurant(c *gin.Context) {
 	restaurant.Address = body.Address
 	restaurant.Location = body.Location
 	restaurant.UserID = body.UserID
+	restaurant.Latitude = body.Latitude
+	restaurant.Longitude = body.Longitude
 	result, err := ctrl.RestaurantService.AddRestaurant(&restaurant)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to register restaurant", err.Error())
@@ -57,15 +64,28 @@ func (ctrl *RestaurantController) CreateRestaurant(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Restaurant created successfully", result)
 }
 
+// restaurantListSpec is what the restaurants lists can be sorted and filtered by
+var restaurantListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "average_rating": "average_rating", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"verified": utils.BoolColumn("verified"), "active": utils.BoolColumn("active"), "state": utils.TextColumn("state"), "country": utils.TextColumn("country"), "brand_id": utils.IDColumn("brand_id"), "verification_status": utils.TextColumn("verification_status")},
+	DefaultSort: "id",
+}
+
 // Get all restaurants
 func (ctrl *RestaurantController) GetAllRestaurant(c *gin.Context) {
-	restaurants, err := ctrl.RestaurantService.GetAllRestaurants()
+	query, err := utils.ParseListQuery(c, restaurantListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	restaurants, pagination, err := ctrl.RestaurantService.GetAllRestaurants(query, c.Query("open_now") == "true")
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch restaurants", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Restaurants fetched successfully", restaurants)
+	utils.PaginatedResponse(c, http.StatusOK, "Restaurants fetched successfully", restaurants, pagination)
 }
 
 // Get a single restaurant
@@ -74,7 +94,7 @@ func (ctrl *RestaurantController) GetRestaurant(c *gin.Context) {
 	if !valid {
 		return
 	}
-	restaurant, err := ctrl.RestaurantService.GetRestaurantByID(restaurantID)
+	restaurant, err := ctrl.RestaurantService.GetRestaurantByID(c.Request.Context(), restaurantID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch restaurant", err.Error())
 		return
@@ -91,16 +111,15 @@ func (ctrl *RestaurantController) UpdateRestaurant(c *gin.Context) {
 	}
 
 	var body struct {
-		Name      *string `json:"name"`
-		Address   *string `json:"address"`
-		Location  *string `json:"location"`
-		Active    *bool   `json:"active"`
-		Verified  *bool   `json:"verified"`
-		VerfiedAt *string `json:"verified_at"`
-		State     *string `json:"state"`
-		Country   *string `json:"country"`
-		Phone     *string `json:"phone"`
-		UserID    *uint   `json:"user_id"`
+		Name      *string  `json:"name"`
+		Address   *string  `json:"address"`
+		Location  *string  `json:"location"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+		Timezone  *string  `json:"timezone"`
+		State     *string  `json:"state"`
+		Country   *string  `json:"country"`
+		Phone     *string  `json:"phone"`
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -109,7 +128,7 @@ func (ctrl *RestaurantController) UpdateRestaurant(c *gin.Context) {
 	}
 
 	// Fetch existing restaurant
-	existingRestaurant, err := ctrl.RestaurantService.GetRestaurantByID(restaurantID)
+	existingRestaurant, err := ctrl.RestaurantService.GetRestaurantByID(c.Request.Context(), restaurantID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Restaurant not found", err.Error())
 		return
@@ -125,14 +144,14 @@ func (ctrl *RestaurantController) UpdateRestaurant(c *gin.Context) {
 	if body.Location != nil {
 		existingRestaurant.Location = *body.Location
 	}
-	if body.Active != nil {
-		existingRestaurant.Active = *body.Active
+	if body.Latitude != nil {
+		existingRestaurant.Latitude = body.Latitude
 	}
-	if body.Verified != nil {
-		existingRestaurant.Verified = *body.Verified
+	if body.Longitude != nil {
+		existingRestaurant.Longitude = body.Longitude
 	}
-	if body.VerfiedAt != nil {
-		existingRestaurant.VerfiedAt = *body.VerfiedAt
+	if body.Timezone != nil {
+		existingRestaurant.Timezone = *body.Timezone
 	}
 	if body.State != nil {
 		existingRestaurant.State = *body.State
@@ -143,9 +162,6 @@ func (ctrl *RestaurantController) UpdateRestaurant(c *gin.Context) {
 	if body.Phone != nil {
 		existingRestaurant.Phone = *body.Phone
 	}
-	if body.UserID != nil {
-		existingRestaurant.UserID = *body.UserID
-	}
 
 	updatedRestaurant, err := ctrl.RestaurantService.UpdateRestaurant(&existingRestaurant)
 	if err != nil {
@@ -172,10 +188,38 @@ func (ctrl *RestaurantController) DeleteRestaurant(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Restaurant deleted successfully", nil)
 }
 
+// Hand a restaurant over to another user, who becomes its owner
+func (ctrl *RestaurantController) TransferRestaurant(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	restaurant, err := ctrl.RestaurantService.TransferRestaurant(restaurantID, body.UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to transfer restaurant", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Restaurant transferred successfully", restaurant)
+}
+
 // Search for restaurants
 func (ctrl *RestaurantController) SearchRestaurant(c *gin.Context) {
 	query := c.Query("q")
-	restaurants, err := ctrl.RestaurantService.SearchRestaurants(query)
+	restaurantID, valid := utils.OptionalQueryID(c, "restaurant_id")
+	if !valid {
+		return
+	}
+	restaurants, err := ctrl.RestaurantService.SearchRestaurants(query, restaurantID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search restaurants", err.Error())
 		return
@@ -213,13 +257,19 @@ func (ctrl *RestaurantController) FilterRestaurant(c *gin.Context) {
 
 // Get all verified restaurants
 func (ctrl *RestaurantController) GetAllVerifiedRestaurants(c *gin.Context) {
-	restaurants, err := ctrl.RestaurantService.GetAllVerifiedRestaurants()
+	query, err := utils.ParseListQuery(c, restaurantListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	restaurants, pagination, err := ctrl.RestaurantService.GetAllVerifiedRestaurants(query, c.Query("open_now") == "true")
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch verified restaurants", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Verified restaurants fetched successfully", restaurants)
+	utils.PaginatedResponse(c, http.StatusOK, "Verified restaurants fetched successfully", restaurants, pagination)
 }
 
 // Get user restaurants
@@ -229,11 +279,44 @@ func (ctrl *RestaurantController) GetUserRestaurants(c *gin.Context) {
 		return
 	}
 
-	restaurants, err := ctrl.RestaurantService.GetUserRestaurants(userID)
+	query, err := utils.ParseListQuery(c, restaurantListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	restaurants, pagination, err := ctrl.RestaurantService.GetUserRestaurants(userID, query)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch user restaurants", err.Error())
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "User restaurants fetched successfully", restaurants)
+	utils.PaginatedResponse(c, http.StatusOK, "User restaurants fetched successfully", restaurants, pagination)
+}
+
+// Get restaurants near a point, closest first
+func (ctrl *RestaurantController) GetNearbyRestaurants(c *gin.Context) {
+	latitude, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lat parameter", err.Error())
+		return
+	}
+	longitude, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid lng parameter", err.Error())
+		return
+	}
+	radiusKm, err := strconv.ParseFloat(c.DefaultQuery("radius_km", "10"), 64)
+	if err != nil || radiusKm <= 0 || radiusKm > 100 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid radius_km parameter", "radius_km must be a number between 0 and 100")
+		return
+	}
+
+	restaurants, err := ctrl.RestaurantService.GetNearbyRestaurants(c.Request.Context(), latitude, longitude, radiusKm, c.Query("open_now") == "true")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to fetch nearby restaurants", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Nearby restaurants fetched successfully", restaurants)
 }
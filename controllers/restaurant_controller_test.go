@@ -0,0 +1,164 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/models"
+	"madang_api/testutil"
+)
+
+func TestCreateRestaurant(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+
+	var restaurant struct {
+		ID     uint   `json:"id"`
+		Name   string `json:"name"`
+		UserID uint   `json:"user_id"`
+	}
+	h.Request(t, http.MethodPost, "/api/restaurants/", token, map[string]interface{}{
+		"name": "Second Branch", "address": "2 Test Street", "location": "Busan", "user_id": h.Fixtures.Manager.ID,
+		"latitude": 35.1796, "longitude": 129.0756,
+	}).Expect(t, http.StatusOK).Decode(t, &restaurant)
+	if restaurant.ID == 0 || restaurant.UserID != h.Fixtures.Manager.ID {
+		t.Fatalf("unexpected restaurant %+v", restaurant)
+	}
+
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/restaurants/%d", restaurant.ID), token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, fmt.Sprintf("/api/restaurants/%d", restaurant.ID), token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/restaurants/%d", restaurant.ID), token, nil).Expect(t, http.StatusInternalServerError)
+}
+
+func TestCreateRestaurantRequiresManager(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	h.Request(t, http.MethodPost, "/api/restaurants/", token, map[string]interface{}{
+		"name": "Not Mine", "address": "3 Test Street", "user_id": h.Fixtures.Customer.ID,
+	}).Expect(t, http.StatusBadRequest)
+
+	h.Request(t, http.MethodPost, "/api/restaurants/", token, map[string]interface{}{
+		"name": "Out Of Range", "user_id": h.Fixtures.Manager.ID, "latitude": 123.0, "longitude": 0.0,
+	}).Expect(t, http.StatusBadRequest)
+}
+
+func TestGetAndUpdateRestaurant(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+	path := fmt.Sprintf("/api/restaurants/%d", h.Fixtures.Restaurant.ID)
+
+	var restaurant struct {
+		Name    string `json:"name"`
+		OpenNow bool   `json:"open_now"`
+		Phone   string `json:"phone"`
+	}
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &restaurant)
+	if restaurant.Name != h.Fixtures.Restaurant.Name || !restaurant.OpenNow {
+		t.Fatalf("unexpected restaurant %+v", restaurant)
+	}
+
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"phone": "010-1234-5678"}).
+		Expect(t, http.StatusOK).Decode(t, &restaurant)
+	if restaurant.Phone != "010-1234-5678" || restaurant.Name != h.Fixtures.Restaurant.Name {
+		t.Fatalf("unexpected restaurant after the update %+v", restaurant)
+	}
+
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"timezone": "Mars/Olympus"}).Expect(t, http.StatusInternalServerError)
+	h.Request(t, http.MethodPut, "/api/restaurants/999", token, map[string]interface{}{"phone": "0"}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPut, "/api/restaurants/999", h.LoginAs(t, "admin"), map[string]interface{}{"phone": "0"}).Expect(t, http.StatusNotFound)
+}
+
+func TestRestaurantChangesRequireOwnership(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+	path := fmt.Sprintf("/api/restaurants/%d", h.Fixtures.Restaurant.ID)
+
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"phone": "0"}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPost, path+"/transfer", token, map[string]interface{}{"user_id": h.Fixtures.Customer.ID}).Expect(t, http.StatusForbidden)
+
+	// the owner can no longer hand the restaurant over through an update
+	owner := h.LoginAs(t, "manager")
+	var restaurant struct {
+		UserID uint `json:"user_id"`
+	}
+	h.Request(t, http.MethodPut, path, owner, map[string]interface{}{"user_id": h.Fixtures.Customer.ID}).
+		Expect(t, http.StatusOK).Decode(t, &restaurant)
+	if restaurant.UserID != h.Fixtures.Manager.ID {
+		t.Fatalf("the update changed the owner to %d", restaurant.UserID)
+	}
+
+	// only to another verified manager
+	h.Request(t, http.MethodPost, path+"/transfer", owner, map[string]interface{}{"user_id": h.Fixtures.Customer.ID}).Expect(t, http.StatusBadRequest)
+
+	other := h.AddUser(t, "other@madang.test", "manager")
+	h.Request(t, http.MethodPost, path+"/transfer", owner, map[string]interface{}{"user_id": other.ID}).
+		Expect(t, http.StatusOK).Decode(t, &restaurant)
+	if restaurant.UserID != other.ID {
+		t.Fatalf("expected the restaurant to belong to %d, got %d", other.ID, restaurant.UserID)
+	}
+	h.Request(t, http.MethodDelete, path, owner, nil).Expect(t, http.StatusForbidden)
+}
+
+func TestListRestaurants(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	lists := []string{
+		"/api/restaurants/",
+		"/api/restaurants/verified",
+		"/api/restaurants/verified?open_now=true",
+		fmt.Sprintf("/api/restaurants/user/%d", h.Fixtures.Manager.ID),
+		"/api/restaurants/search?q=test",
+		"/api/restaurants/nearby?lat=37.56&lng=126.97&radius_km=5",
+	}
+	for _, path := range lists {
+		var restaurants []map[string]interface{}
+		h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &restaurants)
+		if len(restaurants) != 1 {
+			t.Errorf("%s: expected the fixture restaurant, got %d restaurants", path, len(restaurants))
+		}
+	}
+
+	h.Request(t, http.MethodGet, "/api/restaurants/nearby?lat=north&lng=126.97", token, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/restaurants/?sort=password", token, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/restaurants/?verified=maybe", token, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/restaurants/?brand_id=1,abc", token, nil).Expect(t, http.StatusBadRequest)
+}
+
+func TestNearbyRestaurants(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	// an unverified restaurant next to the fixture one and a verified one across the antimeridian
+	addRestaurant := func(name string, latitude float64, longitude float64, verified bool) {
+		t.Helper()
+		restaurant := models.Restaurant{
+			Name: name, Address: "2 Test Street", Location: "Test", State: "Test", Country: "KR", UserID: h.Fixtures.Manager.ID,
+			Latitude: &latitude, Longitude: &longitude, Timezone: "UTC", Active: true, Verified: verified,
+		}
+		if err := h.DB.Create(&restaurant).Error; err != nil {
+			t.Fatalf("creating %s: %v", name, err)
+		}
+	}
+	addRestaurant("Unverified", 37.5665, 126.9781, false)
+	addRestaurant("Fiji", -16.5, 179.99, true)
+
+	var restaurants []struct {
+		Name       string  `json:"name"`
+		DistanceKm float64 `json:"distance_km"`
+	}
+	h.Request(t, http.MethodGet, "/api/restaurants/nearby?lat=37.56&lng=126.97&radius_km=5", token, nil).
+		Expect(t, http.StatusOK).Decode(t, &restaurants)
+	if len(restaurants) != 1 || restaurants[0].Name != h.Fixtures.Restaurant.Name {
+		t.Fatalf("expected only the verified fixture restaurant, got %+v", restaurants)
+	}
+
+	h.Request(t, http.MethodGet, "/api/restaurants/nearby?lat=-16.5&lng=-179.99&radius_km=5", token, nil).
+		Expect(t, http.StatusOK).Decode(t, &restaurants)
+	if len(restaurants) != 1 || restaurants[0].Name != "Fiji" {
+		t.Fatalf("expected the restaurant across the antimeridian, got %+v", restaurants)
+	}
+}
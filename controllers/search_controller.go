@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	SearchService *services.SearchService
+}
+
+// SearchControllerInterface defines the methods for searching the whole catalog
+type SearchControllerInterface interface {
+	Search(c *gin.Context)
+}
+
+// Search handles GET /api/search?q=&restaurant_id=&types=foods,tables
+func (ctrl *SearchController) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Search query is missing", "q cannot be empty")
+		return
+	}
+
+	restaurantID, valid := utils.OptionalQueryID(c, "restaurant_id")
+	if !valid {
+		return
+	}
+
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, searchType := range strings.Split(raw, ",") {
+			searchType = strings.TrimSpace(searchType)
+			known := false
+			for _, t := range services.SearchTypes {
+				if t == searchType {
+					known = true
+				}
+			}
+			if !known {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid types parameter", "unknown type "+searchType)
+				return
+			}
+			types = append(types, searchType)
+		}
+	}
+
+	result, err := ctrl.SearchService.Search(c.Request.Context(), query, restaurantID, types)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Search results retrieved successfully", result)
+}
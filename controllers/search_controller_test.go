@@ -0,0 +1,49 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type globalSearch struct {
+	Total   int64            `json:"total"`
+	Facets  map[string]int64 `json:"facets"`
+	Results struct {
+		Restaurants []struct {
+			ID uint `json:"id"`
+		} `json:"restaurants"`
+		Foods []struct {
+			ID uint `json:"id"`
+		} `json:"foods"`
+	} `json:"results"`
+}
+
+func TestGlobalSearch(t *testing.T) {
+	h := testutil.New(t)
+	customer := h.LoginAs(t, "customer")
+
+	var result globalSearch
+	h.Request(t, http.MethodGet, "/api/search?q=bibim", customer, nil).Expect(t, http.StatusOK).Decode(t, &result)
+	if result.Facets["foods"] != 1 || len(result.Results.Foods) != 1 || result.Results.Foods[0].ID != h.Fixtures.Food.ID {
+		t.Fatalf("expected the fixture food, got %+v", result)
+	}
+
+	// the facets still count the types left out of the results
+	h.Request(t, http.MethodGet, "/api/search?q=test&types=foods", customer, nil).Expect(t, http.StatusOK).Decode(t, &result)
+	if result.Facets["restaurants"] != 1 || len(result.Results.Restaurants) != 0 {
+		t.Fatalf("expected only the restaurant facet, got %+v", result)
+	}
+
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/search?q=bibim&restaurant_id=%d", h.Fixtures.Restaurant.ID+1), customer, nil).
+		Expect(t, http.StatusOK).Decode(t, &result)
+	if result.Total != 0 {
+		t.Fatalf("expected no hits in another restaurant, got %+v", result)
+	}
+
+	h.Request(t, http.MethodGet, "/api/search?q=", customer, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/search?q=bibim&types=users", customer, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/search?q=bibim", "", nil).Expect(t, http.StatusUnauthorized)
+}
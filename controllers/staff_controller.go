@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StaffController struct {
+	StaffService *services.StaffService
+}
+
+// StaffControllerInterface defines the methods for managing restaurant staff
+type StaffControllerInterface interface {
+	InviteStaff(c *gin.Context)
+	GetRestaurantStaff(c *gin.Context)
+	UpdateStaff(c *gin.Context)
+	RemoveStaff(c *gin.Context)
+	GetMyInvitations(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
+	GetRolePermissions(c *gin.Context)
+}
+
+// InviteStaff invites someone by email to a restaurant's staff
+func (ctrl *StaffController) InviteStaff(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(c, body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	staff, token, err := ctrl.StaffService.InviteStaff(loggedInUser.(models.User), restaurantID, body.Email, body.Role)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to invite staff", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Staff invited successfully", gin.H{
+		"staff":        staff,
+		"invite_token": token,
+	})
+}
+
+// GetRestaurantStaff retrieves the staff of a restaurant
+func (ctrl *StaffController) GetRestaurantStaff(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	staff, err := ctrl.StaffService.GetRestaurantStaff(restaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve staff", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Staff retrieved successfully", staff)
+}
+
+// UpdateStaff changes the role of a staff member
+func (ctrl *StaffController) UpdateStaff(c *gin.Context) {
+	staffID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(c, body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	staff, err := ctrl.StaffService.UpdateStaffRole(loggedInUser.(models.User), staffID, body.Role)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update staff", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Staff updated successfully", staff)
+}
+
+// RemoveStaff removes a staff member or cancels an invitation
+func (ctrl *StaffController) RemoveStaff(c *gin.Context) {
+	staffID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	if err := ctrl.StaffService.RemoveStaff(loggedInUser.(models.User), staffID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to remove staff", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Staff removed successfully", nil)
+}
+
+// GetMyInvitations retrieves the pending staff invitations of the logged in user
+func (ctrl *StaffController) GetMyInvitations(c *gin.Context) {
+	loggedInUser, _ := c.Get("user")
+	invitations, err := ctrl.StaffService.GetMyInvitations(loggedInUser.(models.User))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve invitations", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+// AcceptInvitation accepts a staff invitation for the logged in user
+func (ctrl *StaffController) AcceptInvitation(c *gin.Context) {
+	var body struct {
+		Token string `json:"token"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	if err := utils.ValidateStruct(c, body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	staff, err := ctrl.StaffService.AcceptInvitation(loggedInUser.(models.User), body.Token)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to accept invitation", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invitation accepted successfully", staff)
+}
+
+// GetRolePermissions lists the permissions of every staff role
+func (ctrl *StaffController) GetRolePermissions(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Role permissions retrieved successfully", services.RolePermissions)
+}
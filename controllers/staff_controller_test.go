@@ -0,0 +1,100 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type staffMember struct {
+	ID     uint   `json:"id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
+}
+
+// inviteCustomer has the manager invite the fixture customer with the role and the customer accept it
+func inviteCustomer(t *testing.T, h *testutil.Harness, manager string, customer string, role string) staffMember {
+	t.Helper()
+	var invitation struct {
+		Staff       staffMember `json:"staff"`
+		InviteToken string      `json:"invite_token"`
+	}
+	h.Request(t, http.MethodPost, fmt.Sprintf("/api/staff/restaurant/%d/invite", h.Fixtures.Restaurant.ID), manager, map[string]string{
+		"email": h.Fixtures.Customer.Email, "role": role,
+	}).Expect(t, http.StatusCreated).Decode(t, &invitation)
+
+	var member staffMember
+	h.Request(t, http.MethodPost, "/api/staff/invitations/accept", customer, map[string]string{"token": invitation.InviteToken}).
+		Expect(t, http.StatusOK).Decode(t, &member)
+	if member.Status != "active" {
+		t.Fatalf("expected the invitation to be accepted, got %+v", member)
+	}
+	return member
+}
+
+func TestStaffRoleGrantsPermissions(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+	newTable := map[string]interface{}{"name": "Bar", "number": 9, "capacity": 2, "restaurant_id": h.Fixtures.Restaurant.ID}
+	newFood := map[string]interface{}{"name": "Kimbap", "price": 6, "restaurant_id": h.Fixtures.Restaurant.ID}
+
+	h.Request(t, http.MethodPost, "/api/tables/", customer, newTable).Expect(t, http.StatusForbidden)
+
+	member := inviteCustomer(t, h, manager, customer, "waiter")
+
+	// a waiter manages the tables but not the menu
+	h.Request(t, http.MethodPost, "/api/tables/", customer, newTable).Expect(t, http.StatusCreated)
+	h.Request(t, http.MethodPost, "/api/foods/", customer, newFood).Expect(t, http.StatusForbidden)
+
+	path := fmt.Sprintf("/api/staff/%d", member.ID)
+	h.Request(t, http.MethodPut, path, manager, map[string]string{"role": "chef"}).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodPost, "/api/foods/", customer, newFood).Expect(t, http.StatusCreated)
+
+	// nor a waiter nor a chef manages the staff
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/staff/restaurant/%d", h.Fixtures.Restaurant.ID), customer, nil).
+		Expect(t, http.StatusForbidden)
+
+	h.Request(t, http.MethodDelete, path, manager, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodPost, "/api/foods/", customer, map[string]interface{}{"name": "Tteok", "restaurant_id": h.Fixtures.Restaurant.ID}).
+		Expect(t, http.StatusForbidden)
+}
+
+func TestStaffInvitations(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	customer := h.LoginAs(t, "customer")
+	invitePath := fmt.Sprintf("/api/staff/restaurant/%d/invite", h.Fixtures.Restaurant.ID)
+
+	h.Request(t, http.MethodPost, invitePath, manager, map[string]string{"email": h.Fixtures.Customer.Email, "role": "janitor"}).
+		Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPost, invitePath, manager, map[string]string{"email": h.Fixtures.Customer.Email, "role": "cashier"}).
+		Expect(t, http.StatusCreated)
+	h.Request(t, http.MethodPost, invitePath, manager, map[string]string{"email": h.Fixtures.Customer.Email, "role": "cashier"}).
+		Expect(t, http.StatusBadRequest)
+
+	var invitations []staffMember
+	h.Request(t, http.MethodGet, "/api/staff/invitations", customer, nil).Expect(t, http.StatusOK).Decode(t, &invitations)
+	if len(invitations) != 1 || invitations[0].Role != "cashier" {
+		t.Fatalf("expected the cashier invitation, got %+v", invitations)
+	}
+
+	h.Request(t, http.MethodPost, "/api/staff/invitations/accept", customer, map[string]string{"token": "forged"}).
+		Expect(t, http.StatusBadRequest)
+
+	var staff []staffMember
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/staff/restaurant/%d", h.Fixtures.Restaurant.ID), manager, nil).
+		Expect(t, http.StatusOK).Decode(t, &staff)
+	if len(staff) != 1 || staff[0].Status != "invited" {
+		t.Fatalf("expected the pending invitation, got %+v", staff)
+	}
+
+	var roles map[string][]string
+	h.Request(t, http.MethodGet, "/api/staff/roles", customer, nil).Expect(t, http.StatusOK).Decode(t, &roles)
+	if len(roles["owner"]) == 0 {
+		t.Fatalf("expected the permissions of the owner, got %+v", roles)
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 // TableControllerImpl implements the TableController interface
 type TableController struct {
-	TableService services.TableService
+	TableService *services.TableService
 }
 
 // TableController defines the methods for handling table related operations
@@ -73,13 +73,12 @@ func (f *TableController) UpdateTable(c *gin.Context) {
 		return
 	}
 	var body struct {
-		Name         string  `json:"name"`
-		Number       int     `json:"number"`
-		Capacity     int     `json:"capacity"`
-		Image        string  `json:"image"`
-		Price        float64 `json:"price"`
-		RestaurantID uint    `json:"restaurant_id"`
-		CategoryId   uint    `json:"category_id"`
+		Name       string  `json:"name"`
+		Number     int     `json:"number"`
+		Capacity   int     `json:"capacity"`
+		Image      string  `json:"image"`
+		Price      float64 `json:"price"`
+		CategoryId uint    `json:"category_id"`
 	}
 
 	// Validate the request body
@@ -111,9 +110,6 @@ func (f *TableController) UpdateTable(c *gin.Context) {
 	if body.Price == 0 {
 		body.Price = existingTable.Price
 	}
-	if body.RestaurantID == 0 {
-		body.RestaurantID = existingTable.RestaurantID
-	}
 	if body.CategoryId == 0 {
 		body.CategoryId = existingTable.CategoryId
 	}
@@ -123,17 +119,16 @@ func (f *TableController) UpdateTable(c *gin.Context) {
 		return
 	}
 
-	var table models.Table
+	table := existingTable
 	table.Name = body.Name
 	table.Number = body.Number
 	table.Capacity = body.Capacity
 	table.Image = body.Image
 	table.Price = body.Price
-	table.RestaurantID = body.RestaurantID
 	table.CategoryId = body.CategoryId
 
 	// Call the UpdateTable service
-	updatedTable, err := f.TableService.UpdateTable(&table)
+	updatedTable, err := f.TableService.UpdateTable(table)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update table", err.Error())
 		return
@@ -185,10 +180,23 @@ func (f *TableController) GetTable(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Table retrieved successfully", table)
 }
 
+// tableListSpec is what the tables lists can be sorted and filtered by
+var tableListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "number": "number", "capacity": "capacity", "price": "price", "average_rating": "average_rating", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"restaurant_id": utils.IDColumn("restaurant_id"), "category_id": utils.IDColumn("category_id"), "capacity": utils.IntColumn("capacity")},
+	DefaultSort: "id",
+}
+
 // GetAllTables retrieves all table items
 func (f *TableController) GetAllTables(c *gin.Context) {
+	query, err := utils.ParseListQuery(c, tableListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the GetAllTables service
-	tables, err := f.TableService.GetAllTables()
+	tables, pagination, err := f.TableService.GetAllTables(query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve tables", err.Error())
@@ -196,7 +204,7 @@ func (f *TableController) GetAllTables(c *gin.Context) {
 	}
 
 	// Return the list of tables
-	utils.SuccessResponse(c, http.StatusOK, "Tables retrieved successfully", tables)
+	utils.PaginatedResponse(c, http.StatusOK, "Tables retrieved successfully", tables, pagination)
 }
 
 // GetRestaurantTables retrieves all the tables of a particular restaurant
@@ -206,8 +214,14 @@ func (f *TableController) GetRestaurantTables(c *gin.Context) {
 		return
 	}
 
-	// Call the GetRestaurantTables service
-	tables, err := f.TableService.GetRestaurantTables(restaurantId)
+	query, err := utils.ParseListQuery(c, tableListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	// Call the GetAllTables service
+	tables, pagination, err := f.TableService.GetAllTables(query.Where("restaurant_id", restaurantId))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve tables", err.Error())
@@ -215,16 +229,20 @@ func (f *TableController) GetRestaurantTables(c *gin.Context) {
 	}
 
 	// Return the list of tables
-	utils.SuccessResponse(c, http.StatusOK, "Tables retrieved successfully", tables)
+	utils.PaginatedResponse(c, http.StatusOK, "Tables retrieved successfully", tables, pagination)
 }
 
 // SearchTable
 func (f *TableController) SearchTable(c *gin.Context) {
 	// Get the search query parameter
 	query := c.Query("q")
+	restaurantId, valid := utils.OptionalQueryID(c, "restaurant_id")
+	if !valid {
+		return
+	}
 
 	// Call the SearchTable service
-	tables, err := f.TableService.SearchTables(query)
+	tables, err := f.TableService.SearchTables(query, restaurantId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search for tables", err.Error())
@@ -241,8 +259,10 @@ func (f *TableController) RecommendedTables(c *gin.Context) {
 	if !valid {
 		return
 	}
+	loggedInUser, _ := c.Get("user")
+
 	// Call the RecommendedTables service
-	tables, err := f.TableService.GetRecommendedTables(restaurantId)
+	tables, err := f.TableService.GetRecommendedTables(c.Request.Context(), loggedInUser.(models.User).ID, restaurantId)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recommended tables", err.Error())
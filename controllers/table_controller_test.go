@@ -0,0 +1,79 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type table struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	Number       int     `json:"number"`
+	Capacity     int     `json:"capacity"`
+	Price        float64 `json:"price"`
+	RestaurantID uint    `json:"restaurant_id"`
+}
+
+func TestTableLifecycle(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "manager")
+
+	var created table
+	h.Request(t, http.MethodPost, "/api/tables/", token, map[string]interface{}{
+		"name": "Terrace", "number": 2, "capacity": 6, "price": 8,
+		"restaurant_id": h.Fixtures.Restaurant.ID, "category_id": h.Fixtures.Category.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &created)
+	if created.Number != 2 || created.Capacity != 6 {
+		t.Fatalf("unexpected table %+v", created)
+	}
+	path := fmt.Sprintf("/api/tables/%d", created.ID)
+
+	// the table stays in its restaurant whatever the body says
+	var updated table
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"capacity": 8, "restaurant_id": h.Fixtures.Restaurant.ID + 1}).
+		Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.ID != created.ID || updated.Capacity != 8 || updated.Name != "Terrace" || updated.RestaurantID != h.Fixtures.Restaurant.ID {
+		t.Fatalf("unexpected table after the update %+v", updated)
+	}
+
+	var tables []table
+	h.Request(t, http.MethodGet, "/api/tables/?capacity=8", token, nil).Expect(t, http.StatusOK).Decode(t, &tables)
+	if len(tables) != 1 || tables[0].ID != created.ID {
+		t.Fatalf("expected only the updated table, got %+v", tables)
+	}
+
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusNotFound)
+}
+
+func TestTableRequiresTablePermission(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	h.Request(t, http.MethodPost, "/api/tables/", token, map[string]interface{}{
+		"name": "Terrace", "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPut, fmt.Sprintf("/api/tables/%d", h.Fixtures.Table.ID), token, map[string]interface{}{"capacity": 2}).
+		Expect(t, http.StatusForbidden)
+}
+
+func TestBrowseTables(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	lists := []string{
+		fmt.Sprintf("/api/tables/restaurant/%d", h.Fixtures.Restaurant.ID),
+		"/api/tables/search?q=window",
+		fmt.Sprintf("/api/tables/restaurant/%d/recommended", h.Fixtures.Restaurant.ID),
+	}
+	for _, path := range lists {
+		var tables []table
+		h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &tables)
+		if len(tables) != 1 || tables[0].ID != h.Fixtures.Table.ID {
+			t.Errorf("%s: expected the fixture table, got %+v", path, tables)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 type TransactionController struct {
-	TransactionService services.TransactionService
+	TransactionService *services.TransactionService
 }
 
 type TransactionControllerInterface interface {
@@ -22,8 +22,24 @@ type TransactionControllerInterface interface {
 	GetRestaurantTransactions(c *gin.Context)
 }
 
+// transactionListSpec is what the transactions lists can be sorted and filtered by
+var transactionListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "amount": "amount", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"status": utils.TextColumn("status"), "restaurant_id": utils.IDColumn("restaurant_id"), "order_id": utils.IDColumn("order_id"), "payment_id": utils.IDColumn("payment_id")},
+	DefaultSort: "-created_at",
+}
+
+// transactionFeedSpec pages the transactions of a restaurant by cursor on (created_at, id)
+var transactionFeedSpec = utils.ListSpec{Sorts: transactionListSpec.Sorts, Filters: transactionListSpec.Filters, DefaultSort: "-created_at", Keyset: true}
+
 func (ctrl *TransactionController) GetAllTransactions(c *gin.Context) {
-	transactions, err := ctrl.TransactionService.GetTransactions()
+	query, err := utils.ParseListQuery(c, transactionListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	transactions, pagination, err := ctrl.TransactionService.GetTransactions(query)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve transactions", err.Error())
@@ -31,7 +47,7 @@ func (ctrl *TransactionController) GetAllTransactions(c *gin.Context) {
 	}
 
 	// Return the list of transactions
-	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
+	utils.PaginatedResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions, pagination)
 }
 
 func (ctrl *TransactionController) GetTransaction(c *gin.Context) {
@@ -112,12 +128,11 @@ func (ctrl *TransactionController) UpdateTransaction(c *gin.Context) {
 		return
 	}
 	//check fig the transaction exists
-	_, err := ctrl.TransactionService.GetTransaction(transactionID)
+	transaction, err := ctrl.TransactionService.GetTransaction(transactionID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Transaction not found", err.Error())
 		return
 	}
-	var transaction models.Transaction
 	transaction.OrderID = body.OrderID
 	transaction.PaymentID = body.PaymentID
 	transaction.Status = body.Status
@@ -170,8 +185,14 @@ func (ctrl *TransactionController) GetRestaurantTransactions(c *gin.Context) {
 		return
 	}
 
+	query, err := utils.ParseListQuery(c, transactionFeedSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
 	// Call the service to retrieve the transactions for the restaurant
-	transactions, err := ctrl.TransactionService.GetRestaurantTransactions(restaurantID)
+	transactions, pagination, err := ctrl.TransactionService.GetTransactions(query.Where("restaurant_id", restaurantID))
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve transactions", err.Error())
@@ -179,5 +200,5 @@ func (ctrl *TransactionController) GetRestaurantTransactions(c *gin.Context) {
 	}
 
 	// Return the list of transactions
-	utils.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions)
+	utils.PaginatedResponse(c, http.StatusOK, "Transactions retrieved successfully", transactions, pagination)
 }
@@ -0,0 +1,57 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type transaction struct {
+	ID        uint    `json:"id"`
+	OrderID   uint    `json:"order_id"`
+	PaymentID uint    `json:"payment_id"`
+	Amount    float64 `json:"amount"`
+	Status    string  `json:"status"`
+}
+
+func TestTransactionLifecycle(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+	placed := placeOrder(t, h, token)
+
+	var created transaction
+	h.Request(t, http.MethodPost, "/api/transactions/", token, map[string]interface{}{
+		"order_id": placed.ID, "status": "initiated", "amount": placed.TotalPrice, "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusCreated).Decode(t, &created)
+	path := fmt.Sprintf("/api/transactions/%d", created.ID)
+
+	var updated transaction
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{
+		"order_id": placed.ID, "status": "completed", "amount": placed.TotalPrice, "restaurant_id": h.Fixtures.Restaurant.ID,
+	}).Expect(t, http.StatusOK).Decode(t, &updated)
+	if updated.ID != created.ID || updated.Status != "completed" {
+		t.Fatalf("unexpected transaction after the update %+v", updated)
+	}
+
+	var transactions []transaction
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/transactions/restaurant/%d", h.Fixtures.Restaurant.ID), token, nil).
+		Expect(t, http.StatusOK).Decode(t, &transactions)
+	if len(transactions) != 1 || transactions[0].Status != "completed" {
+		t.Fatalf("expected the single updated transaction, got %+v", transactions)
+	}
+
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusNotFound)
+}
+
+func TestTransactionsRejectInvalidParameters(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+
+	h.Request(t, http.MethodGet, "/api/transactions/abc", token, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/transactions/?page=0", token, nil).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodGet, "/api/transactions/?sort=secret", token, nil).Expect(t, http.StatusBadRequest)
+}
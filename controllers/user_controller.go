@@ -55,7 +55,7 @@ func (controller *UserController) Register(c *gin.Context) {
 	user.Role = body.Role
 	user.EmailVerificationOTP = otp
 
-	err := controller.UserService.RegisterUser(&user)
+	err := controller.UserService.RegisterUser(c.Request.Context(), &user)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to register user", err.Error())
 		return
@@ -94,7 +94,7 @@ func (controller *UserController) ValidateEmail(c *gin.Context) {
 		return
 	}
 
-	user, err := controller.UserService.VerifyEmailOTP(body.Email, body.Otp)
+	user, err := controller.UserService.VerifyEmailOTP(c.Request.Context(), body.Email, body.Otp)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to validate email", err.Error())
 		return
@@ -133,7 +133,7 @@ func (controller *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := controller.UserService.LoginUser(body.Email, body.Password)
+	user, err := controller.UserService.LoginUser(c.Request.Context(), body.Email, body.Password)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to login user", err.Error())
 		return
@@ -162,7 +162,7 @@ func (controller *UserController) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	user, err := controller.UserService.GetUserByID(userID)
+	user, err := controller.UserService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Failed to get user", err.Error())
 		return
@@ -184,9 +184,22 @@ func (controller *UserController) GetUserByID(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "User retrieved successfully", userResponse)
 }
 
+// userListSpec is what the users lists can be sorted and filtered by
+var userListSpec = utils.ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "email": "email", "created_at": "created_at"},
+	Filters:     map[string]utils.Filter{"role": utils.TextColumn("role"), "active": utils.BoolColumn("active"), "email_verified": utils.BoolColumn("email_verified")},
+	DefaultSort: "id",
+}
+
 // GetAllUsers get all users
 func (controller *UserController) GetAllUsers(c *gin.Context) {
-	users, err := controller.UserService.GetAllUsers()
+	query, err := utils.ParseListQuery(c, userListSpec)
+	if err != nil {
+		utils.ListQueryError(c, err)
+		return
+	}
+
+	users, pagination, err := controller.UserService.GetAllUsers(c.Request.Context(), query)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get users", err.Error())
 		return
@@ -209,7 +222,7 @@ func (controller *UserController) GetAllUsers(c *gin.Context) {
 		userResponses = append(userResponses, userResponse)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", userResponses)
+	utils.PaginatedResponse(c, http.StatusOK, "Users retrieved successfully", userResponses, pagination)
 }
 
 // UpdateUser update user
@@ -237,7 +250,7 @@ func (controller *UserController) UpdateUser(c *gin.Context) {
 	// 	return
 	// }
 	// Get user from database
-	user, err := controller.UserService.GetUserByID(userID)
+	user, err := controller.UserService.GetUserByID(c.Request.Context(), userID)
 	// Handle error
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get user", err.Error())
@@ -252,7 +265,7 @@ func (controller *UserController) UpdateUser(c *gin.Context) {
 	user.Active = body.Active
 
 	// Update user in database
-	err = controller.UserService.UpdateUser(user)
+	err = controller.UserService.UpdateUser(c.Request.Context(), user)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update user", err.Error())
 		return
@@ -280,7 +293,7 @@ func (controller *UserController) DeleteUser(c *gin.Context) {
 	if !valid {
 		return
 	}
-	err := controller.UserService.DeleteUser(userID)
+	err := controller.UserService.DeleteUser(c.Request.Context(), userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Failed to delete user", err.Error())
 		return
@@ -0,0 +1,221 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"madang_api/config"
+	"madang_api/models"
+	"madang_api/testutil"
+)
+
+func TestRegisterVerifyAndLogin(t *testing.T) {
+	h := testutil.New(t)
+
+	var otp string
+	h.Request(t, http.MethodPost, "/api/auth/register", "", map[string]string{
+		"name": "New Customer", "email": "new@madang.test", "password": "secret123", "role": "customer",
+	}).Expect(t, http.StatusCreated).Decode(t, &otp)
+
+	// the email must be verified before logging in
+	h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": "new@madang.test", "password": "secret123"}).
+		Expect(t, http.StatusBadRequest)
+
+	h.Request(t, http.MethodPost, "/api/auth/verify-email", "", map[string]string{"email": "new@madang.test", "otp": "wrong"}).
+		Expect(t, http.StatusBadRequest)
+
+	var verified struct {
+		Token string `json:"token"`
+	}
+	h.Request(t, http.MethodPost, "/api/auth/verify-email", "", map[string]string{"email": "new@madang.test", "otp": otp}).
+		Expect(t, http.StatusOK).Decode(t, &verified)
+	if verified.Token == "" {
+		t.Fatal("verifying the email returned no token")
+	}
+
+	h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": "new@madang.test", "password": "secret123"}).
+		Expect(t, http.StatusOK)
+}
+
+func TestRegisterRejectsDuplicateEmail(t *testing.T) {
+	h := testutil.New(t)
+
+	h.Request(t, http.MethodPost, "/api/auth/register", "", map[string]string{
+		"name": "Copy", "email": h.Fixtures.Customer.Email, "password": "secret123", "role": "customer",
+	}).Expect(t, http.StatusBadRequest)
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	h := testutil.New(t)
+
+	wrong := h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": h.Fixtures.Customer.Email, "password": "wrong"}).
+		Expect(t, http.StatusBadRequest)
+	// an unknown email gets the very same answer, logins do not tell which emails have an account
+	unknown := h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": "nobody@madang.test", "password": "wrong"}).
+		Expect(t, http.StatusBadRequest)
+	if wrong.Message != unknown.Message || wrong.Error != unknown.Error {
+		t.Fatalf("expected the same answer for a wrong password and an unknown email, got %s and %s", wrong.Body, unknown.Body)
+	}
+}
+
+func TestLoginLocksAccountAfterFailures(t *testing.T) {
+	h := testutil.New(t, func(cfg *config.Config) {
+		cfg.LoginMaxFailures = 3
+		cfg.LoginLockout = time.Minute
+	})
+	customer := h.Fixtures.Customer
+	login := func(password string) *testutil.Response {
+		return h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": customer.Email, "password": password})
+	}
+
+	login("wrong").Expect(t, http.StatusBadRequest)
+	login("wrong").Expect(t, http.StatusBadRequest)
+	login("wrong").Expect(t, http.StatusBadRequest)
+	// the right password does not get in either while the account is locked, and the answer is the one of an
+	// unknown email
+	locked := login(testutil.Password).Expect(t, http.StatusBadRequest)
+	unknown := h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": "nobody@madang.test", "password": testutil.Password}).
+		Expect(t, http.StatusBadRequest)
+	if locked.Message != unknown.Message || locked.Error != unknown.Error {
+		t.Fatalf("expected a locked account to answer like an unknown email, got %s and %s", locked.Body, unknown.Body)
+	}
+	var user models.User
+	if err := h.DB.First(&user, customer.ID).Error; err != nil {
+		t.Fatalf("loading the customer: %v", err)
+	}
+	if user.LockedUntil == nil || time.Until(*user.LockedUntil) > time.Minute {
+		t.Fatalf("expected the account to be locked for the lockout, got %v", user.LockedUntil)
+	}
+
+	// once the lockout is over the right password logs in and the failures are forgotten
+	if err := h.DB.Model(&models.User{}).Where("id = ?", customer.ID).Update("locked_until", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("ending the lockout: %v", err)
+	}
+	login(testutil.Password).Expect(t, http.StatusOK)
+	if err := h.DB.First(&user, customer.ID).Error; err != nil {
+		t.Fatalf("loading the customer: %v", err)
+	}
+	if user.FailedLogins != 0 || user.LockedUntil != nil {
+		t.Fatalf("expected the failed logins to be reset, got %d and %v", user.FailedLogins, user.LockedUntil)
+	}
+}
+
+func TestAuthRoutesAreRateLimited(t *testing.T) {
+	h := testutil.New(t, func(cfg *config.Config) {
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitWindow = time.Minute
+		cfg.RateLimitIPRequests = 100
+		cfg.RateLimitAuthRequests = 2
+	})
+	body := map[string]string{"email": h.Fixtures.Customer.Email, "password": "wrong"}
+
+	h.Request(t, http.MethodPost, "/api/auth/login", "", body).Expect(t, http.StatusBadRequest)
+	h.Request(t, http.MethodPost, "/api/auth/login", "", body).Expect(t, http.StatusBadRequest)
+	limited := h.Request(t, http.MethodPost, "/api/auth/login", "", body).Expect(t, http.StatusTooManyRequests)
+	if limited.Header.Get("Retry-After") == "" || limited.Header.Get("X-RateLimit-Remaining") != "0" || limited.RequestID == "" {
+		t.Fatalf("expected Retry-After, the remaining requests and the request ID, got %v: %s", limited.Header, limited.Body)
+	}
+
+	// without trusted proxies a forged X-Forwarded-For still counts against the address of the connection
+	request := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"x@madang.test","password":"wrong"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	h.Do(t, request).Expect(t, http.StatusTooManyRequests)
+
+	// each route has its own bucket
+	h.Request(t, http.MethodPost, "/api/auth/verify-email", "", map[string]string{"email": h.Fixtures.Customer.Email, "otp": "000000"}).
+		Expect(t, http.StatusBadRequest)
+}
+
+func TestUserRequestsAreRateLimited(t *testing.T) {
+	h := testutil.New(t, func(cfg *config.Config) {
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitWindow = time.Minute
+		cfg.RateLimitIPRequests = 100
+		cfg.RateLimitAuthRequests = 100
+		cfg.RateLimitUserRequests = 2
+	})
+	customer := h.LoginAs(t, "customer")
+	path := fmt.Sprintf("/api/users/%d", h.Fixtures.Customer.ID)
+
+	h.Request(t, http.MethodGet, path, customer, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, customer, nil).Expect(t, http.StatusOK)
+	h.Request(t, http.MethodGet, path, customer, nil).Expect(t, http.StatusTooManyRequests)
+
+	// each route has its own bucket
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/orders/user/%d", h.Fixtures.Customer.ID), customer, nil).Expect(t, http.StatusOK)
+
+	// the probes are never limited
+	for i := 0; i < 3; i++ {
+		h.Request(t, http.MethodGet, "/healthz", "", nil).Expect(t, http.StatusOK)
+	}
+}
+
+func TestUsersRequireToken(t *testing.T) {
+	h := testutil.New(t)
+
+	h.Request(t, http.MethodGet, "/api/users", "", nil).Expect(t, http.StatusUnauthorized)
+	h.Request(t, http.MethodGet, "/api/users", "not-a-jwt", nil).Expect(t, http.StatusUnauthorized)
+
+	// a header that is not a bearer token is refused too
+	request := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	h.Do(t, request).Expect(t, http.StatusUnauthorized)
+}
+
+func TestErrorResponsesCarryRequestID(t *testing.T) {
+	h := testutil.New(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	request.Header.Set("X-Request-ID", "test-request-1")
+	response := h.Do(t, request).Expect(t, http.StatusUnauthorized)
+	if response.RequestID != "test-request-1" || response.Header.Get("X-Request-ID") != "test-request-1" {
+		t.Fatalf("expected the request ID to be propagated, got %q and %q", response.RequestID, response.Header.Get("X-Request-ID"))
+	}
+
+	// an ID is assigned when the client sends none or an unusable one
+	request = httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	request.Header.Set("X-Request-ID", "bad id\nwith newline")
+	response = h.Do(t, request).Expect(t, http.StatusUnauthorized)
+	if response.RequestID == "" || response.RequestID != response.Header.Get("X-Request-ID") || response.RequestID == "bad id\nwith newline" {
+		t.Fatalf("expected a new request ID, got %q", response.RequestID)
+	}
+}
+
+func TestGetUpdateAndDeleteUser(t *testing.T) {
+	h := testutil.New(t)
+	token := h.LoginAs(t, "customer")
+	path := fmt.Sprintf("/api/users/%d", h.Fixtures.Customer.ID)
+
+	var user struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+	h.Request(t, http.MethodGet, path, token, nil).Expect(t, http.StatusOK).Decode(t, &user)
+	if user.Email != h.Fixtures.Customer.Email {
+		t.Fatalf("expected the customer, got %s", user.Email)
+	}
+
+	h.Request(t, http.MethodPut, path, token, map[string]interface{}{"name": "Renamed", "active": true}).
+		Expect(t, http.StatusOK).Decode(t, &user)
+	if user.Name != "Renamed" {
+		t.Fatalf("expected the new name, got %s", user.Name)
+	}
+
+	var users []struct {
+		ID uint `json:"id"`
+	}
+	h.Request(t, http.MethodGet, "/api/users", token, nil).Expect(t, http.StatusOK).Decode(t, &users)
+	if len(users) != 3 {
+		t.Fatalf("expected the 3 fixture users, got %d", len(users))
+	}
+
+	h.Request(t, http.MethodGet, "/api/users/999", token, nil).Expect(t, http.StatusNotFound)
+	h.Request(t, http.MethodGet, "/api/users/abc", token, nil).Expect(t, http.StatusBadRequest)
+
+	h.Request(t, http.MethodDelete, path, token, nil).Expect(t, http.StatusOK)
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"madang_api/models"
+	"madang_api/services"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerificationController struct {
+	VerificationService *services.VerificationService
+}
+
+// VerificationControllerInterface defines the methods for the restaurant verification workflow
+type VerificationControllerInterface interface {
+	SubmitVerification(c *gin.Context)
+	GetRestaurantVerificationHistory(c *gin.Context)
+	GetReviewQueue(c *gin.Context)
+	GetVerification(c *gin.Context)
+	ApproveVerification(c *gin.Context)
+	RejectVerification(c *gin.Context)
+	SetRestaurantActive(c *gin.Context)
+}
+
+// SubmitVerification handles a manager sending a restaurant for verification
+func (ctrl *VerificationController) SubmitVerification(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		RegistrationNumber string `json:"registration_number" binding:"required"`
+		TaxID              string `json:"tax_id"`
+		Notes              string `json:"notes"`
+		Documents          []struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		} `json:"documents"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	loggedInUser, exists := c.Get("user")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "bad request", "User not found")
+		return
+	}
+
+	var verification models.RestaurantVerification
+	verification.RestaurantID = restaurantID
+	verification.SubmittedBy = loggedInUser.(models.User).ID
+	verification.RegistrationNumber = body.RegistrationNumber
+	verification.TaxID = body.TaxID
+	verification.Notes = body.Notes
+	for _, document := range body.Documents {
+		verification.Documents = append(verification.Documents, models.VerificationDocument{
+			Type: document.Type,
+			URL:  document.URL,
+		})
+	}
+
+	newVerification, err := ctrl.VerificationService.SubmitVerification(&verification)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to submit verification", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Verification submitted successfully", newVerification)
+}
+
+// GetRestaurantVerificationHistory retrieves the verification submissions and status history of a restaurant
+func (ctrl *VerificationController) GetRestaurantVerificationHistory(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	history, err := ctrl.VerificationService.GetRestaurantVerificationHistory(restaurantID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Restaurant not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification history retrieved successfully", history)
+}
+
+// GetReviewQueue retrieves the verifications waiting for review (or with the ?status= given)
+func (ctrl *VerificationController) GetReviewQueue(c *gin.Context) {
+	verifications, err := ctrl.VerificationService.GetReviewQueue(c.Query("status"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve review queue", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Review queue retrieved successfully", verifications)
+}
+
+// GetVerification retrieves a specific verification request
+func (ctrl *VerificationController) GetVerification(c *gin.Context) {
+	verificationID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	verification, err := ctrl.VerificationService.GetVerification(verificationID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Verification not found", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification retrieved successfully", verification)
+}
+
+// ApproveVerification handles an admin approving a verification request
+func (ctrl *VerificationController) ApproveVerification(c *gin.Context) {
+	verificationID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && c.Request.ContentLength > 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	verification, err := ctrl.VerificationService.ApproveVerification(verificationID, loggedInUser.(models.User).ID, body.Reason)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to approve verification", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification approved successfully", verification)
+}
+
+// RejectVerification handles an admin rejecting a verification request
+func (ctrl *VerificationController) RejectVerification(c *gin.Context) {
+	verificationID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	verification, err := ctrl.VerificationService.RejectVerification(verificationID, loggedInUser.(models.User).ID, body.Reason)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to reject verification", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification rejected successfully", verification)
+}
+
+// SetRestaurantActive handles an admin suspending or reactivating a restaurant
+func (ctrl *VerificationController) SetRestaurantActive(c *gin.Context) {
+	restaurantID, valid := utils.ValidateID(c, "id")
+	if !valid {
+		return
+	}
+
+	var body struct {
+		Active *bool  `json:"active" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	loggedInUser, _ := c.Get("user")
+	restaurant, err := ctrl.VerificationService.SetRestaurantActive(restaurantID, *body.Active, loggedInUser.(models.User).ID, body.Reason)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update restaurant status", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Restaurant status updated successfully", restaurant)
+}
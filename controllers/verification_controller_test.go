@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"madang_api/testutil"
+)
+
+type verification struct {
+	ID           uint   `json:"id"`
+	RestaurantID uint   `json:"restaurant_id"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason"`
+}
+
+// submitVerification has the manager submit the fixture restaurant for review
+func submitVerification(t *testing.T, h *testutil.Harness, manager string) verification {
+	t.Helper()
+	var submitted verification
+	h.Request(t, http.MethodPost, fmt.Sprintf("/api/verifications/restaurant/%d", h.Fixtures.Restaurant.ID), manager, map[string]interface{}{
+		"registration_number": "123-45-67890",
+		"documents":           []map[string]string{{"type": "business_license", "url": "https://files.madang.test/license.pdf"}},
+	}).Expect(t, http.StatusCreated).Decode(t, &submitted)
+	if submitted.Status != "pending" {
+		t.Fatalf("expected a pending verification, got %+v", submitted)
+	}
+	return submitted
+}
+
+func TestVerificationApproval(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	admin := h.LoginAs(t, "admin")
+	customer := h.LoginAs(t, "customer")
+	path := fmt.Sprintf("/api/verifications/restaurant/%d", h.Fixtures.Restaurant.ID)
+
+	h.Request(t, http.MethodPost, path, customer, map[string]interface{}{"registration_number": "1"}).Expect(t, http.StatusForbidden)
+	// a submission needs documents
+	h.Request(t, http.MethodPost, path, manager, map[string]interface{}{"registration_number": "1"}).Expect(t, http.StatusBadRequest)
+
+	submitted := submitVerification(t, h, manager)
+	// only one submission can be pending at a time
+	h.Request(t, http.MethodPost, path, manager, map[string]interface{}{
+		"registration_number": "123-45-67890",
+		"documents":           []map[string]string{{"type": "tax_certificate", "url": "https://files.madang.test/tax.pdf"}},
+	}).Expect(t, http.StatusBadRequest)
+
+	var queue []verification
+	h.Request(t, http.MethodGet, "/api/verifications/queue", admin, nil).Expect(t, http.StatusOK).Decode(t, &queue)
+	if len(queue) != 1 || queue[0].ID != submitted.ID {
+		t.Fatalf("expected the submission in the queue, got %+v", queue)
+	}
+	h.Request(t, http.MethodGet, "/api/verifications/queue", manager, nil).Expect(t, http.StatusForbidden)
+
+	approve := fmt.Sprintf("/api/verifications/%d/approve", submitted.ID)
+	h.Request(t, http.MethodPost, approve, manager, map[string]string{}).Expect(t, http.StatusForbidden)
+	var approved verification
+	h.Request(t, http.MethodPost, approve, admin, map[string]string{"reason": "documents match"}).
+		Expect(t, http.StatusOK).Decode(t, &approved)
+	if approved.Status != "approved" {
+		t.Fatalf("expected the verification to be approved, got %+v", approved)
+	}
+	// a reviewed submission cannot be reviewed again
+	h.Request(t, http.MethodPost, fmt.Sprintf("/api/verifications/%d/reject", submitted.ID), admin, map[string]string{"reason": "late"}).
+		Expect(t, http.StatusBadRequest)
+
+	var history struct {
+		VerificationStatus string         `json:"verification_status"`
+		Verified           bool           `json:"verified"`
+		Submissions        []verification `json:"submissions"`
+	}
+	h.Request(t, http.MethodGet, path, manager, nil).Expect(t, http.StatusOK).Decode(t, &history)
+	if !history.Verified || history.VerificationStatus != "approved" || len(history.Submissions) != 1 {
+		t.Fatalf("unexpected verification history %+v", history)
+	}
+
+	var fetched verification
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/verifications/%d", submitted.ID), admin, nil).Expect(t, http.StatusOK).Decode(t, &fetched)
+	if fetched.Status != "approved" {
+		t.Fatalf("expected the approved verification, got %+v", fetched)
+	}
+	// the restaurant of an unknown verification cannot be determined
+	h.Request(t, http.MethodGet, "/api/verifications/999999", admin, nil).Expect(t, http.StatusBadRequest)
+
+	// managers of other restaurants see none of it
+	otherToken := h.Login(t, h.AddUser(t, "other@madang.test", "manager").Email)
+	h.Request(t, http.MethodGet, path, otherToken, nil).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/verifications/%d", submitted.ID), otherToken, nil).Expect(t, http.StatusForbidden)
+}
+
+func TestVerificationRejection(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	admin := h.LoginAs(t, "admin")
+
+	submitted := submitVerification(t, h, manager)
+	reject := fmt.Sprintf("/api/verifications/%d/reject", submitted.ID)
+	// a rejection needs a reason
+	h.Request(t, http.MethodPost, reject, admin, map[string]string{}).Expect(t, http.StatusBadRequest)
+
+	var rejected verification
+	h.Request(t, http.MethodPost, reject, admin, map[string]string{"reason": "unreadable license"}).
+		Expect(t, http.StatusOK).Decode(t, &rejected)
+	if rejected.Status != "rejected" || rejected.Reason != "unreadable license" {
+		t.Fatalf("expected the verification to be rejected, got %+v", rejected)
+	}
+
+	// the manager can submit again once the previous submission is reviewed
+	submitVerification(t, h, manager)
+}
+
+func TestSetRestaurantActive(t *testing.T) {
+	h := testutil.New(t)
+	manager := h.LoginAs(t, "manager")
+	admin := h.LoginAs(t, "admin")
+	path := fmt.Sprintf("/api/verifications/restaurant/%d/active", h.Fixtures.Restaurant.ID)
+
+	h.Request(t, http.MethodPut, path, manager, map[string]interface{}{"active": false}).Expect(t, http.StatusForbidden)
+	h.Request(t, http.MethodPut, path, admin, map[string]interface{}{}).Expect(t, http.StatusBadRequest)
+
+	var restaurant struct {
+		Active bool `json:"active"`
+	}
+	h.Request(t, http.MethodPut, path, admin, map[string]interface{}{"active": false, "reason": "complaints"}).
+		Expect(t, http.StatusOK).Decode(t, &restaurant)
+	if restaurant.Active {
+		t.Fatal("expected the restaurant to be suspended")
+	}
+
+	var history struct {
+		Active        bool              `json:"active"`
+		StatusHistory []json.RawMessage `json:"status_history"`
+	}
+	h.Request(t, http.MethodGet, fmt.Sprintf("/api/verifications/restaurant/%d", h.Fixtures.Restaurant.ID), admin, nil).
+		Expect(t, http.StatusOK).Decode(t, &history)
+	if history.Active || len(history.StatusHistory) != 1 {
+		t.Fatalf("expected the suspension in the history, got %+v", history)
+	}
+}
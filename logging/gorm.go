@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger writes the logs of GORM through slog. Failed queries are logged at debug level, and queries
+// slower than the threshold at warn level when slow query logging is enabled. Queries are logged without
+// their values, which can be passwords or tokens
+type GormLogger struct {
+	slowThreshold time.Duration
+	logSlow       bool
+	level         logger.LogLevel
+}
+
+// NewGormLogger logs the queries slower than slowThreshold when logSlow is set
+func NewGormLogger(slowThreshold time.Duration, logSlow bool) *GormLogger {
+	return &GormLogger{slowThreshold: slowThreshold, logSlow: logSlow, level: logger.Warn}
+}
+
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "query failed", "sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed), "error", err)
+	case l.logSlow && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed), "threshold_ms", milliseconds(l.slowThreshold))
+	}
+}
+
+// ParamsFilter leaves the values out of the logged queries
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
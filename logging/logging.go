@@ -0,0 +1,110 @@
+// Package logging sets up the structured logger of the application. Every line logged with a context
+// gets the request ID and the span the context carries as the request_id, trace_id and span_id
+// attributes, and the values of sensitive keys are redacted
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup makes a logger writing to w at the level ("debug", "info", "warn" or "error") in the format
+// ("json" or "text") the default one, which the standard log package then writes through too
+func Setup(w io.Writer, level string, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	options := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redact}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return fmt.Errorf("invalid log format %q, expected json or text", format)
+	}
+
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, empty when there is none
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID and the span of the context to the records
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// sensitiveKeys are the attributes whose values never reach the logs
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"token":         true,
+	"access_token":  true,
+	"invite_token":  true,
+	"password":      true,
+	"secret":        true,
+	"otp":           true,
+}
+
+const redacted = "[redacted]"
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redacted)
+	}
+	if attr.Value.Kind() == slog.KindString {
+		attr.Value = slog.StringValue(RedactBearer(attr.Value.String()))
+	}
+	return attr
+}
+
+// RedactBearer hides the token of any "Bearer <token>" in s
+func RedactBearer(s string) string {
+	index := strings.Index(s, "Bearer ")
+	if index < 0 {
+		return s
+	}
+	rest := s[index+len("Bearer "):]
+	end := strings.IndexAny(rest, " \t\n\",")
+	if end < 0 {
+		end = len(rest)
+	}
+	return s[:index] + "Bearer " + redacted + RedactBearer(rest[end:])
+}
@@ -1,64 +1,41 @@
 package main
 
 import (
-	"fmt"
+	"log"
+	"log/slog"
+	"madang_api/app"
 	"madang_api/config"
-	"madang_api/routes"
-	"madang_api/services"
-
-	"github.com/gin-gonic/gin"
+	"madang_api/logging"
+	"madang_api/migrations"
+	"os"
 )
 
-func init() {
-	config.LoadEnvVars()
-	config.ConnectToDB()
-	// config.SyncDatabase()
-}
 func main() {
-	userService := &services.UserService{}
-	restaurantService := &services.RestaurantService{}
-	categoryService := &services.CategoryService{}
-	foodService := &services.FoodService{}
-	tableService := &services.TableService{}
-	addonService := &services.AddonService{}
-	orderService := &services.OrderService{}
-	paymentService := &services.PaymentService{}
-	transactionService := &services.TransactionService{}
-
-	// Set up Gin router
-	router := gin.Default()
-
-	// Set up user routes
-	routes.SetupUserRoutes(router, userService)
-
-	//Set up restuarant routes
-	routes.SetupRestaurantRoutes(router, restaurantService)
-
-	//Set up category routes
-	routes.SetupCategoryRoutes(router, categoryService)
-
-	//Set up food routes
-	routes.SetupFoodRoutes(router, foodService)
-
-	//Set up table routes
-	routes.SetupTableRoutes(router, tableService)
-
-	//Set up addon routes
-	routes.SetupAddonRoutes(router, addonService)
-
-	//Set up order routes
-	routes.SetupOrderRoutes(router, orderService)
-
-	//Set up payment routes
-	routes.SetupPaymentRoutes(router, paymentService)
-
-	//Set up transaction routes
-	routes.SetupTransactionRoutes(router, transactionService)
-
-	//Set up init routes
-	routes.SetupInitRoutes(router)
-
-	fmt.Println("Server stated running successfully")
-
-	router.Run() // listen and serve on localhost:3000
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Configuration loaded", "config", cfg.String())
+	db := config.ConnectToDB(cfg)
+
+	// madang_api migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(db, os.Args[2:])
+		return
+	}
+	// madang_api seed [-size small|medium|large] [-seed n] [-reset]
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		runSeed(cfg, db, os.Args[2:])
+		return
+	}
+	if pending, err := migrations.Pending(db); err != nil {
+		slog.Error("Failed to check migrations", "error", err)
+	} else if len(pending) > 0 {
+		slog.Warn("Migrations are pending, run `madang_api migrate up`", "pending", len(pending))
+	}
+
+	serve(app.NewContainer(cfg, db))
 }
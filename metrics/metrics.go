@@ -0,0 +1,131 @@
+// Package metrics defines the Prometheus metrics of the API and the registry /metrics serves them from.
+// Labels only take bounded values: route templates rather than paths, the known statuses and payment
+// methods with anything else counted as "other", and restaurant IDs, of which there are at most a few thousand
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "madang"
+
+// Registry holds every metric of the application, along with the Go runtime and process ones
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests answered, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to answer HTTP requests, by method and route.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "route"})
+
+	ordersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders placed, by initial status and restaurant.",
+	}, []string{"status", "restaurant_id"})
+
+	orderStatusChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_status_changes_total",
+		Help:      "Orders moved to a new status, by that status and restaurant.",
+	}, []string{"status", "restaurant_id"})
+
+	payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Payments recorded or moved to a new status, by method, status and restaurant.",
+	}, []string{"method", "status", "restaurant_id"})
+
+	otpVerifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_verifications_total",
+		Help:      "Email OTP verifications, by outcome.",
+	}, []string{"outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, ordersCreated, orderStatusChanges, payments, otpVerifications,
+	)
+}
+
+// RegisterDB exposes the statistics of the database connection pool
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "madang"))
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records an answered HTTP request. route is the template the request matched, empty when
+// it matched none
+func ObserveRequest(method string, route string, status int, seconds float64) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(seconds)
+}
+
+// OrderCreated records an order placed at the restaurant
+func OrderCreated(status string, restaurantID uint) {
+	ordersCreated.WithLabelValues(known(status, orderStatuses), restaurantLabel(restaurantID)).Inc()
+}
+
+// OrderStatusChanged records an order of the restaurant moved to the status
+func OrderStatusChanged(status string, restaurantID uint) {
+	orderStatusChanges.WithLabelValues(known(status, orderStatuses), restaurantLabel(restaurantID)).Inc()
+}
+
+// PaymentRecorded records a payment created or moved to a new status
+func PaymentRecorded(method string, status string, restaurantID uint) {
+	payments.WithLabelValues(known(method, paymentMethods), known(status, paymentStatuses), restaurantLabel(restaurantID)).Inc()
+}
+
+// Outcomes of an email OTP verification
+const (
+	OutcomeVerified     = "verified"
+	OutcomeInvalidOTP   = "invalid_otp"
+	OutcomeUnknownEmail = "unknown_email"
+)
+
+// OTPVerified records the outcome of an email OTP verification
+func OTPVerified(outcome string) {
+	otpVerifications.WithLabelValues(outcome).Inc()
+}
+
+// the statuses and methods are free text in the API, anything unknown is counted as "other"
+var (
+	orderStatuses   = map[string]bool{"pending": true, "confirmed": true, "completed": true, "cancelled": true}
+	paymentMethods  = map[string]bool{"credit_card": true, "paypal": true, "cash": true}
+	paymentStatuses = map[string]bool{"pending": true, "completed": true, "failed": true}
+)
+
+func known(value string, values map[string]bool) string {
+	if values[value] {
+		return value
+	}
+	return "other"
+}
+
+func restaurantLabel(restaurantID uint) string {
+	return strconv.FormatUint(uint64(restaurantID), 10)
+}
@@ -3,10 +3,11 @@ package middleware
 import (
 	"fmt"
 	"madang_api/config"
-	"madang_api/models"
+	"madang_api/ratelimit"
+	"madang_api/repositories"
+	"madang_api/services"
 	"madang_api/utils"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -14,10 +15,38 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(c *gin.Context) {
-	fmt.Println("Middleware running successfully")
+// Dependencies are what the middlewares look users, restaurants and permissions up with, and where the
+// rate limits keep their buckets
+type Dependencies struct {
+	Users       repositories.UserRepository
+	Restaurants repositories.RestaurantRepository
+	Orders      repositories.OrderRepository
+	Staff       *services.StaffService
+	Brands      *services.BrandService
+	RateLimits  ratelimit.Store
+}
+
+// Middleware holds what the middlewares needing the configuration or a lookup work with. Each container
+// builds its own, so several APIs in one process (such as parallel tests) do not share their settings
+type Middleware struct {
+	jwtSecret  []byte // verifies the access tokens
+	deps       Dependencies
+	rateLimits rateLimits
+	cursors    utils.CursorSigner
+}
+
+// New builds the middlewares with the parts of the configuration and the dependencies they need
+func New(cfg *config.Config, dependencies Dependencies) *Middleware {
+	return &Middleware{
+		jwtSecret:  []byte(cfg.JWTSecret),
+		deps:       dependencies,
+		rateLimits: newRateLimits(cfg),
+		cursors:    utils.NewCursorSigner(cfg.CursorSecret),
+	}
+}
 
-	//Get the token from the request header
+func (m *Middleware) AuthMiddleware(c *gin.Context) {
+	//Get the token from the request header. It is never logged
 	requestToken := c.GetHeader("Authorization")
 
 	if requestToken == "" {
@@ -25,15 +54,12 @@ func AuthMiddleware(c *gin.Context) {
 		return
 	}
 
-	tokenString := strings.Split(requestToken, "Bearer ")[1]
-	// fmt.Println(tokenString)
-	if tokenString == "" {
+	tokenString, found := strings.CutPrefix(requestToken, "Bearer ")
+	if !found || tokenString == "" {
 		utils.AbortResponse(c, http.StatusUnauthorized, "access token required")
 		return
 	}
 
-	fmt.Println(tokenString)
-
 	//Decode/validate it
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Don't forget to validate the alg is what you expect:
@@ -42,7 +68,7 @@ func AuthMiddleware(c *gin.Context) {
 		}
 
 		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte(os.Getenv("JWT_SECRET")), nil
+		return m.jwtSecret, nil
 	})
 
 	if err != nil {
@@ -53,26 +79,27 @@ func AuthMiddleware(c *gin.Context) {
 	if claims, ok := token.Claims.(jwt.MapClaims); ok {
 		//check the exp
 		if float64(time.Now().Unix()) > claims["exp"].(float64) {
-			c.AbortWithStatus(http.StatusUnauthorized)
-
+			utils.AbortResponse(c, http.StatusUnauthorized, "access token expired")
+			return
 		}
 
 		//find the user with the token
-		var user models.User
-		config.DB.First(&user, claims["sub"])
-
-		if user.ID == 0 {
-			c.AbortWithStatus(http.StatusUnauthorized)
+		subject, _ := claims["sub"].(float64)
+		user, err := m.deps.Users.FindByID(uint(subject))
+		if err != nil {
+			utils.AbortResponse(c, http.StatusUnauthorized, "invalid access token")
+			return
 		}
 
 		//Attach to the req
-		c.Set("user", user)
+		c.Set("user", *user)
+		if !m.limitUser(c, *user) {
+			return
+		}
 
 		//Continue
 		c.Next()
 	} else {
-		fmt.Println(err)
-		c.AbortWithStatus(http.StatusUnauthorized)
-
+		utils.AbortResponse(c, http.StatusUnauthorized, "invalid access token")
 	}
 }
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"madang_api/models"
+	"madang_api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireBrandOwner only lets through the owner of the brand whose ID is in the given path parameter.
+// It must run after AuthMiddleware
+func (m *Middleware) RequireBrandOwner(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loggedInUser, exists := c.Get("user")
+		if !exists {
+			utils.AbortResponse(c, http.StatusUnauthorized, "access token required")
+			return
+		}
+
+		brandID, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil || !m.deps.Brands.IsBrandOwner(c.Request.Context(), loggedInUser.(models.User), uint(brandID)) {
+			utils.AbortErrorResponse(c, http.StatusForbidden, "you are not allowed to perform this action", "Forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"madang_api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignCursors hands the lists of the request the key signing their cursors
+func (m *Middleware) SignCursors(c *gin.Context) {
+	utils.SetCursorSigner(c, m.cursors)
+	c.Next()
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"madang_api/models"
+	"madang_api/utils"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger logs every request once it is answered: errors of the server at error level, errors of the
+// client at warn level and the rest at info level. The query string is left out, it can hold tokens
+func RequestLogger(c *gin.Context) {
+	started := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("route", c.FullPath()),
+		slog.String("path", c.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Float64("latency_ms", float64(time.Since(started).Microseconds())/1000),
+		slog.String("client_ip", c.ClientIP()),
+		slog.Int("size", c.Writer.Size()),
+	}
+	if loggedInUser, exists := c.Get("user"); exists {
+		attrs = append(attrs, slog.Uint64("user_id", uint64(loggedInUser.(models.User).ID)))
+	}
+	if len(c.Errors) > 0 {
+		attrs = append(attrs, slog.String("error", c.Errors.String()))
+	}
+	slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+}
+
+// Recovery answers 500 to a request whose handler panicked and logs the panic with its stack
+var Recovery = gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+	slog.ErrorContext(c.Request.Context(), "handler panicked", "panic", recovered, "stack", string(debug.Stack()))
+	utils.AbortErrorResponse(c, http.StatusInternalServerError, "Internal server error", "Internal Server Error")
+})
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"madang_api/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts every answered request and observes its latency, labelled by the route template so the
+// IDs in the paths do not multiply the series
+func Metrics(c *gin.Context) {
+	started := time.Now()
+	c.Next()
+	metrics.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(started).Seconds())
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"madang_api/models"
+	"madang_api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOrderAccess lets through the customer who placed the order whose ID is in the given path
+// parameter, and otherwise the users the permission is granted to on the restaurant of the order.
+// It must run after AuthMiddleware
+func (m *Middleware) RequireOrderAccess(param string, permission string) gin.HandlerFunc {
+	staff := m.RequireRestaurantPermission(permission, m.RestaurantOfRecord("orders", param))
+	return func(c *gin.Context) {
+		loggedInUser, exists := c.Get("user")
+		if !exists {
+			utils.AbortResponse(c, http.StatusUnauthorized, "access token required")
+			return
+		}
+
+		orderID, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			utils.AbortErrorResponse(c, http.StatusBadRequest, "invalid "+param+" parameter", err.Error())
+			return
+		}
+		if customerID, err := m.deps.Orders.CustomerOf(uint(orderID)); err == nil && customerID == loggedInUser.(models.User).ID {
+			c.Next()
+			return
+		}
+		staff(c)
+	}
+}
+
+// RequireSelfOrAdmin only lets through the user whose ID is in the given path parameter, and admins.
+// It must run after AuthMiddleware
+func RequireSelfOrAdmin(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loggedInUser, exists := c.Get("user")
+		if !exists {
+			utils.AbortResponse(c, http.StatusUnauthorized, "access token required")
+			return
+		}
+
+		user := loggedInUser.(models.User)
+		userID, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if user.Role != "admin" && (err != nil || uint(userID) != user.ID) {
+			utils.AbortErrorResponse(c, http.StatusForbidden, "you are not allowed to perform this action", "Forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"madang_api/config"
+	"madang_api/models"
+	"madang_api/ratelimit"
+	"madang_api/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimits are the policies of the rate limits. A policy allowing no request is off
+type rateLimits struct {
+	enabled bool
+	ip      ratelimit.Limit // the requests of a client IP to one route
+	user    ratelimit.Limit // the authenticated requests of a user to one route
+	auth    ratelimit.Limit // the requests of a client IP to one /api/auth route
+}
+
+func newRateLimits(cfg *config.Config) rateLimits {
+	return rateLimits{
+		enabled: cfg.RateLimitEnabled,
+		ip:      ratelimit.Limit{Requests: int(cfg.RateLimitIPRequests), Per: cfg.RateLimitWindow},
+		user:    ratelimit.Limit{Requests: int(cfg.RateLimitUserRequests), Per: cfg.RateLimitWindow},
+		auth:    ratelimit.Limit{Requests: int(cfg.RateLimitAuthRequests), Per: cfg.RateLimitWindow},
+	}
+}
+
+// RateLimit limits the requests of every client IP to each route
+func (m *Middleware) RateLimit(c *gin.Context) {
+	if !m.allow(c, fmt.Sprintf("ip:%s:%s", c.FullPath(), c.ClientIP()), m.rateLimits.ip) {
+		return
+	}
+	c.Next()
+}
+
+// AuthRateLimit limits the requests of every client IP to each of the unauthenticated /api/auth routes,
+// far more strictly than RateLimit since they check passwords and OTPs
+func (m *Middleware) AuthRateLimit(c *gin.Context) {
+	if !m.allow(c, fmt.Sprintf("auth:%s:%s", c.FullPath(), c.ClientIP()), m.rateLimits.auth) {
+		return
+	}
+	c.Next()
+}
+
+// limitUser limits the requests of the user to each route, AuthMiddleware calls it once the user is known
+func (m *Middleware) limitUser(c *gin.Context, user models.User) bool {
+	return m.allow(c, fmt.Sprintf("user:%s:%d", c.FullPath(), user.ID), m.rateLimits.user)
+}
+
+// allow takes a token of the bucket of key. When there is none it answers 429 and returns false.
+// Requests are let through when the store fails, so an outage of Redis does not take the API down
+func (m *Middleware) allow(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	if !m.rateLimits.enabled || limit.Requests <= 0 || m.deps.RateLimits == nil {
+		return true
+	}
+	result, err := m.deps.RateLimits.Take(c.Request.Context(), key, limit)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Rate limit store failed, request let through", "error", err)
+		return true
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	if !result.Allowed {
+		utils.TooManyRequests(c, result.RetryAfter, "too many requests, slow down")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"madang_api/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request from the client or the proxy in front of the API, and back
+// in the response
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID keeps the X-Request-ID of the request, or assigns one when it is missing or unusable, and puts it
+// in the request context for the logs and the error responses
+func RequestID(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = newRequestID()
+	}
+
+	c.Header(RequestIDHeader, requestID)
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+	c.Next()
+}
+
+// validRequestID accepts the IDs made of at most 128 printable ASCII characters, so a client cannot inject
+// anything into the logs through them
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
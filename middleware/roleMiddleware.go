@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"madang_api/models"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through users whose role is one of the given roles. It must run after AuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loggedInUser, exists := c.Get("user")
+		if !exists {
+			utils.AbortResponse(c, http.StatusUnauthorized, "access token required")
+			return
+		}
+
+		role := loggedInUser.(models.User).Role
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		utils.AbortErrorResponse(c, http.StatusForbidden, "you are not allowed to perform this action", "Forbidden")
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"madang_api/models"
+	"madang_api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RestaurantResolver works out which restaurant a request acts on
+type RestaurantResolver func(c *gin.Context) (uint, error)
+
+// RestaurantFromParam reads the restaurant ID from a path parameter
+func RestaurantFromParam(param string) RestaurantResolver {
+	return func(c *gin.Context) (uint, error) {
+		id, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			return 0, errors.New("invalid " + param + " parameter")
+		}
+		return uint(id), nil
+	}
+}
+
+// RestaurantFromBody reads the restaurant_id field of a JSON body, leaving the body in place for the handler
+func RestaurantFromBody() RestaurantResolver {
+	return func(c *gin.Context) (uint, error) {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return 0, err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+		var body struct {
+			RestaurantID uint `json:"restaurant_id"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil || body.RestaurantID == 0 {
+			return 0, errors.New("restaurant_id is required")
+		}
+		return body.RestaurantID, nil
+	}
+}
+
+// RestaurantOfRecord looks up the restaurant of the record whose ID is in the given path parameter
+func (m *Middleware) RestaurantOfRecord(table string, param string) RestaurantResolver {
+	return func(c *gin.Context) (uint, error) {
+		id, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			return 0, errors.New("invalid " + param + " parameter")
+		}
+		return m.deps.Restaurants.RestaurantOf(table, uint(id))
+	}
+}
+
+// RequireRestaurantPermission only lets through users whose staff role on the resolved restaurant grants
+// the permission. It must run after AuthMiddleware
+func (m *Middleware) RequireRestaurantPermission(permission string, resolve RestaurantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loggedInUser, exists := c.Get("user")
+		if !exists {
+			utils.AbortResponse(c, http.StatusUnauthorized, "access token required")
+			return
+		}
+
+		restaurantID, err := resolve(c)
+		if err != nil {
+			utils.AbortErrorResponse(c, http.StatusBadRequest, "could not determine the restaurant", err.Error())
+			return
+		}
+
+		allowed, err := m.deps.Staff.HasPermission(c.Request.Context(), loggedInUser.(models.User), restaurantID, permission)
+		if err != nil || !allowed {
+			utils.AbortErrorResponse(c, http.StatusForbidden, "you are not allowed to perform this action", "Forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
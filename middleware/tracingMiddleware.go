@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"madang_api/logging"
+	"madang_api/models"
+	"madang_api/tracing"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts the server span of every request, continuing the trace of the caller when the request
+// carries a traceparent header. The handlers, services and queries run under it through the context of
+// the request. Requests answered with a server error mark the span failed
+func Tracing(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+	route := c.FullPath()
+	name := c.Request.Method + " " + route
+	if route == "" {
+		name = c.Request.Method
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(c.Request.Method),
+		semconv.HTTPRoute(route),
+		semconv.URLPath(c.Request.URL.Path),
+		semconv.ClientAddress(c.ClientIP()),
+		attribute.String("request_id", logging.RequestID(ctx)),
+	))
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if loggedInUser, exists := c.Get("user"); exists {
+		span.SetAttributes(attribute.Int64("user.id", int64(loggedInUser.(models.User).ID)))
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	if len(c.Errors) > 0 {
+		span.RecordError(c.Errors.Last())
+	}
+}
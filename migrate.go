@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"madang_api/migrations"
+	"os"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: madang_api migrate up|down [steps]|status"
+
+// runMigrate runs the migrate subcommand: up applies the pending migrations, down rolls back the last one
+// (or the last steps ones) and status lists them
+func runMigrate(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(db)
+		for _, migration := range applied {
+			log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Println("Database is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatal(migrateUsage)
+			}
+		}
+		rolledBack, err := migrations.Down(db, steps)
+		for _, migration := range rolledBack {
+			log.Printf("Rolled back migration %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			log.Println("No migration to roll back")
+		}
+	case "status":
+		statuses, err := migrations.GetStatus(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%-30s %s\n", status.Version, status.Name, applied)
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -0,0 +1,31 @@
+DROP TABLE IF EXISTS
+    ratings,
+    recommendations,
+    branch_overrides,
+    brand_addons,
+    brand_foods,
+    restaurant_staff,
+    notifications,
+    restaurant_status_histories,
+    verification_documents,
+    restaurant_verifications,
+    opening_exceptions,
+    opening_intervals,
+    stock_adjustments,
+    addon_ingredients,
+    food_ingredients,
+    ingredients,
+    transactions,
+    payments,
+    addon_orders,
+    table_orders,
+    food_orders,
+    orders,
+    table_addons,
+    addons,
+    tables,
+    foods,
+    categories,
+    restaurants,
+    brands,
+    users;
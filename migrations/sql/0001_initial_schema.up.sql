@@ -0,0 +1,376 @@
+-- Schema of the models as of the switch from AutoMigrate to migrations. Tables are created only when
+-- missing so databases built by AutoMigrate can be brought under migrations as they are.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text,
+    email text,
+    password text,
+    phone text,
+    role text,
+    avatar text,
+    active boolean,
+    token text,
+    device_id text,
+    device_token text,
+    email_verified boolean,
+    email_verification_otp text
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS brands (
+    id bigserial PRIMARY KEY,
+    name text,
+    description text,
+    image text,
+    user_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_brands_name ON brands (name);
+
+CREATE TABLE IF NOT EXISTS restaurants (
+    id bigserial PRIMARY KEY,
+    name text,
+    address text,
+    user_id bigint CONSTRAINT fk_users_restaurants REFERENCES users (id),
+    brand_id bigint CONSTRAINT fk_brands_restaurants REFERENCES brands (id),
+    phone text,
+    email text,
+    website text,
+    location text,
+    latitude decimal,
+    longitude decimal,
+    state text,
+    country text,
+    image text,
+    opening_hours text,
+    closing_hours text,
+    timezone text DEFAULT 'UTC',
+    active boolean,
+    verified boolean,
+    verified_at timestamptz,
+    verification_status text DEFAULT 'unverified',
+    average_rating decimal,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_restaurants_coordinates ON restaurants (latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_restaurants_brand_id ON restaurants (brand_id);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text NOT NULL,
+    type text NOT NULL,
+    restaurant_id bigint
+);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS foods (
+    id bigserial PRIMARY KEY,
+    name text,
+    description text,
+    image text,
+    price decimal,
+    restaurant_id bigint CONSTRAINT fk_restaurants_foods REFERENCES restaurants (id),
+    category_id bigint,
+    average_rating decimal,
+    available boolean DEFAULT true,
+    brand_food_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_foods_brand_food_id ON foods (brand_food_id);
+
+CREATE TABLE IF NOT EXISTS tables (
+    id bigserial PRIMARY KEY,
+    name text,
+    number bigint,
+    capacity bigint,
+    image text,
+    price decimal,
+    average_rating decimal,
+    created_at timestamptz,
+    updated_at timestamptz,
+    restaurant_id bigint CONSTRAINT fk_restaurants_tables REFERENCES restaurants (id),
+    category_id bigint
+);
+
+CREATE TABLE IF NOT EXISTS addons (
+    id bigserial PRIMARY KEY,
+    name text,
+    type text,
+    price decimal,
+    restaurant_id bigint CONSTRAINT fk_restaurants_addons REFERENCES restaurants (id),
+    brand_addon_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_addons_brand_addon_id ON addons (brand_addon_id);
+
+CREATE TABLE IF NOT EXISTS table_addons (
+    table_id bigint CONSTRAINT fk_table_addons_table REFERENCES tables (id),
+    addon_id bigint CONSTRAINT fk_table_addons_addon REFERENCES addons (id),
+    PRIMARY KEY (table_id, addon_id)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id bigserial PRIMARY KEY,
+    user_id bigint CONSTRAINT fk_users_orders REFERENCES users (id),
+    restaurant_id bigint,
+    table_id bigint,
+    total_price decimal,
+    status text,
+    special_notes text,
+    expected_ready timestamptz,
+    stock_deducted boolean,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_orders_restaurant_feed ON orders (restaurant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_user_feed ON orders (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS food_orders (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL CONSTRAINT fk_orders_food_orders REFERENCES orders (id),
+    food_id bigint NOT NULL CONSTRAINT fk_food_orders_food REFERENCES foods (id),
+    quantity bigint
+);
+
+CREATE TABLE IF NOT EXISTS table_orders (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL CONSTRAINT fk_orders_table_orders REFERENCES orders (id),
+    table_id bigint NOT NULL CONSTRAINT fk_table_orders_table REFERENCES tables (id)
+);
+
+CREATE TABLE IF NOT EXISTS addon_orders (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL CONSTRAINT fk_orders_addon_orders REFERENCES orders (id),
+    addon_id bigint NOT NULL CONSTRAINT fk_addon_orders_addon REFERENCES addons (id),
+    quantity bigint
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    order_id bigint,
+    amount decimal,
+    method text,
+    status text,
+    restaurant_id bigint
+);
+CREATE INDEX IF NOT EXISTS idx_payments_deleted_at ON payments (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_payments_restaurant_feed ON payments (restaurant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    order_id bigint,
+    payment_id bigint,
+    status text,
+    amount decimal,
+    restaurant_id bigint
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_restaurant_feed ON transactions (restaurant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ingredients (
+    id bigserial PRIMARY KEY,
+    name text,
+    unit text,
+    quantity decimal,
+    low_stock_threshold decimal,
+    restaurant_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS food_ingredients (
+    id bigserial PRIMARY KEY,
+    food_id bigint NOT NULL,
+    ingredient_id bigint NOT NULL CONSTRAINT fk_food_ingredients_ingredient REFERENCES ingredients (id),
+    quantity decimal
+);
+
+CREATE TABLE IF NOT EXISTS addon_ingredients (
+    id bigserial PRIMARY KEY,
+    addon_id bigint NOT NULL,
+    ingredient_id bigint NOT NULL CONSTRAINT fk_addon_ingredients_ingredient REFERENCES ingredients (id),
+    quantity decimal
+);
+
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id bigserial PRIMARY KEY,
+    ingredient_id bigint NOT NULL,
+    change decimal,
+    quantity_after decimal,
+    reason text,
+    order_id bigint,
+    user_id bigint,
+    created_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS opening_intervals (
+    id bigserial PRIMARY KEY,
+    restaurant_id bigint NOT NULL CONSTRAINT fk_restaurants_opening_intervals REFERENCES restaurants (id),
+    day_of_week bigint,
+    opens_at text,
+    closes_at text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_opening_intervals_restaurant_id ON opening_intervals (restaurant_id);
+
+CREATE TABLE IF NOT EXISTS opening_exceptions (
+    id bigserial PRIMARY KEY,
+    restaurant_id bigint NOT NULL CONSTRAINT fk_restaurants_opening_exceptions REFERENCES restaurants (id),
+    date text,
+    closed boolean,
+    opens_at text,
+    closes_at text,
+    reason text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_opening_exceptions_restaurant_id ON opening_exceptions (restaurant_id);
+
+CREATE TABLE IF NOT EXISTS restaurant_verifications (
+    id bigserial PRIMARY KEY,
+    restaurant_id bigint NOT NULL CONSTRAINT fk_restaurant_verifications_restaurant REFERENCES restaurants (id),
+    submitted_by bigint,
+    registration_number text,
+    tax_id text,
+    notes text,
+    status text,
+    reviewed_by bigint,
+    reviewed_at timestamptz,
+    reason text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_restaurant_verifications_restaurant_id ON restaurant_verifications (restaurant_id);
+CREATE INDEX IF NOT EXISTS idx_restaurant_verifications_status ON restaurant_verifications (status);
+
+CREATE TABLE IF NOT EXISTS verification_documents (
+    id bigserial PRIMARY KEY,
+    verification_id bigint NOT NULL CONSTRAINT fk_restaurant_verifications_documents REFERENCES restaurant_verifications (id),
+    type text,
+    url text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_verification_documents_verification_id ON verification_documents (verification_id);
+
+CREATE TABLE IF NOT EXISTS restaurant_status_histories (
+    id bigserial PRIMARY KEY,
+    restaurant_id bigint NOT NULL,
+    verification_id bigint,
+    from_status text,
+    to_status text,
+    changed_by bigint,
+    reason text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_restaurant_status_histories_restaurant_id ON restaurant_status_histories (restaurant_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    type text,
+    title text,
+    message text,
+    read_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+
+CREATE TABLE IF NOT EXISTS restaurant_staff (
+    id bigserial PRIMARY KEY,
+    restaurant_id bigint NOT NULL,
+    user_id bigint,
+    email text,
+    role text,
+    status text,
+    invite_token text,
+    invited_by bigint,
+    accepted_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_restaurant_staff_restaurant_id ON restaurant_staff (restaurant_id);
+CREATE INDEX IF NOT EXISTS idx_restaurant_staff_user_id ON restaurant_staff (user_id);
+CREATE INDEX IF NOT EXISTS idx_restaurant_staff_email ON restaurant_staff (email);
+CREATE INDEX IF NOT EXISTS idx_restaurant_staff_invite_token ON restaurant_staff (invite_token);
+
+CREATE TABLE IF NOT EXISTS brand_foods (
+    id bigserial PRIMARY KEY,
+    brand_id bigint NOT NULL CONSTRAINT fk_brands_foods REFERENCES brands (id),
+    name text,
+    description text,
+    image text,
+    price decimal,
+    category text,
+    category_type text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_brand_foods_brand_id ON brand_foods (brand_id);
+
+CREATE TABLE IF NOT EXISTS brand_addons (
+    id bigserial PRIMARY KEY,
+    brand_id bigint NOT NULL CONSTRAINT fk_brands_addons REFERENCES brands (id),
+    name text,
+    type text,
+    price decimal,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_brand_addons_brand_id ON brand_addons (brand_id);
+
+CREATE TABLE IF NOT EXISTS branch_overrides (
+    id bigserial PRIMARY KEY,
+    restaurant_id bigint NOT NULL,
+    brand_food_id bigint,
+    brand_addon_id bigint,
+    price decimal,
+    available boolean,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_branch_overrides_restaurant_id ON branch_overrides (restaurant_id);
+CREATE INDEX IF NOT EXISTS idx_branch_overrides_brand_food_id ON branch_overrides (brand_food_id);
+CREATE INDEX IF NOT EXISTS idx_branch_overrides_brand_addon_id ON branch_overrides (brand_addon_id);
+
+CREATE TABLE IF NOT EXISTS recommendations (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    restaurant_id bigint,
+    item_type text,
+    item_id bigint,
+    score decimal,
+    reason text,
+    computed_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_recommendations_lookup ON recommendations (user_id, restaurant_id, item_type);
+
+CREATE TABLE IF NOT EXISTS ratings (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint CONSTRAINT fk_users_ratings REFERENCES users (id),
+    food_id bigint CONSTRAINT fk_foods_ratings REFERENCES foods (id),
+    restaurant_id bigint CONSTRAINT fk_restaurants_ratings REFERENCES restaurants (id),
+    score bigint,
+    comment text
+);
+CREATE INDEX IF NOT EXISTS idx_ratings_deleted_at ON ratings (deleted_at);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS
+    idx_foods_search,
+    idx_foods_name_trgm,
+    idx_tables_search,
+    idx_tables_name_trgm,
+    idx_restaurants_search,
+    idx_restaurants_name_trgm,
+    idx_categories_search,
+    idx_categories_name_search,
+    idx_categories_name_trgm,
+    idx_addons_search,
+    idx_addons_name_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
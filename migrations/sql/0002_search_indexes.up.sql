@@ -0,0 +1,15 @@
+-- Full-text (tsvector) and trigram indexes used by the search queries. The index expressions must
+-- stay identical to the ones in repositories/search.go or Postgres will not use them.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_foods_search ON foods USING GIN ((setweight(to_tsvector('simple', coalesce(name, '')), 'A') || setweight(to_tsvector('simple', coalesce(description, '')), 'B')));
+CREATE INDEX IF NOT EXISTS idx_foods_name_trgm ON foods USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_tables_search ON tables USING GIN (setweight(to_tsvector('simple', coalesce(name, '')), 'A'));
+CREATE INDEX IF NOT EXISTS idx_tables_name_trgm ON tables USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_restaurants_search ON restaurants USING GIN ((setweight(to_tsvector('simple', coalesce(name, '')), 'A') || setweight(to_tsvector('simple', coalesce(address, '') || ' ' || coalesce(location, '')), 'B') || setweight(to_tsvector('simple', coalesce(state, '') || ' ' || coalesce(country, '')), 'C')));
+CREATE INDEX IF NOT EXISTS idx_restaurants_name_trgm ON restaurants USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_search ON categories USING GIN (setweight(to_tsvector('simple', coalesce(name, '')), 'C'));
+CREATE INDEX IF NOT EXISTS idx_categories_name_search ON categories USING GIN ((setweight(to_tsvector('simple', coalesce(name, '')), 'A') || setweight(to_tsvector('simple', coalesce(type, '')), 'B')));
+CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_addons_search ON addons USING GIN ((setweight(to_tsvector('simple', coalesce(name, '')), 'A') || setweight(to_tsvector('simple', coalesce(type, '')), 'B')));
+CREATE INDEX IF NOT EXISTS idx_addons_name_trgm ON addons USING GIN (name gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- Failed logins in a row and the end of the lockout they trigger, see UserService.LoginUser.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_logins integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamptz;
@@ -8,6 +8,7 @@ type Addon struct {
 	Type         string    `json:"type"` // e.g., "chair", "flower"
 	Price        float64   `json:"price"`
 	RestaurantID uint      `json:"restaurant_id"`
+	BrandAddonID *uint     `json:"brand_addon_id,omitempty" gorm:"index"` // set when the addon comes from the brand menu template
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
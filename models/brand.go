@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Brand groups the restaurants (branches) of one chain. Its owner manages every branch and its
+// menu template is pushed to all of them
+type Brand struct {
+	ID          uint         `json:"id" gorm:"primary_key"`
+	Name        string       `json:"name" gorm:"uniqueIndex"`
+	Description string       `json:"description"`
+	Image       string       `json:"image"`
+	UserID      uint         `json:"user_id"` // Brand owner's UserID
+	Foods       []BrandFood  `json:"foods,omitempty" gorm:"foreignKey:BrandID"`
+	Addons      []BrandAddon `json:"addons,omitempty" gorm:"foreignKey:BrandID"`
+	Restaurants []Restaurant `json:"restaurants,omitempty" gorm:"foreignKey:BrandID"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// BrandFood is a food of a brand's menu template. Every branch gets a Food linked to it through BrandFoodID
+type BrandFood struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	BrandID      uint      `json:"brand_id" gorm:"not null;index"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Image        string    `json:"image"`
+	Price        float64   `json:"price"`
+	Category     string    `json:"category"`
+	CategoryType string    `json:"category_type"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BrandAddon is an addon of a brand's menu template. Every branch gets an Addon linked to it through BrandAddonID
+type BrandAddon struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	BrandID   uint      `json:"brand_id" gorm:"not null;index"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Price     float64   `json:"price"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BranchOverride lets a branch change the price or availability of one template item.
+// Exactly one of BrandFoodID and BrandAddonID is set; nil fields keep the template value
+type BranchOverride struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	BrandFoodID  *uint     `json:"brand_food_id,omitempty" gorm:"index"`
+	BrandAddonID *uint     `json:"brand_addon_id,omitempty" gorm:"index"`
+	Price        *float64  `json:"price"`
+	Available    *bool     `json:"available"` // only applies to foods
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -12,6 +12,8 @@ type Food struct {
 	CategoryId    uint      `json:"category_id"`
 	Ratings       []Rating  `json:"ratings" gorm:"foreignKey:FoodID"`
 	AverageRating float64   `json:"average_rating"`
+	Available     bool      `json:"available" gorm:"default:true"`
+	BrandFoodID   *uint     `json:"brand_food_id,omitempty" gorm:"index"` // set when the food comes from the brand menu template
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
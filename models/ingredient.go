@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type Ingredient struct {
+	ID                uint      `json:"id" gorm:"primary_key"`
+	Name              string    `json:"name"`
+	Unit              string    `json:"unit"` // e.g., "g", "ml", "piece"
+	Quantity          float64   `json:"quantity"`
+	LowStockThreshold float64   `json:"low_stock_threshold"`
+	RestaurantID      uint      `json:"restaurant_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// FoodIngredient is a recipe line: how much of an ingredient one portion of a food uses
+type FoodIngredient struct {
+	ID           uint       `json:"id" gorm:"primary_key"`
+	FoodID       uint       `json:"food_id" gorm:"not null"`
+	IngredientID uint       `json:"ingredient_id" gorm:"not null"`
+	Ingredient   Ingredient `json:"ingredient" gorm:"foreignKey:IngredientID"`
+	Quantity     float64    `json:"quantity"`
+}
+
+// AddonIngredient is a recipe line: how much of an ingredient one unit of an addon uses
+type AddonIngredient struct {
+	ID           uint       `json:"id" gorm:"primary_key"`
+	AddonID      uint       `json:"addon_id" gorm:"not null"`
+	IngredientID uint       `json:"ingredient_id" gorm:"not null"`
+	Ingredient   Ingredient `json:"ingredient" gorm:"foreignKey:IngredientID"`
+	Quantity     float64    `json:"quantity"`
+}
+
+// StockAdjustment is the audit history of every change made to an ingredient's stock
+type StockAdjustment struct {
+	ID            uint      `json:"id" gorm:"primary_key"`
+	IngredientID  uint      `json:"ingredient_id" gorm:"not null"`
+	Change        float64   `json:"change"` // positive for restocks, negative for usage
+	QuantityAfter float64   `json:"quantity_after"`
+	Reason        string    `json:"reason"` // e.g., "restock", "waste", "order_confirmed", "order_cancelled"
+	OrderID       *uint     `json:"order_id,omitempty"`
+	UserID        *uint     `json:"user_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Type      string     `json:"type"` // e.g., "verification_approved", "verification_rejected"
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OpeningInterval is one opening period of a restaurant's weekly schedule. A day can have several
+// (e.g. a lunch and a dinner shift). When ClosesAt is not after OpensAt the interval runs past midnight
+type OpeningInterval struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	DayOfWeek    int       `json:"day_of_week"` // 0 = Sunday ... 6 = Saturday
+	OpensAt      string    `json:"opens_at"`    // "HH:MM" in the restaurant's timezone
+	ClosesAt     string    `json:"closes_at"`   // "HH:MM" in the restaurant's timezone
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// OpeningException replaces the weekly schedule on a specific date, e.g. a holiday closure or special hours.
+// A date can have several exception rows for split shifts; a Closed row closes the restaurant for the whole day
+type OpeningException struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	Date         string    `json:"date"` // "YYYY-MM-DD" in the restaurant's timezone
+	Closed       bool      `json:"closed"`
+	OpensAt      string    `json:"opens_at,omitempty"`
+	ClosesAt     string    `json:"closes_at,omitempty"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -27,8 +27,8 @@ type AddonOrder struct {
 
 type Order struct {
 	ID            uint         `json:"id" gorm:"primary_key"`
-	UserID        uint         `json:"user_id"`
-	RestaurantID  uint         `json:"restaurant_id"`
+	UserID        uint         `json:"user_id" gorm:"index:idx_orders_user_feed,priority:1"`
+	RestaurantID  uint         `json:"restaurant_id" gorm:"index:idx_orders_restaurant_feed,priority:1"`
 	TableID       *uint        `json:"table_id,omitempty"`
 	FoodOrders    []FoodOrder  `json:"food_orders" gorm:"foreignKey:OrderID"`
 	TableOrders   []TableOrder `json:"table_orders" gorm:"foreignKey:OrderID"`
@@ -37,6 +37,7 @@ type Order struct {
 	Status        string       `json:"status" default:"pending"`
 	SpecialNotes  string       `json:"special_notes,omitempty"`
 	ExpectedReady *time.Time   `json:"expected_ready,omitempty"`
-	CreatedAt     time.Time    `json:"created_at"`
+	StockDeducted bool         `json:"-"` // set once ingredients have been taken out of stock for this order
+	CreatedAt     time.Time    `json:"created_at" gorm:"index:idx_orders_user_feed,priority:2;index:idx_orders_restaurant_feed,priority:2"`
 	UpdatedAt     time.Time    `json:"updated_at"`
 }
@@ -12,7 +12,7 @@ type Payment struct {
 	Amount       float64   `json:"amount"`
 	Method       string    `json:"method"` // e.g., "credit_card", "paypal"
 	Status       string    `json:"status"` // e.g., "pending", "completed", "failed"
-	RestaurantID uint      `json:"restaurant_id"`
-	CreatedAt    time.Time `json:"created_at"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"index:idx_payments_restaurant_feed,priority:1"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index:idx_payments_restaurant_feed,priority:2"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Recommendation is a precomputed score of a food or table for a user. Rows with UserID 0 are the
+// recommendations for users without any history at the restaurant
+type Recommendation struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	UserID       uint      `json:"user_id" gorm:"index:idx_recommendations_lookup"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"index:idx_recommendations_lookup"`
+	ItemType     string    `json:"item_type" gorm:"index:idx_recommendations_lookup"` // "food" or "table"
+	ItemID       uint      `json:"item_id"`
+	Score        float64   `json:"score"`
+	Reason       string    `json:"reason"` // "ordered_before", "ordered_together", "popular" or "top_rated"
+	ComputedAt   time.Time `json:"computed_at"`
+}
@@ -3,27 +3,36 @@ package models
 import "time"
 
 type Restaurant struct {
-	ID            uint      `json:"id" gorm:"primary_key"`
-	Name          string    `json:"name"`
-	Address       string    `json:"address"`
-	UserID        uint      `json:"user_id"` // Manager's UserID
-	Phone         string    `json:"phone"`
-	Email         string    `json:"email"`
-	Website       string    `json:"website"`
-	Location      string    `json:"location"`
-	State         string    `json:"state"`
-	Country       string    `json:"country"`
-	Image         string    `json:"image"`
-	OpeningHours  string    `json:"opening_hours"`
-	ClosingHours  string    `json:"closing_hours"`
-	Active        bool      `json:"active"`
-	Verified      bool      `json:"verified"`
-	VerfiedAt     string    `json:"verified_at"`
-	Foods         []Food    `json:"foods" gorm:"foreignKey:RestaurantID"`
-	Tables        []Table   `json:"tables" gorm:"foreignKey:RestaurantID"`
-	Addons        []Addon   `json:"addons" gorm:"foreignKey:RestaurantID"`
-	Ratings       []Rating  `json:"ratings" gorm:"foreignKey:RestaurantID"`
-	AverageRating float64   `json:"average_rating"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID                 uint               `json:"id" gorm:"primary_key"`
+	Name               string             `json:"name"`
+	Address            string             `json:"address"`
+	UserID             uint               `json:"user_id"` // Manager's UserID
+	BrandID            *uint              `json:"brand_id,omitempty" gorm:"index"`
+	Phone              string             `json:"phone"`
+	Email              string             `json:"email"`
+	Website            string             `json:"website"`
+	Location           string             `json:"location"`
+	Latitude           *float64           `json:"latitude" gorm:"index:idx_restaurants_coordinates"`
+	Longitude          *float64           `json:"longitude" gorm:"index:idx_restaurants_coordinates"`
+	State              string             `json:"state"`
+	Country            string             `json:"country"`
+	Image              string             `json:"image"`
+	OpeningHours       string             `json:"opening_hours"`
+	ClosingHours       string             `json:"closing_hours"`
+	Timezone           string             `json:"timezone" gorm:"default:UTC"`
+	OpeningIntervals   []OpeningInterval  `json:"opening_intervals" gorm:"foreignKey:RestaurantID"`
+	OpeningExceptions  []OpeningException `json:"opening_exceptions" gorm:"foreignKey:RestaurantID"`
+	OpenNow            bool               `json:"open_now" gorm:"-"`
+	NextOpening        *time.Time         `json:"next_opening,omitempty" gorm:"-"`
+	Active             bool               `json:"active"`
+	Verified           bool               `json:"verified"`
+	VerifiedAt         *time.Time         `json:"verified_at"`
+	VerificationStatus string             `json:"verification_status" gorm:"default:unverified"` // "unverified", "pending", "approved" or "rejected"
+	Foods              []Food             `json:"foods" gorm:"foreignKey:RestaurantID"`
+	Tables             []Table            `json:"tables" gorm:"foreignKey:RestaurantID"`
+	Addons             []Addon            `json:"addons" gorm:"foreignKey:RestaurantID"`
+	Ratings            []Rating           `json:"ratings" gorm:"foreignKey:RestaurantID"`
+	AverageRating      float64            `json:"average_rating"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
 }
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RestaurantStaff is a user's membership of a restaurant's team. It starts as an email invitation
+// and is linked to the user once they accept it
+type RestaurantStaff struct {
+	ID           uint       `json:"id" gorm:"primary_key"`
+	RestaurantID uint       `json:"restaurant_id" gorm:"not null;index"`
+	UserID       *uint      `json:"user_id,omitempty" gorm:"index"`
+	Email        string     `json:"email" gorm:"index"`
+	Role         string     `json:"role"`   // "owner", "manager", "waiter", "chef" or "cashier"
+	Status       string     `json:"status"` // "invited" or "active"
+	InviteToken  string     `json:"-" gorm:"index"`
+	InvitedBy    uint       `json:"invited_by"`
+	AcceptedAt   *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (RestaurantStaff) TableName() string {
+	return "restaurant_staff"
+}
@@ -12,7 +12,7 @@ type Transaction struct {
 	PaymentID    uint      `json:"payment_id"`
 	Status       string    `json:"status"` // e.g., "initiated", "completed", "failed"
 	Amount       float64   `json:"amount"`
-	RestaurantID uint      `json:"restaurant_id"`
-	CreatedAt    time.Time `json:"created_at"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"index:idx_transactions_restaurant_feed,priority:1"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index:idx_transactions_restaurant_feed,priority:2"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -20,6 +20,8 @@ type User struct {
 	DeviceToken          string       `json:"device_token"`
 	EmailVerified        bool         `json:"email_verified"`
 	EmailVerificationOTP string       `json:"-"`
+	FailedLogins         int          `json:"-" gorm:"not null;default:0"` // failed logins in a row
+	LockedUntil          *time.Time   `json:"-"`                           // logins are refused until then
 	Restaurants          []Restaurant `json:"restaurants" gorm:"foreignKey:UserID"`
 	Orders               []Order      `json:"orders" gorm:"foreignKey:UserID"`
 	Ratings              []Rating     `json:"ratings" gorm:"foreignKey:UserID"`
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// RestaurantVerification is a manager's request to have a restaurant verified and the admin decision on it
+type RestaurantVerification struct {
+	ID                 uint                   `json:"id" gorm:"primary_key"`
+	RestaurantID       uint                   `json:"restaurant_id" gorm:"not null;index"`
+	SubmittedBy        uint                   `json:"submitted_by"` // Manager's UserID
+	RegistrationNumber string                 `json:"registration_number"`
+	TaxID              string                 `json:"tax_id"`
+	Notes              string                 `json:"notes"`
+	Documents          []VerificationDocument `json:"documents" gorm:"foreignKey:VerificationID"`
+	Status             string                 `json:"status" gorm:"index"` // "pending", "approved" or "rejected"
+	ReviewedBy         *uint                  `json:"reviewed_by,omitempty"`
+	ReviewedAt         *time.Time             `json:"reviewed_at,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Restaurant         Restaurant             `json:"restaurant" gorm:"foreignKey:RestaurantID"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+}
+
+type VerificationDocument struct {
+	ID             uint      `json:"id" gorm:"primary_key"`
+	VerificationID uint      `json:"verification_id" gorm:"not null;index"`
+	Type           string    `json:"type"` // e.g., "business_license", "food_safety_certificate"
+	URL            string    `json:"url"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RestaurantStatusHistory records every change of a restaurant's verification status or activation
+type RestaurantStatusHistory struct {
+	ID             uint      `json:"id" gorm:"primary_key"`
+	RestaurantID   uint      `json:"restaurant_id" gorm:"not null;index"`
+	VerificationID *uint     `json:"verification_id,omitempty"`
+	FromStatus     string    `json:"from_status"`
+	ToStatus       string    `json:"to_status"`
+	ChangedBy      uint      `json:"changed_by"`
+	Reason         string    `json:"reason"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a fake time the store reads, moved forward by the tests
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = c.Now
+	store.lastSweep = c.now
+	return store, c
+}
+
+func takeToken(t *testing.T, store *MemoryStore, key string, limit Limit) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("taking a token of %s: %v", key, err)
+	}
+	return result
+}
+
+func TestMemoryStoreSpendsTheBucket(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 3, Per: time.Minute}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result := takeToken(t, store, "a", limit)
+		if !result.Allowed || result.Remaining != remaining || result.RetryAfter != 0 {
+			t.Fatalf("expected an allowed request with %d tokens left, got %+v", remaining, result)
+		}
+	}
+	if result := takeToken(t, store, "a", limit); result.Allowed {
+		t.Fatalf("expected the empty bucket to refuse the request, got %+v", result)
+	}
+
+	// every key has its own bucket
+	if result := takeToken(t, store, "b", limit); !result.Allowed {
+		t.Fatalf("expected the bucket of another key to be full, got %+v", result)
+	}
+}
+
+func TestMemoryStoreRetryAfter(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 2, Per: time.Minute} // a token every 30 seconds
+
+	takeToken(t, store, "a", limit)
+	takeToken(t, store, "a", limit)
+	result := takeToken(t, store, "a", limit)
+	if result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Fatalf("expected to retry in 30s, got %+v", result)
+	}
+}
+
+func TestMemoryStoreRefills(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Requests: 2, Per: time.Minute}
+
+	takeToken(t, store, "a", limit)
+	takeToken(t, store, "a", limit)
+
+	clock.Advance(20 * time.Second)
+	result := takeToken(t, store, "a", limit)
+	if result.Allowed || result.RetryAfter != 10*time.Second {
+		t.Fatalf("expected two thirds of a token and to retry in 10s, got %+v", result)
+	}
+
+	clock.Advance(10 * time.Second)
+	if result := takeToken(t, store, "a", limit); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("expected a token after 30s, got %+v", result)
+	}
+
+	// a bucket never holds more than the limit
+	clock.Advance(time.Hour)
+	if result := takeToken(t, store, "a", limit); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("expected a full bucket, got %+v", result)
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Requests: 2, Per: time.Hour}
+
+	takeToken(t, store, "idle", Limit{Requests: 2, Per: time.Second})
+	takeToken(t, store, "busy", limit)
+
+	// the next take after the sweep interval drops the buckets that have refilled
+	clock.Advance(sweepInterval + time.Second)
+	takeToken(t, store, "other", limit)
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("expected the refilled bucket to be swept")
+	}
+	if _, ok := store.buckets["busy"]; !ok {
+		t.Error("expected the bucket still refilling to be kept")
+	}
+	if !store.lastSweep.Equal(clock.now) {
+		t.Errorf("expected the sweep at %s, got %s", clock.now, store.lastSweep)
+	}
+
+	// a swept bucket comes back full
+	if result := takeToken(t, store, "idle", Limit{Requests: 2, Per: time.Second}); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("expected the swept bucket to start full, got %+v", result)
+	}
+}
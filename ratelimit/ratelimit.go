@@ -0,0 +1,52 @@
+// Package ratelimit throttles requests with token buckets. A bucket per key holds up to Limit.Requests
+// tokens and refills at Limit.Requests per Limit.Per; each request takes a token and is refused when
+// there is none left. The buckets live in a Store: in memory for a single instance, or in Redis to share
+// them between instances
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is the number of requests allowed per period, all of which can be spent at once
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// rate is the number of tokens the bucket gains per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the decision on one request
+type Result struct {
+	Allowed    bool
+	Remaining  int           // tokens left in the bucket
+	RetryAfter time.Duration // when the next token comes in, zero when the request was allowed
+}
+
+// Store holds the buckets. Take takes a token out of the bucket of key, creating it full when needed
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// refill returns the tokens of a bucket last updated elapsed ago, at most limit.Requests
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed <= 0 {
+		return tokens
+	}
+	return math.Min(float64(limit.Requests), tokens+elapsed.Seconds()*limit.rate())
+}
+
+// take spends a token of a bucket holding tokens and returns the tokens left with the decision
+func take(tokens float64, limit Limit) (float64, Result) {
+	if tokens >= 1 {
+		tokens--
+		return tokens, Result{Allowed: true, Remaining: int(tokens)}
+	}
+	wait := time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+	return tokens, Result{Allowed: false, RetryAfter: wait}
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisClient is the part of a Redis client the Redis store needs. Any client speaking the Redis protocol
+// can be adapted to it, e.g. with go-redis:
+//
+//	func (a adapter) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//		return a.client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisStore keeps the buckets in Redis, so every instance shares them. Each bucket is a hash updated by
+// a script, which Redis runs atomically, and expires once it would be full again
+type RedisStore struct {
+	client RedisClient
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore stores the buckets under keys starting with prefix
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix, now: time.Now}
+}
+
+// takeScript is the token bucket of the memory store. It returns whether the request is allowed, the
+// tokens left and the milliseconds until the next token
+const takeScript = `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil then
+	tokens = capacity
+	updated = now
+end
+if now > updated then
+	tokens = math.min(capacity, tokens + (now - updated) / 1000 * rate)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], ttl)
+return {allowed, math.floor(tokens), retry}
+`
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.now().UnixMilli()
+	reply, err := s.client.Eval(ctx, takeScript, []string{s.prefix + key},
+		limit.Requests, limit.rate(), now, limit.Per.Milliseconds())
+	if err != nil {
+		return Result{}, fmt.Errorf("taking a token of %s: %w", key, err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected reply %v of the rate limit script", reply)
+	}
+	numbers := make([]int64, len(values))
+	for i, value := range values {
+		if numbers[i], ok = value.(int64); !ok {
+			return Result{}, fmt.Errorf("unexpected reply %v of the rate limit script", reply)
+		}
+	}
+	return Result{
+		Allowed:    numbers[0] == 1,
+		Remaining:  int(numbers[1]),
+		RetryAfter: time.Duration(numbers[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+type AddonRepository interface {
+	Create(addon *models.Addon) error
+	Save(addon *models.Addon) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Addon, error)
+	FindByIDs(ids []uint) ([]models.Addon, error)
+	FindByName(restaurantID uint, name string) (*models.Addon, error)
+	FindByRestaurant(restaurantID uint) ([]models.Addon, error)
+	List(query *utils.ListQuery) ([]models.Addon, *utils.Pagination, error)
+	Search(query string, restaurantID uint) ([]SearchHit, error)
+	CountSearch(query string, restaurantID uint) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) AddonRepository
+}
+
+type gormAddonRepository struct {
+	restaurantRepository[models.Addon]
+}
+
+func NewAddonRepository(db *gorm.DB) AddonRepository {
+	return gormAddonRepository{restaurantRepository[models.Addon]{gormRepository[models.Addon]{db}}}
+}
+
+func (r gormAddonRepository) WithContext(ctx context.Context) AddonRepository {
+	return NewAddonRepository(r.db.WithContext(ctx))
+}
+
+func (r gormAddonRepository) Search(query string, restaurantID uint) ([]SearchHit, error) {
+	return runSearch(r.db, addonSearch, query, restaurantID)
+}
+
+func (r gormAddonRepository) CountSearch(query string, restaurantID uint) (int64, error) {
+	return countSearch(r.db, addonSearch, query, restaurantID)
+}
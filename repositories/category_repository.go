@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	Create(category *models.Category) error
+	Save(category *models.Category) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Category, error)
+	FindByIDs(ids []uint) ([]models.Category, error)
+	FindByName(restaurantID uint, name string) (*models.Category, error)
+	FindByRestaurant(restaurantID uint) ([]models.Category, error)
+	List(query *utils.ListQuery) ([]models.Category, *utils.Pagination, error)
+	Search(query string, restaurantID uint) ([]SearchHit, error)
+	CountSearch(query string, restaurantID uint) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) CategoryRepository
+}
+
+type gormCategoryRepository struct {
+	restaurantRepository[models.Category]
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return gormCategoryRepository{restaurantRepository[models.Category]{gormRepository[models.Category]{db}}}
+}
+
+func (r gormCategoryRepository) WithContext(ctx context.Context) CategoryRepository {
+	return NewCategoryRepository(r.db.WithContext(ctx))
+}
+
+func (r gormCategoryRepository) Search(query string, restaurantID uint) ([]SearchHit, error) {
+	return runSearch(r.db, categorySearch, query, restaurantID)
+}
+
+func (r gormCategoryRepository) CountSearch(query string, restaurantID uint) (int64, error) {
+	return countSearch(r.db, categorySearch, query, restaurantID)
+}
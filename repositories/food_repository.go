@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+type FoodRepository interface {
+	Create(food *models.Food) error
+	Save(food *models.Food) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Food, error)
+	FindByIDs(ids []uint) ([]models.Food, error)
+	FindByName(restaurantID uint, name string) (*models.Food, error)
+	FindByRestaurant(restaurantID uint) ([]models.Food, error)
+	List(query *utils.ListQuery) ([]models.Food, *utils.Pagination, error)
+	// BestRated returns the best rated available foods of a restaurant
+	BestRated(restaurantID uint, limit int) ([]models.Food, error)
+	Search(query string, restaurantID uint) ([]SearchHit, error)
+	CountSearch(query string, restaurantID uint) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) FoodRepository
+}
+
+type gormFoodRepository struct {
+	restaurantRepository[models.Food]
+}
+
+func NewFoodRepository(db *gorm.DB) FoodRepository {
+	return gormFoodRepository{restaurantRepository[models.Food]{gormRepository[models.Food]{db}}}
+}
+
+func (r gormFoodRepository) WithContext(ctx context.Context) FoodRepository {
+	return NewFoodRepository(r.db.WithContext(ctx))
+}
+
+func (r gormFoodRepository) BestRated(restaurantID uint, limit int) ([]models.Food, error) {
+	var foods []models.Food
+	if err := r.db.Where("restaurant_id = ? AND available = ?", restaurantID, true).Order("average_rating desc, id desc").Limit(limit).Find(&foods).Error; err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
+
+func (r gormFoodRepository) Search(query string, restaurantID uint) ([]SearchHit, error) {
+	return runSearch(r.db, foodSearch, query, restaurantID)
+}
+
+func (r gormFoodRepository) CountSearch(query string, restaurantID uint) (int64, error) {
+	return countSearch(r.db, foodSearch, query, restaurantID)
+}
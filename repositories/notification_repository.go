@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"madang_api/models"
+	"madang_api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	// FindForUser finds a notification of a user, another user's notification is not found
+	FindForUser(userID uint, id uint) (*models.Notification, error)
+	ListForUser(userID uint, query *utils.ListQuery) ([]models.Notification, *utils.Pagination, error)
+	MarkRead(notification *models.Notification, at time.Time) error
+}
+
+type gormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return gormNotificationRepository{db}
+}
+
+func (r gormNotificationRepository) FindForUser(userID uint, id uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := r.db.Where("user_id = ?", userID).First(&notification, id).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r gormNotificationRepository) ListForUser(userID uint, query *utils.ListQuery) ([]models.Notification, *utils.Pagination, error) {
+	notifications := []models.Notification{}
+	pagination, err := utils.Paginate(r.db.Model(&models.Notification{}), query.Where("user_id", userID), &notifications)
+	if err != nil {
+		return nil, nil, err
+	}
+	return notifications, pagination, nil
+}
+
+func (r gormNotificationRepository) MarkRead(notification *models.Notification, at time.Time) error {
+	if err := r.db.Model(notification).Update("read_at", at).Error; err != nil {
+		return err
+	}
+	notification.ReadAt = &at
+	return nil
+}
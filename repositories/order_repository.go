@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+// OrderRepository reads and updates orders. Placing an order or changing its status moves stock too, so
+// those run in a transaction of the order service
+type OrderRepository interface {
+	Save(order *models.Order) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Order, error)
+	List(query *utils.ListQuery) ([]models.Order, *utils.Pagination, error)
+	FindByRestaurant(restaurantID uint) ([]models.Order, error)
+	FindByUser(userID uint) ([]models.Order, error)
+	FindByStatus(status string) ([]models.Order, error)
+	SearchByName(query string) ([]models.Order, error)
+	// CustomerOf returns the ID of the user who placed an order
+	CustomerOf(id uint) (uint, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) OrderRepository
+}
+
+type gormOrderRepository struct {
+	gormRepository[models.Order]
+}
+
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return gormOrderRepository{gormRepository[models.Order]{db}}
+}
+
+func (r gormOrderRepository) WithContext(ctx context.Context) OrderRepository {
+	return NewOrderRepository(r.db.WithContext(ctx))
+}
+
+// WithOrderItems loads the foods, addons and tables of orders
+func WithOrderItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table")
+}
+
+// FindByID loads an order with its foods
+func (r gormOrderRepository) FindByID(id uint) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.Preload("FoodOrders.Food").First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r gormOrderRepository) CustomerOf(id uint) (uint, error) {
+	var userIDs []uint
+	if err := r.db.Model(&models.Order{}).Where("id = ?", id).Pluck("user_id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return userIDs[0], nil
+}
+
+// Delete deletes an order with its foods, tables and addons
+func (r gormOrderRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range []interface{}{&models.FoodOrder{}, &models.TableOrder{}, &models.AddonOrder{}} {
+			if err := tx.Where("order_id = ?", id).Delete(item).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Order{}, id).Error
+	})
+}
+
+// List returns a page of the orders matching the query with their items
+func (r gormOrderRepository) List(query *utils.ListQuery) ([]models.Order, *utils.Pagination, error) {
+	orders := []models.Order{}
+	pagination, err := utils.Paginate(r.db.Model(&models.Order{}), query, &orders, WithOrderItems)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orders, pagination, nil
+}
+
+func (r gormOrderRepository) FindByRestaurant(restaurantID uint) ([]models.Order, error) {
+	return r.findWithItems("restaurant_id = ?", restaurantID)
+}
+
+func (r gormOrderRepository) FindByUser(userID uint) ([]models.Order, error) {
+	return r.findWithItems("user_id = ?", userID)
+}
+
+func (r gormOrderRepository) FindByStatus(status string) ([]models.Order, error) {
+	return r.findWithItems("status = ?", status)
+}
+
+func (r gormOrderRepository) findWithItems(condition string, value interface{}) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Where(condition, value).Scopes(WithOrderItems).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// SearchByName finds the orders whose name or category name contains the query
+func (r gormOrderRepository) SearchByName(query string) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Where("name LIKE ? OR category_id IN (SELECT id FROM categories WHERE name LIKE ?)", "%"+query+"%", "%"+query+"%").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
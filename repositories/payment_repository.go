@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+type PaymentRepository interface {
+	Create(payment *models.Payment) error
+	Save(payment *models.Payment) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Payment, error)
+	FindByRestaurant(restaurantID uint) ([]models.Payment, error)
+	List(query *utils.ListQuery) ([]models.Payment, *utils.Pagination, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) PaymentRepository
+}
+
+type gormPaymentRepository struct {
+	restaurantRepository[models.Payment]
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return gormPaymentRepository{restaurantRepository[models.Payment]{gormRepository[models.Payment]{db}}}
+}
+
+func (r gormPaymentRepository) WithContext(ctx context.Context) PaymentRepository {
+	return NewPaymentRepository(r.db.WithContext(ctx))
+}
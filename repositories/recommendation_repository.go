@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationRepository interface {
+	// ItemIDs returns the ids of the items to recommend to a user at a restaurant: their personal
+	// recommendations first, topped up with the general ones
+	ItemIDs(userID uint, restaurantID uint, itemType string, limit int) ([]uint, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) RecommendationRepository
+}
+
+type gormRecommendationRepository struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return gormRecommendationRepository{db}
+}
+
+func (r gormRecommendationRepository) WithContext(ctx context.Context) RecommendationRepository {
+	return NewRecommendationRepository(r.db.WithContext(ctx))
+}
+
+func (r gormRecommendationRepository) ItemIDs(userID uint, restaurantID uint, itemType string, limit int) ([]uint, error) {
+	var recommendations []models.Recommendation
+	if err := r.db.Where("restaurant_id = ? AND item_type = ? AND user_id IN ?", restaurantID, itemType, []uint{userID, 0}).
+		Order("user_id DESC, score DESC, item_id").
+		Find(&recommendations).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[uint]bool)
+	ids := []uint{}
+	for _, recommendation := range recommendations {
+		if seen[recommendation.ItemID] {
+			continue
+		}
+		seen[recommendation.ItemID] = true
+		ids = append(ids, recommendation.ItemID)
+		if len(ids) == limit {
+			break
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,84 @@
+// Package repositories is the storage of the aggregates used by the services. Each aggregate has an
+// interface, so services can be tested with fakes, and a gorm implementation backed by Postgres
+package repositories
+
+import (
+	"madang_api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// gormRepository implements the operations every aggregate has
+type gormRepository[T any] struct {
+	db *gorm.DB
+}
+
+func (r gormRepository[T]) Create(item *T) error {
+	return r.db.Create(item).Error
+}
+
+func (r gormRepository[T]) Save(item *T) error {
+	return r.db.Save(item).Error
+}
+
+// Delete deletes the row with the given ID, it is not an error when there is none
+func (r gormRepository[T]) Delete(id uint) error {
+	return r.db.Delete(new(T), id).Error
+}
+
+func (r gormRepository[T]) FindByID(id uint) (*T, error) {
+	var item T
+	if err := r.db.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindByIDs loads the rows with the given IDs, in no particular order
+func (r gormRepository[T]) FindByIDs(ids []uint) ([]T, error) {
+	items := []T{}
+	if err := r.db.Where("id IN ?", ids).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// List returns a page of the rows matching the query
+func (r gormRepository[T]) List(query *utils.ListQuery) ([]T, *utils.Pagination, error) {
+	items := []T{}
+	pagination, err := utils.Paginate(r.db.Model(new(T)), query, &items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, pagination, nil
+}
+
+// restaurantRepository adds the lookups of aggregates belonging to a restaurant
+type restaurantRepository[T any] struct {
+	gormRepository[T]
+}
+
+func (r restaurantRepository[T]) FindByRestaurant(restaurantID uint) ([]T, error) {
+	var items []T
+	if err := r.db.Where("restaurant_id = ?", restaurantID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindByName finds the row of a restaurant with the given name
+func (r restaurantRepository[T]) FindByName(restaurantID uint, name string) (*T, error) {
+	var item T
+	if err := r.db.Where("restaurant_id = ? AND name = ?", restaurantID, name).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// WithSchedule preloads what is needed to compute whether a restaurant is open.
+// Past exceptions are skipped since they can no longer affect the status
+func WithSchedule(db *gorm.DB) *gorm.DB {
+	since := time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	return db.Preload("OpeningIntervals").Preload("OpeningExceptions", "date >= ?", since)
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"madang_api/models"
+	"madang_api/utils"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type RestaurantRepository interface {
+	Create(restaurant *models.Restaurant) error
+	Save(restaurant *models.Restaurant) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Restaurant, error)
+	FindByName(name string) (*models.Restaurant, error)
+	// FindWithSchedule and FindByIDsWithSchedule also load what is needed to tell whether restaurants are open
+	FindWithSchedule(id uint) (*models.Restaurant, error)
+	FindByIDsWithSchedule(ids []uint) ([]models.Restaurant, error)
+	// List returns a page of the restaurants matching the query, ListAll every one of them in order
+	List(query *utils.ListQuery) ([]models.Restaurant, *utils.Pagination, error)
+	ListAll(query *utils.ListQuery) ([]models.Restaurant, error)
+	FindByPlace(state string, country string, location string) ([]models.Restaurant, error)
+	// WithinRadius returns the ids of the verified, active restaurants within radiusKm of a point, closest first
+	WithinRadius(latitude float64, longitude float64, radiusKm float64) ([]RestaurantDistance, error)
+	// RestaurantOf returns the restaurant of the row of table with the given ID
+	RestaurantOf(table string, id uint) (uint, error)
+	Search(query string, restaurantID uint) ([]SearchHit, error)
+	CountSearch(query string, restaurantID uint) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) RestaurantRepository
+}
+
+// RestaurantDistance is the distance of a restaurant from the point given to WithinRadius
+type RestaurantDistance struct {
+	ID         uint
+	DistanceKm float64
+}
+
+// earthRadiusKm is the mean radius of the earth used by the haversine formula
+const earthRadiusKm = 6371.0
+
+type gormRestaurantRepository struct {
+	gormRepository[models.Restaurant]
+}
+
+func NewRestaurantRepository(db *gorm.DB) RestaurantRepository {
+	return gormRestaurantRepository{gormRepository[models.Restaurant]{db}}
+}
+
+func (r gormRestaurantRepository) WithContext(ctx context.Context) RestaurantRepository {
+	return NewRestaurantRepository(r.db.WithContext(ctx))
+}
+
+func (r gormRestaurantRepository) FindByName(name string) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	if err := r.db.Where("name = ?", name).First(&restaurant).Error; err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+func (r gormRestaurantRepository) FindWithSchedule(id uint) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	if err := r.db.Scopes(WithSchedule).First(&restaurant, id).Error; err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+func (r gormRestaurantRepository) FindByIDsWithSchedule(ids []uint) ([]models.Restaurant, error) {
+	var restaurants []models.Restaurant
+	if err := r.db.Scopes(WithSchedule).Where("id IN ?", ids).Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+func (r gormRestaurantRepository) List(query *utils.ListQuery) ([]models.Restaurant, *utils.Pagination, error) {
+	restaurants := []models.Restaurant{}
+	pagination, err := utils.Paginate(r.db.Model(&models.Restaurant{}), query, &restaurants, WithSchedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	return restaurants, pagination, nil
+}
+
+func (r gormRestaurantRepository) ListAll(query *utils.ListQuery) ([]models.Restaurant, error) {
+	restaurants := []models.Restaurant{}
+	if err := query.Sort(query.Filter(r.db.Model(&models.Restaurant{}))).Scopes(WithSchedule).Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+func (r gormRestaurantRepository) FindByPlace(state string, country string, location string) ([]models.Restaurant, error) {
+	var restaurants []models.Restaurant
+	if err := r.db.Where("state = ? AND location = ? AND country = ? AND ", state, location, country).Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+// WithinRadius computes great-circle distances with the haversine formula; a bounding box around
+// the point narrows the rows down first so the coordinates index can be used
+func (r gormRestaurantRepository) WithinRadius(latitude float64, longitude float64, radiusKm float64) ([]RestaurantDistance, error) {
+	distance := `? * 2 * asin(sqrt(
+		power(sin(radians(latitude - ?) / 2), 2) +
+		cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2)))`
+
+	query := r.db.Model(&models.Restaurant{}).
+		Select("id, "+distance+" AS distance_km", earthRadiusKm, latitude, latitude, longitude).
+		Where("verified = ? AND active = ?", true, true).
+		Where("latitude IS NOT NULL AND longitude IS NOT NULL").
+		Where("latitude BETWEEN ? AND ?", latitude-radiusKm/111.0, latitude+radiusKm/111.0)
+
+	// one degree of longitude shrinks towards the poles, skip that part of the box where it degenerates.
+	// A box crossing the antimeridian wraps around to the other side of it
+	if cosLat := math.Cos(latitude * math.Pi / 180); cosLat > 0.01 {
+		lngDelta := radiusKm / (111.0 * cosLat)
+		west, east := longitude-lngDelta, longitude+lngDelta
+		switch {
+		case lngDelta >= 180:
+		case west < -180:
+			query = query.Where("(longitude BETWEEN ? AND 180 OR longitude BETWEEN -180 AND ?)", west+360, east)
+		case east > 180:
+			query = query.Where("(longitude BETWEEN ? AND 180 OR longitude BETWEEN -180 AND ?)", west, east-360)
+		default:
+			query = query.Where("longitude BETWEEN ? AND ?", west, east)
+		}
+	}
+
+	var hits []RestaurantDistance
+	if err := r.db.Table("(?) AS nearby", query).Where("distance_km <= ?", radiusKm).Order("distance_km, id").Find(&hits).Error; err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+func (r gormRestaurantRepository) RestaurantOf(table string, id uint) (uint, error) {
+	var restaurantIDs []uint
+	if err := r.db.Table(table).Where("id = ?", id).Pluck("restaurant_id", &restaurantIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(restaurantIDs) == 0 {
+		return 0, errors.New("record not found")
+	}
+	return restaurantIDs[0], nil
+}
+
+func (r gormRestaurantRepository) Search(query string, restaurantID uint) ([]SearchHit, error) {
+	return runSearch(r.db, restaurantSearch, query, restaurantID)
+}
+
+func (r gormRestaurantRepository) CountSearch(query string, restaurantID uint) (int64, error) {
+	return countSearch(r.db, restaurantSearch, query, restaurantID)
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// searchLimit caps the number of hits a single search returns
+const searchLimit = 50
+
+// searchSpec describes how one table is searched. Vector is the weighted tsvector expression kept
+// in sync with the GIN index created in migrations/sql/0002_search_indexes.up.sql; ExtraVector covers the joined
+// columns (such as the category name) that an index on the table itself cannot include.
+// RestaurantColumn is used to restrict a search to one restaurant
+type searchSpec struct {
+	Table            string
+	Joins            string
+	Vector           string
+	ExtraVector      string
+	Name             string
+	Document         string
+	Where            string
+	RestaurantColumn string
+}
+
+// SearchHit is the id of a matching row together with its relevance and highlighted snippet
+type SearchHit struct {
+	ID      uint    `json:"id"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+var foodSearch = searchSpec{
+	Table:            "foods",
+	Joins:            "LEFT JOIN categories ON categories.id = foods.category_id AND categories.deleted_at IS NULL",
+	Vector:           "(setweight(to_tsvector('simple', coalesce(foods.name, '')), 'A') || setweight(to_tsvector('simple', coalesce(foods.description, '')), 'B'))",
+	ExtraVector:      "setweight(to_tsvector('simple', coalesce(categories.name, '')), 'C')",
+	Name:             "foods.name",
+	Document:         "coalesce(foods.name, '') || ' ' || coalesce(foods.description, '') || ' ' || coalesce(categories.name, '')",
+	RestaurantColumn: "foods.restaurant_id",
+}
+
+var tableSearch = searchSpec{
+	Table:            "tables",
+	Joins:            "LEFT JOIN categories ON categories.id = tables.category_id AND categories.deleted_at IS NULL",
+	Vector:           "setweight(to_tsvector('simple', coalesce(tables.name, '')), 'A')",
+	ExtraVector:      "setweight(to_tsvector('simple', coalesce(categories.name, '')), 'C')",
+	Name:             "tables.name",
+	Document:         "coalesce(tables.name, '') || ' ' || coalesce(categories.name, '')",
+	RestaurantColumn: "tables.restaurant_id",
+}
+
+var restaurantSearch = searchSpec{
+	Table:            "restaurants",
+	Vector:           "(setweight(to_tsvector('simple', coalesce(restaurants.name, '')), 'A') || setweight(to_tsvector('simple', coalesce(restaurants.address, '') || ' ' || coalesce(restaurants.location, '')), 'B') || setweight(to_tsvector('simple', coalesce(restaurants.state, '') || ' ' || coalesce(restaurants.country, '')), 'C'))",
+	Name:             "restaurants.name",
+	Document:         "coalesce(restaurants.name, '') || ' ' || coalesce(restaurants.address, '') || ' ' || coalesce(restaurants.location, '') || ' ' || coalesce(restaurants.state, '') || ' ' || coalesce(restaurants.country, '')",
+	RestaurantColumn: "restaurants.id",
+}
+
+var addonSearch = searchSpec{
+	Table:            "addons",
+	Vector:           "(setweight(to_tsvector('simple', coalesce(addons.name, '')), 'A') || setweight(to_tsvector('simple', coalesce(addons.type, '')), 'B'))",
+	Name:             "addons.name",
+	Document:         "coalesce(addons.name, '') || ' ' || coalesce(addons.type, '')",
+	RestaurantColumn: "addons.restaurant_id",
+}
+
+var categorySearch = searchSpec{
+	Table:            "categories",
+	Vector:           "(setweight(to_tsvector('simple', coalesce(categories.name, '')), 'A') || setweight(to_tsvector('simple', coalesce(categories.type, '')), 'B'))",
+	Name:             "categories.name",
+	Document:         "coalesce(categories.name, '') || ' ' || coalesce(categories.type, '')",
+	Where:            "categories.deleted_at IS NULL",
+	RestaurantColumn: "categories.restaurant_id",
+}
+
+// prefixTSQuery turns free text into a tsquery where every word is matched as a prefix,
+// e.g. "Pizza marg" becomes "pizza:* & marg:*". Anything that is not a letter or digit is dropped
+// so user input can never produce a tsquery syntax error
+func prefixTSQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// searchFilter builds the FROM and WHERE parts shared by runSearch and countSearch. A row matches when
+// its text matches every word as a prefix, or when its name is close enough to the query to be a typo (pg_trgm).
+// Each way of matching is its own branch of a UNION so Postgres can answer every one of them from its GIN
+// index; ORed together in a single WHERE they would fall back to scanning the table
+func searchFilter(spec searchSpec, query string, tsQuery string, restaurantID uint) (string, []interface{}) {
+	matches := []string{fmt.Sprintf("SELECT %[1]s.id FROM %[1]s WHERE %[2]s @@ to_tsquery('simple', ?)", spec.Table, spec.Vector)}
+	args := []interface{}{tsQuery, tsQuery}
+	if spec.ExtraVector != "" {
+		matches = append(matches, fmt.Sprintf("SELECT %s.id FROM %s %s WHERE %s @@ to_tsquery('simple', ?)", spec.Table, spec.Table, spec.Joins, spec.ExtraVector))
+		args = append(args, tsQuery)
+	}
+	matches = append(matches, fmt.Sprintf("SELECT %[1]s.id FROM %[1]s WHERE %[2]s %% ?", spec.Table, spec.Name))
+	args = append(args, query)
+
+	sql := fmt.Sprintf("FROM %s %s, to_tsquery('simple', ?) AS q(query) WHERE %s.id IN (%s)", spec.Table, spec.Joins, spec.Table, strings.Join(matches, " UNION "))
+	if spec.Where != "" {
+		sql += " AND " + spec.Where
+	}
+	if restaurantID != 0 {
+		sql += " AND " + spec.RestaurantColumn + " = ?"
+		args = append(args, restaurantID)
+	}
+	return sql, args
+}
+
+// runSearch ranks the rows of spec.Table against the query, optionally restricted to one restaurant
+func runSearch(db *gorm.DB, spec searchSpec, query string, restaurantID uint) ([]SearchHit, error) {
+	hits := []SearchHit{}
+	tsQuery := prefixTSQuery(query)
+	if tsQuery == "" {
+		return hits, nil
+	}
+
+	vector := spec.Vector
+	if spec.ExtraVector != "" {
+		vector = "(" + spec.Vector + " || " + spec.ExtraVector + ")"
+	}
+	filter, filterArgs := searchFilter(spec, query, tsQuery, restaurantID)
+
+	sql := fmt.Sprintf(`SELECT %[1]s.id AS id,
+		ts_rank(%[2]s, q.query) + similarity(%[3]s, ?) AS rank,
+		ts_headline('simple', %[4]s, q.query, 'StartSel=<b>, StopSel=</b>, MaxWords=20, MinWords=5') AS snippet
+		%[5]s
+		ORDER BY rank DESC, %[1]s.id
+		LIMIT ?`,
+		spec.Table, vector, spec.Name, spec.Document, filter)
+
+	args := append([]interface{}{query}, filterArgs...)
+	args = append(args, searchLimit)
+	if err := db.Raw(sql, args...).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// countSearch counts every row matching the query, not just the ones runSearch returns
+func countSearch(db *gorm.DB, spec searchSpec, query string, restaurantID uint) (int64, error) {
+	tsQuery := prefixTSQuery(query)
+	if tsQuery == "" {
+		return 0, nil
+	}
+
+	filter, args := searchFilter(spec, query, tsQuery, restaurantID)
+	var count int64
+	if err := db.Raw("SELECT count(*) "+filter, args...).Scan(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+type TableRepository interface {
+	Create(table *models.Table) error
+	Save(table *models.Table) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Table, error)
+	FindByIDs(ids []uint) ([]models.Table, error)
+	FindByName(restaurantID uint, name string) (*models.Table, error)
+	FindByRestaurant(restaurantID uint) ([]models.Table, error)
+	List(query *utils.ListQuery) ([]models.Table, *utils.Pagination, error)
+	// BestRated returns the best rated tables of a restaurant
+	BestRated(restaurantID uint, limit int) ([]models.Table, error)
+	Search(query string, restaurantID uint) ([]SearchHit, error)
+	CountSearch(query string, restaurantID uint) (int64, error)
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) TableRepository
+}
+
+type gormTableRepository struct {
+	restaurantRepository[models.Table]
+}
+
+func NewTableRepository(db *gorm.DB) TableRepository {
+	return gormTableRepository{restaurantRepository[models.Table]{gormRepository[models.Table]{db}}}
+}
+
+func (r gormTableRepository) WithContext(ctx context.Context) TableRepository {
+	return NewTableRepository(r.db.WithContext(ctx))
+}
+
+func (r gormTableRepository) BestRated(restaurantID uint, limit int) ([]models.Table, error) {
+	var tables []models.Table
+	if err := r.db.Where("restaurant_id = ?", restaurantID).Order("average_rating desc, id desc").Limit(limit).Find(&tables).Error; err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func (r gormTableRepository) Search(query string, restaurantID uint) ([]SearchHit, error) {
+	return runSearch(r.db, tableSearch, query, restaurantID)
+}
+
+func (r gormTableRepository) CountSearch(query string, restaurantID uint) (int64, error) {
+	return countSearch(r.db, tableSearch, query, restaurantID)
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+)
+
+type TransactionRepository interface {
+	Create(transaction *models.Transaction) error
+	Save(transaction *models.Transaction) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.Transaction, error)
+	FindByRestaurant(restaurantID uint) ([]models.Transaction, error)
+	List(query *utils.ListQuery) ([]models.Transaction, *utils.Pagination, error)
+}
+
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return restaurantRepository[models.Transaction]{gormRepository[models.Transaction]{db}}
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"madang_api/models"
+	"madang_api/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRepository interface {
+	Create(user *models.User) error
+	Save(user *models.User) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	List(query *utils.ListQuery) ([]models.User, *utils.Pagination, error)
+	// RecordFailedLogin counts a failed login of the user and, on the maxFailures-th in a row, locks the
+	// account until lockUntil and starts counting again. It returns when the account is locked until, nil
+	// when it is not
+	RecordFailedLogin(id uint, maxFailures int, lockUntil time.Time) (*time.Time, error)
+	// ResetFailedLogins forgets the failed logins of the user and lifts its lockout
+	ResetFailedLogins(id uint) error
+	// WithContext returns the repository running its queries with ctx, so they join its trace
+	WithContext(ctx context.Context) UserRepository
+}
+
+type gormUserRepository struct {
+	gormRepository[models.User]
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return gormUserRepository{gormRepository[models.User]{db}}
+}
+
+func (r gormUserRepository) WithContext(ctx context.Context) UserRepository {
+	return NewUserRepository(r.db.WithContext(ctx))
+}
+
+func (r gormUserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUserRepository) RecordFailedLogin(id uint, maxFailures int, lockUntil time.Time) (*time.Time, error) {
+	// a single statement, so concurrent failures are all counted
+	var user models.User
+	err := r.db.Model(&user).Clauses(clause.Returning{Columns: []clause.Column{{Name: "locked_until"}}}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_logins": gorm.Expr("CASE WHEN failed_logins + 1 >= ? THEN 0 ELSE failed_logins + 1 END", maxFailures),
+			"locked_until":  gorm.Expr("CASE WHEN failed_logins + 1 >= ? THEN ?::timestamptz ELSE locked_until END", maxFailures, lockUntil),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return user.LockedUntil, nil
+}
+
+func (r gormUserRepository) ResetFailedLogins(id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"failed_logins": 0, "locked_until": nil}).Error
+}
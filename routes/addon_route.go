@@ -8,19 +8,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAddonRoutes(router *gin.Engine, addonService *services.AddonService) {
+func SetupAddonRoutes(router *gin.Engine, mw *middleware.Middleware, addonService *services.AddonService) {
 	addonController := &controllers.AddonController{
-		AddonService: services.AddonService{},
+		AddonService: addonService,
 	}
 
+	manageMenu := mw.RequireRestaurantPermission(services.PermissionManageMenu, middleware.RestaurantFromBody())
+	manageAddon := mw.RequireRestaurantPermission(services.PermissionManageMenu, mw.RestaurantOfRecord("addons", "id"))
+
 	addonRoutes := router.Group("/api/addons")
 	{
-		addonRoutes.POST("/", middleware.AuthMiddleware, addonController.AddAddon)
-		addonRoutes.PUT("/:id", middleware.AuthMiddleware, addonController.UpdateAddon)
-		addonRoutes.DELETE("/:id", middleware.AuthMiddleware, addonController.DeleteAddon)
-		addonRoutes.GET("/", middleware.AuthMiddleware, addonController.GetAllAddons)
-		addonRoutes.GET("/search", middleware.AuthMiddleware, addonController.SearchAddon)
-		addonRoutes.GET("/restaurant/:id", middleware.AuthMiddleware, addonController.GetRestaurantAddons)
-		addonRoutes.GET("/:id", middleware.AuthMiddleware, addonController.GetAddon)
+		addonRoutes.POST("/", mw.AuthMiddleware, manageMenu, addonController.AddAddon)
+		addonRoutes.PUT("/:id", mw.AuthMiddleware, manageAddon, addonController.UpdateAddon)
+		addonRoutes.DELETE("/:id", mw.AuthMiddleware, manageAddon, addonController.DeleteAddon)
+		addonRoutes.GET("/", mw.AuthMiddleware, addonController.GetAllAddons)
+		addonRoutes.GET("/search", mw.AuthMiddleware, addonController.SearchAddon)
+		addonRoutes.GET("/restaurant/:id", mw.AuthMiddleware, addonController.GetRestaurantAddons)
+		addonRoutes.GET("/:id", mw.AuthMiddleware, addonController.GetAddon)
 	}
 }
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupBrandRoutes(router *gin.Engine, mw *middleware.Middleware, brandService *services.BrandService) {
+	brandController := &controllers.BrandController{
+		BrandService: brandService,
+	}
+
+	brandOwner := mw.RequireBrandOwner("id")
+	manageBranchMenu := mw.RequireRestaurantPermission(services.PermissionManageMenu, middleware.RestaurantFromParam("id"))
+
+	brandRoutes := router.Group("/api/brands")
+	{
+		brandRoutes.POST("/", mw.AuthMiddleware, brandController.CreateBrand)
+		brandRoutes.GET("/", mw.AuthMiddleware, brandController.GetAllBrands)
+		brandRoutes.GET("/:id", mw.AuthMiddleware, brandController.GetBrand)
+		brandRoutes.PUT("/:id", mw.AuthMiddleware, brandOwner, brandController.UpdateBrand)
+		brandRoutes.DELETE("/:id", mw.AuthMiddleware, brandOwner, brandController.DeleteBrand)
+		brandRoutes.GET("/:id/report", mw.AuthMiddleware, brandOwner, brandController.GetBrandReport)
+		brandRoutes.POST("/:id/sync", mw.AuthMiddleware, brandOwner, brandController.SyncBrand)
+		brandRoutes.POST("/:id/branches", mw.AuthMiddleware, brandOwner, brandController.AddBranch)
+		brandRoutes.DELETE("/:id/branches/:restaurant_id", mw.AuthMiddleware, brandOwner, brandController.RemoveBranch)
+		brandRoutes.PUT("/:id/branches/:restaurant_id/manager", mw.AuthMiddleware, brandOwner, brandController.SetBranchManager)
+		brandRoutes.POST("/:id/foods", mw.AuthMiddleware, brandOwner, brandController.AddTemplateFood)
+		brandRoutes.PUT("/:id/foods/:food_id", mw.AuthMiddleware, brandOwner, brandController.UpdateTemplateFood)
+		brandRoutes.DELETE("/:id/foods/:food_id", mw.AuthMiddleware, brandOwner, brandController.DeleteTemplateFood)
+		brandRoutes.POST("/:id/addons", mw.AuthMiddleware, brandOwner, brandController.AddTemplateAddon)
+		brandRoutes.PUT("/:id/addons/:addon_id", mw.AuthMiddleware, brandOwner, brandController.UpdateTemplateAddon)
+		brandRoutes.DELETE("/:id/addons/:addon_id", mw.AuthMiddleware, brandOwner, brandController.DeleteTemplateAddon)
+	}
+
+	overrideRoutes := router.Group("/api/restaurants/:id/overrides")
+	{
+		overrideRoutes.GET("/", mw.AuthMiddleware, manageBranchMenu, brandController.GetBranchOverrides)
+		overrideRoutes.PUT("/", mw.AuthMiddleware, manageBranchMenu, brandController.SetBranchOverride)
+		overrideRoutes.DELETE("/:override_id", mw.AuthMiddleware, manageBranchMenu, brandController.DeleteBranchOverride)
+	}
+}
@@ -8,18 +8,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupCategoryRoutes(router *gin.Engine, categoryService *services.CategoryService) {
+func SetupCategoryRoutes(router *gin.Engine, mw *middleware.Middleware, categoryService *services.CategoryService) {
 	categoryController := &controllers.CategoryController{
-		CategoryService: services.CategoryService{},
+		CategoryService: categoryService,
 	}
 
+	manageMenu := mw.RequireRestaurantPermission(services.PermissionManageMenu, middleware.RestaurantFromBody())
+	manageCategory := mw.RequireRestaurantPermission(services.PermissionManageMenu, mw.RestaurantOfRecord("categories", "id"))
+
 	categoryRoutes := router.Group("/api/categories")
 	{
-		categoryRoutes.POST("/", middleware.AuthMiddleware, categoryController.CreateCategory)
-		categoryRoutes.PUT("/:id", middleware.AuthMiddleware, categoryController.UpdateCategory)
-		categoryRoutes.DELETE("/:id", middleware.AuthMiddleware, categoryController.DeleteCategory)
-		categoryRoutes.GET("/", middleware.AuthMiddleware, categoryController.GetAllCategories)
-		categoryRoutes.GET("/restaurant/:id", middleware.AuthMiddleware, categoryController.GetRestaurantCategories)
-		categoryRoutes.GET("/:id", middleware.AuthMiddleware, categoryController.GetCategory)
+		categoryRoutes.POST("/", mw.AuthMiddleware, manageMenu, categoryController.CreateCategory)
+		categoryRoutes.PUT("/:id", mw.AuthMiddleware, manageCategory, categoryController.UpdateCategory)
+		categoryRoutes.DELETE("/:id", mw.AuthMiddleware, manageCategory, categoryController.DeleteCategory)
+		categoryRoutes.GET("/", mw.AuthMiddleware, categoryController.GetAllCategories)
+		categoryRoutes.GET("/restaurant/:id", mw.AuthMiddleware, categoryController.GetRestaurantCategories)
+		categoryRoutes.GET("/:id", mw.AuthMiddleware, categoryController.GetCategory)
 	}
 }
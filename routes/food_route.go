@@ -8,19 +8,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupFoodRoutes(router *gin.Engine, foodService *services.FoodService) {
+func SetupFoodRoutes(router *gin.Engine, mw *middleware.Middleware, foodService *services.FoodService) {
 	foodController := &controllers.FoodController{
-		FoodService: services.FoodService{},
+		FoodService: foodService,
 	}
 
+	manageMenu := mw.RequireRestaurantPermission(services.PermissionManageMenu, middleware.RestaurantFromBody())
+	manageFood := mw.RequireRestaurantPermission(services.PermissionManageMenu, mw.RestaurantOfRecord("foods", "id"))
+
 	foodRoutes := router.Group("/api/foods")
 	{
-		foodRoutes.POST("/", middleware.AuthMiddleware, foodController.AddFood)
-		foodRoutes.PUT("/:id", middleware.AuthMiddleware, foodController.UpdateFood)
-		foodRoutes.DELETE("/:id", middleware.AuthMiddleware, foodController.DeleteFood)
-		foodRoutes.GET("/", middleware.AuthMiddleware, foodController.GetAllFoods)
-		foodRoutes.GET("/search", middleware.AuthMiddleware, foodController.SearchFood)
-		foodRoutes.GET("/restaurant/:id", middleware.AuthMiddleware, foodController.GetRestaurantFoods)
-		foodRoutes.GET("/:id", middleware.AuthMiddleware, foodController.GetFood)
+		foodRoutes.POST("/", mw.AuthMiddleware, manageMenu, foodController.AddFood)
+		foodRoutes.PUT("/:id", mw.AuthMiddleware, manageFood, foodController.UpdateFood)
+		foodRoutes.DELETE("/:id", mw.AuthMiddleware, manageFood, foodController.DeleteFood)
+		foodRoutes.GET("/", mw.AuthMiddleware, foodController.GetAllFoods)
+		foodRoutes.GET("/search", mw.AuthMiddleware, foodController.SearchFood)
+		foodRoutes.GET("/restaurant/:id", mw.AuthMiddleware, foodController.GetRestaurantFoods)
+		foodRoutes.GET("/restaurant/:id/recommended", mw.AuthMiddleware, foodController.RecommendedFoods)
+		foodRoutes.GET("/:id", mw.AuthMiddleware, foodController.GetFood)
 	}
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHealthRoutes sets up the probes of the orchestrator. They are not behind AuthMiddleware
+func SetupHealthRoutes(router *gin.Engine, healthService *services.HealthService) {
+	healthController := &controllers.HealthController{
+		HealthService: healthService,
+	}
+
+	router.GET("/healthz", healthController.Healthz)
+	router.GET("/readyz", healthController.Readyz)
+	router.GET("/version", healthController.Version)
+}
@@ -3,15 +3,16 @@ package routes
 import (
 	"madang_api/controllers"
 	"madang_api/middleware"
+	"madang_api/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupInitRoutes(router *gin.Engine) {
-	initController := &controllers.InitController{}
+func SetupInitRoutes(router *gin.Engine, mw *middleware.Middleware, feedService *services.FeedService) {
+	initController := &controllers.InitController{FeedService: feedService}
 
 	initRoutes := router.Group("/api/inits")
 	{
-		initRoutes.GET("/", middleware.AuthMiddleware, initController.LoadData)
+		initRoutes.GET("/", mw.AuthMiddleware, initController.LoadData)
 	}
 }
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupInventoryRoutes(router *gin.Engine, mw *middleware.Middleware, inventoryService *services.InventoryService) {
+	inventoryController := &controllers.InventoryController{
+		InventoryService: inventoryService,
+	}
+
+	manageIngredients := mw.RequireRestaurantPermission(services.PermissionManageMenu, middleware.RestaurantFromBody())
+	manageIngredient := mw.RequireRestaurantPermission(services.PermissionManageMenu, mw.RestaurantOfRecord("ingredients", "id"))
+	manageFoodRecipe := mw.RequireRestaurantPermission(services.PermissionManageMenu, mw.RestaurantOfRecord("foods", "id"))
+	manageAddonRecipe := mw.RequireRestaurantPermission(services.PermissionManageMenu, mw.RestaurantOfRecord("addons", "id"))
+	viewIngredients := mw.RequireRestaurantPermission(services.PermissionViewMenu, middleware.RestaurantFromParam("id"))
+	viewIngredient := mw.RequireRestaurantPermission(services.PermissionViewMenu, mw.RestaurantOfRecord("ingredients", "id"))
+	viewFoodRecipe := mw.RequireRestaurantPermission(services.PermissionViewMenu, mw.RestaurantOfRecord("foods", "id"))
+	viewAddonRecipe := mw.RequireRestaurantPermission(services.PermissionViewMenu, mw.RestaurantOfRecord("addons", "id"))
+
+	ingredientRoutes := router.Group("/api/ingredients")
+	{
+		ingredientRoutes.POST("/", mw.AuthMiddleware, manageIngredients, inventoryController.AddIngredient)
+		ingredientRoutes.PUT("/:id", mw.AuthMiddleware, manageIngredient, inventoryController.UpdateIngredient)
+		ingredientRoutes.DELETE("/:id", mw.AuthMiddleware, manageIngredient, inventoryController.DeleteIngredient)
+		ingredientRoutes.POST("/:id/adjust", mw.AuthMiddleware, manageIngredient, inventoryController.AdjustStock)
+		ingredientRoutes.GET("/:id/history", mw.AuthMiddleware, viewIngredient, inventoryController.GetStockHistory)
+		ingredientRoutes.GET("/restaurant/:id", mw.AuthMiddleware, viewIngredients, inventoryController.GetRestaurantIngredients)
+		ingredientRoutes.GET("/restaurant/:id/low-stock", mw.AuthMiddleware, viewIngredients, inventoryController.GetLowStockIngredients)
+		ingredientRoutes.GET("/:id", mw.AuthMiddleware, viewIngredient, inventoryController.GetIngredient)
+	}
+
+	recipeRoutes := router.Group("/api/recipes")
+	{
+		recipeRoutes.GET("/food/:id", mw.AuthMiddleware, viewFoodRecipe, inventoryController.GetFoodRecipe)
+		recipeRoutes.PUT("/food/:id", mw.AuthMiddleware, manageFoodRecipe, inventoryController.SetFoodRecipe)
+		recipeRoutes.GET("/addon/:id", mw.AuthMiddleware, viewAddonRecipe, inventoryController.GetAddonRecipe)
+		recipeRoutes.PUT("/addon/:id", mw.AuthMiddleware, manageAddonRecipe, inventoryController.SetAddonRecipe)
+	}
+}
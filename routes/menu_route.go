@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupMenuRoutes(router *gin.Engine, mw *middleware.Middleware, menuService *services.MenuService) {
+	menuController := &controllers.MenuController{
+		MenuService: menuService,
+	}
+
+	viewMenu := mw.RequireRestaurantPermission(services.PermissionViewMenu, middleware.RestaurantFromParam("id"))
+	manageMenu := mw.RequireRestaurantPermission(services.PermissionManageMenu, middleware.RestaurantFromParam("id"))
+
+	menuRoutes := router.Group("/api/menus")
+	{
+		menuRoutes.GET("/restaurant/:id/export", mw.AuthMiddleware, viewMenu, menuController.ExportMenu)
+		menuRoutes.POST("/restaurant/:id/import", mw.AuthMiddleware, manageMenu, menuController.ImportMenu)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"madang_api/metrics"
+	"madang_api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMetricsRoutes serves the Prometheus metrics. When token is set the scraper has to send it as a
+// bearer token, the metrics are not behind AuthMiddleware
+func SetupMetricsRoutes(router *gin.Engine, token string) {
+	handler := metrics.Handler()
+	router.GET("/metrics", func(c *gin.Context) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			utils.AbortResponse(c, http.StatusUnauthorized, "invalid metrics token")
+			return
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	})
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNotificationRoutes(router *gin.Engine, mw *middleware.Middleware, notificationService *services.NotificationService) {
+	notificationController := &controllers.NotificationController{
+		NotificationService: notificationService,
+	}
+
+	notificationRoutes := router.Group("/api/notifications")
+	{
+		notificationRoutes.GET("/", mw.AuthMiddleware, notificationController.GetMyNotifications)
+		notificationRoutes.PUT("/:id/read", mw.AuthMiddleware, notificationController.MarkAsRead)
+	}
+}
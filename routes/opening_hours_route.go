@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupOpeningHoursRoutes(router *gin.Engine, mw *middleware.Middleware, openingHoursService *services.OpeningHoursService) {
+	openingHoursController := &controllers.OpeningHoursController{
+		OpeningHoursService: openingHoursService,
+	}
+
+	openingHoursRoutes := router.Group("/api/restaurants/:id/hours")
+	{
+		openingHoursRoutes.GET("/", mw.AuthMiddleware, openingHoursController.GetOpeningHours)
+		openingHoursRoutes.PUT("/", mw.AuthMiddleware, openingHoursController.SetWeeklySchedule)
+		openingHoursRoutes.POST("/exceptions", mw.AuthMiddleware, openingHoursController.AddException)
+		openingHoursRoutes.DELETE("/exceptions/:exception_id", mw.AuthMiddleware, openingHoursController.DeleteException)
+	}
+}
@@ -8,21 +8,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupOrderRoutes(router *gin.Engine, orderService *services.OrderService) {
+func SetupOrderRoutes(router *gin.Engine, mw *middleware.Middleware, orderService *services.OrderService) {
 	orderController := &controllers.OrderController{
-		OrderService: services.OrderService{},
+		OrderService: orderService,
 	}
 
+	manageOrder := mw.RequireRestaurantPermission(services.PermissionManageOrders, mw.RestaurantOfRecord("orders", "id"))
+	viewOrder := mw.RequireOrderAccess("id", services.PermissionViewOrders)
+	viewRestaurantOrders := mw.RequireRestaurantPermission(services.PermissionViewOrders, middleware.RestaurantFromParam("restaurant_id"))
+	// the lists across every restaurant
+	admin := middleware.RequireRole("admin")
+
 	orderRoutes := router.Group("/api/orders")
 	{
-		orderRoutes.POST("/", middleware.AuthMiddleware, orderController.AddOrder)
-		orderRoutes.PUT("/:id", middleware.AuthMiddleware, orderController.UpdateOrder)
-		orderRoutes.DELETE("/:id", middleware.AuthMiddleware, orderController.DeleteOrder)
-		orderRoutes.GET("/", middleware.AuthMiddleware, orderController.GetAllOrders)
-		orderRoutes.GET("/search", middleware.AuthMiddleware, orderController.SearchOrder)
-		orderRoutes.GET("/status", middleware.AuthMiddleware, orderController.GetOrdersByStatus)
-		orderRoutes.GET("/restaurant/:restaurant_id", middleware.AuthMiddleware, orderController.GetRestaurantOrders)
-		orderRoutes.GET("/user/:user_id", middleware.AuthMiddleware, orderController.GetUserOrders)
-		orderRoutes.GET("/:id", middleware.AuthMiddleware, orderController.GetOrder)
+		orderRoutes.POST("/", mw.AuthMiddleware, orderController.AddOrder)
+		orderRoutes.PUT("/:id", mw.AuthMiddleware, manageOrder, orderController.UpdateOrder)
+		orderRoutes.PUT("/:id/status", mw.AuthMiddleware, manageOrder, orderController.UpdateOrderStatus)
+		orderRoutes.DELETE("/:id", mw.AuthMiddleware, manageOrder, orderController.DeleteOrder)
+		orderRoutes.GET("/", mw.AuthMiddleware, admin, orderController.GetAllOrders)
+		orderRoutes.GET("/search", mw.AuthMiddleware, admin, orderController.SearchOrder)
+		orderRoutes.GET("/status", mw.AuthMiddleware, admin, orderController.GetOrdersByStatus)
+		orderRoutes.GET("/restaurant/:restaurant_id", mw.AuthMiddleware, viewRestaurantOrders, orderController.GetRestaurantOrders)
+		orderRoutes.GET("/user/:user_id", mw.AuthMiddleware, middleware.RequireSelfOrAdmin("user_id"), orderController.GetUserOrders)
+		orderRoutes.GET("/:id", mw.AuthMiddleware, viewOrder, orderController.GetOrder)
 	}
 }
@@ -8,18 +8,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupPaymentRoutes(router *gin.Engine, paymentService *services.PaymentService) {
+func SetupPaymentRoutes(router *gin.Engine, mw *middleware.Middleware, paymentService *services.PaymentService) {
 	paymentController := &controllers.PaymentController{
-		PaymentService: services.PaymentService{},
+		PaymentService: paymentService,
 	}
 
 	paymentRoutes := router.Group("/api/payments")
 	{
-		paymentRoutes.POST("/", middleware.AuthMiddleware, paymentController.CreatePayment)
-		paymentRoutes.PUT("/:id", middleware.AuthMiddleware, paymentController.UpdatePayment)
-		paymentRoutes.DELETE("/:id", middleware.AuthMiddleware, paymentController.DeletePayment)
-		paymentRoutes.GET("/", middleware.AuthMiddleware, paymentController.GetAllPayments)
-		paymentRoutes.GET("/restaurant/:id", middleware.AuthMiddleware, paymentController.GetRestaurantPayments)
-		paymentRoutes.GET("/:id", middleware.AuthMiddleware, paymentController.GetPayment)
+		paymentRoutes.POST("/", mw.AuthMiddleware, paymentController.CreatePayment)
+		paymentRoutes.PUT("/:id", mw.AuthMiddleware, paymentController.UpdatePayment)
+		paymentRoutes.DELETE("/:id", mw.AuthMiddleware, paymentController.DeletePayment)
+		paymentRoutes.GET("/", mw.AuthMiddleware, paymentController.GetAllPayments)
+		paymentRoutes.GET("/restaurant/:id", mw.AuthMiddleware, paymentController.GetRestaurantPayments)
+		paymentRoutes.GET("/:id", mw.AuthMiddleware, paymentController.GetPayment)
 	}
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRecommendationRoutes(router *gin.Engine, mw *middleware.Middleware, recommendationService *services.RecommendationService) {
+	recommendationController := &controllers.RecommendationController{
+		RecommendationService: recommendationService,
+	}
+
+	recommendationRoutes := router.Group("/api/recommendations")
+	{
+		recommendationRoutes.POST("/refresh", mw.AuthMiddleware, middleware.RequireRole("admin"), recommendationController.RefreshRecommendations)
+	}
+}
@@ -8,21 +8,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRestaurantRoutes(router *gin.Engine, restaurantService *services.RestaurantService) {
+func SetupRestaurantRoutes(router *gin.Engine, mw *middleware.Middleware, restaurantService *services.RestaurantService) {
 	restaurantController := &controllers.RestaurantController{
-		RestaurantService: services.RestaurantService{},
+		RestaurantService: restaurantService,
 	}
 
+	manageRestaurant := mw.RequireRestaurantPermission(services.PermissionManageRestaurant, middleware.RestaurantFromParam("id"))
+	ownRestaurant := mw.RequireRestaurantPermission(services.PermissionOwnRestaurant, middleware.RestaurantFromParam("id"))
+
 	restaurantRoutes := router.Group("/api/restaurants")
 	{
-		restaurantRoutes.POST("/", middleware.AuthMiddleware, restaurantController.CreateRestaurant)
-		restaurantRoutes.PUT("/:id", middleware.AuthMiddleware, restaurantController.UpdateRestaurant)
-		restaurantRoutes.DELETE("/:id", middleware.AuthMiddleware, restaurantController.DeleteRestaurant)
-		restaurantRoutes.GET("/", middleware.AuthMiddleware, restaurantController.GetAllRestaurant)
-		restaurantRoutes.GET("/search", middleware.AuthMiddleware, restaurantController.SearchRestaurant)
-		restaurantRoutes.GET("/verified", middleware.AuthMiddleware, restaurantController.GetAllVerifiedRestaurants)
-		restaurantRoutes.GET("/filtered", middleware.AuthMiddleware, restaurantController.FilterRestaurant)
-		restaurantRoutes.GET("/user/:user_id", middleware.AuthMiddleware, restaurantController.GetUserRestaurants)
-		restaurantRoutes.GET("/:id", middleware.AuthMiddleware, restaurantController.GetRestaurant)
+		restaurantRoutes.POST("/", mw.AuthMiddleware, restaurantController.CreateRestaurant)
+		restaurantRoutes.PUT("/:id", mw.AuthMiddleware, manageRestaurant, restaurantController.UpdateRestaurant)
+		restaurantRoutes.DELETE("/:id", mw.AuthMiddleware, ownRestaurant, restaurantController.DeleteRestaurant)
+		restaurantRoutes.POST("/:id/transfer", mw.AuthMiddleware, ownRestaurant, restaurantController.TransferRestaurant)
+		restaurantRoutes.GET("/", mw.AuthMiddleware, restaurantController.GetAllRestaurant)
+		restaurantRoutes.GET("/search", mw.AuthMiddleware, restaurantController.SearchRestaurant)
+		restaurantRoutes.GET("/verified", mw.AuthMiddleware, restaurantController.GetAllVerifiedRestaurants)
+		restaurantRoutes.GET("/filtered", mw.AuthMiddleware, restaurantController.FilterRestaurant)
+		restaurantRoutes.GET("/nearby", mw.AuthMiddleware, restaurantController.GetNearbyRestaurants)
+		restaurantRoutes.GET("/user/:user_id", mw.AuthMiddleware, restaurantController.GetUserRestaurants)
+		restaurantRoutes.GET("/:id", mw.AuthMiddleware, restaurantController.GetRestaurant)
 	}
 }
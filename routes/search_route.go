@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSearchRoutes(router *gin.Engine, mw *middleware.Middleware, searchService *services.SearchService) {
+	searchController := &controllers.SearchController{
+		SearchService: searchService,
+	}
+
+	router.GET("/api/search", mw.AuthMiddleware, searchController.Search)
+}
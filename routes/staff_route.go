@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStaffRoutes(router *gin.Engine, mw *middleware.Middleware, staffService *services.StaffService) {
+	staffController := &controllers.StaffController{
+		StaffService: staffService,
+	}
+
+	manageRestaurantStaff := mw.RequireRestaurantPermission(services.PermissionManageStaff, middleware.RestaurantFromParam("id"))
+	manageStaffMember := mw.RequireRestaurantPermission(services.PermissionManageStaff, mw.RestaurantOfRecord("restaurant_staff", "id"))
+
+	staffRoutes := router.Group("/api/staff")
+	{
+		staffRoutes.GET("/roles", mw.AuthMiddleware, staffController.GetRolePermissions)
+		staffRoutes.GET("/invitations", mw.AuthMiddleware, staffController.GetMyInvitations)
+		staffRoutes.POST("/invitations/accept", mw.AuthMiddleware, staffController.AcceptInvitation)
+		staffRoutes.POST("/restaurant/:id/invite", mw.AuthMiddleware, manageRestaurantStaff, staffController.InviteStaff)
+		staffRoutes.GET("/restaurant/:id", mw.AuthMiddleware, manageRestaurantStaff, staffController.GetRestaurantStaff)
+		staffRoutes.PUT("/:id", mw.AuthMiddleware, manageStaffMember, staffController.UpdateStaff)
+		staffRoutes.DELETE("/:id", mw.AuthMiddleware, manageStaffMember, staffController.RemoveStaff)
+	}
+}
@@ -8,19 +8,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupTableRoutes(router *gin.Engine, tableService *services.TableService) {
+func SetupTableRoutes(router *gin.Engine, mw *middleware.Middleware, tableService *services.TableService) {
 	tableController := &controllers.TableController{
-		TableService: services.TableService{},
+		TableService: tableService,
 	}
 
+	manageTables := mw.RequireRestaurantPermission(services.PermissionManageTables, middleware.RestaurantFromBody())
+	manageTable := mw.RequireRestaurantPermission(services.PermissionManageTables, mw.RestaurantOfRecord("tables", "id"))
+
 	tableRoutes := router.Group("/api/tables")
 	{
-		tableRoutes.POST("/", middleware.AuthMiddleware, tableController.AddTable)
-		tableRoutes.PUT("/:id", middleware.AuthMiddleware, tableController.UpdateTable)
-		tableRoutes.DELETE("/:id", middleware.AuthMiddleware, tableController.DeleteTable)
-		tableRoutes.GET("/", middleware.AuthMiddleware, tableController.GetAllTables)
-		tableRoutes.GET("/search", middleware.AuthMiddleware, tableController.SearchTable)
-		tableRoutes.GET("/restaurant/:id", middleware.AuthMiddleware, tableController.GetRestaurantTables)
-		tableRoutes.GET("/:id", middleware.AuthMiddleware, tableController.GetTable)
+		tableRoutes.POST("/", mw.AuthMiddleware, manageTables, tableController.AddTable)
+		tableRoutes.PUT("/:id", mw.AuthMiddleware, manageTable, tableController.UpdateTable)
+		tableRoutes.DELETE("/:id", mw.AuthMiddleware, manageTable, tableController.DeleteTable)
+		tableRoutes.GET("/", mw.AuthMiddleware, tableController.GetAllTables)
+		tableRoutes.GET("/search", mw.AuthMiddleware, tableController.SearchTable)
+		tableRoutes.GET("/restaurant/:id", mw.AuthMiddleware, tableController.GetRestaurantTables)
+		tableRoutes.GET("/restaurant/:id/recommended", mw.AuthMiddleware, tableController.RecommendedTables)
+		tableRoutes.GET("/:id", mw.AuthMiddleware, tableController.GetTable)
 	}
 }
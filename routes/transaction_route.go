@@ -8,18 +8,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupTransactionRoutes(router *gin.Engine, transactionService *services.TransactionService) {
+func SetupTransactionRoutes(router *gin.Engine, mw *middleware.Middleware, transactionService *services.TransactionService) {
 	transactionController := &controllers.TransactionController{
-		TransactionService: services.TransactionService{},
+		TransactionService: transactionService,
 	}
 
 	transactionRoutes := router.Group("/api/transactions")
 	{
-		transactionRoutes.POST("/", middleware.AuthMiddleware, transactionController.CreateTransaction)
-		transactionRoutes.PUT("/:id", middleware.AuthMiddleware, transactionController.UpdateTransaction)
-		transactionRoutes.DELETE("/:id", middleware.AuthMiddleware, transactionController.DeleteTransaction)
-		transactionRoutes.GET("/", middleware.AuthMiddleware, transactionController.GetAllTransactions)
-		transactionRoutes.GET("/restaurant/:id", middleware.AuthMiddleware, transactionController.GetRestaurantTransactions)
-		transactionRoutes.GET("/:id", middleware.AuthMiddleware, transactionController.GetTransaction)
+		transactionRoutes.POST("/", mw.AuthMiddleware, transactionController.CreateTransaction)
+		transactionRoutes.PUT("/:id", mw.AuthMiddleware, transactionController.UpdateTransaction)
+		transactionRoutes.DELETE("/:id", mw.AuthMiddleware, transactionController.DeleteTransaction)
+		transactionRoutes.GET("/", mw.AuthMiddleware, transactionController.GetAllTransactions)
+		transactionRoutes.GET("/restaurant/:id", mw.AuthMiddleware, transactionController.GetRestaurantTransactions)
+		transactionRoutes.GET("/:id", mw.AuthMiddleware, transactionController.GetTransaction)
 	}
 }
@@ -8,15 +8,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserRoutes(router *gin.Engine, userService *services.UserService) {
+func SetupUserRoutes(router *gin.Engine, mw *middleware.Middleware, userService *services.UserService) {
 	userController := controllers.UserController{UserService: userService}
 
-	router.POST("/api/auth/register", userController.Register)
-	router.POST("/api/auth/verify-email", userController.ValidateEmail)
-	router.POST("/api/auth/login", userController.Login)
-	router.GET("/api/users/:id", middleware.AuthMiddleware, userController.GetUserByID)
-	router.GET("/api/users", middleware.AuthMiddleware, userController.GetAllUsers)
-	router.PUT("/api/users/:id", middleware.AuthMiddleware, userController.UpdateUser)
-	router.DELETE("/api/users/:id", middleware.AuthMiddleware, userController.DeleteUser)
+	auth := router.Group("/api/auth", mw.AuthRateLimit)
+	auth.POST("/register", userController.Register)
+	auth.POST("/verify-email", userController.ValidateEmail)
+	auth.POST("/login", userController.Login)
+	router.GET("/api/users/:id", mw.AuthMiddleware, userController.GetUserByID)
+	router.GET("/api/users", mw.AuthMiddleware, userController.GetAllUsers)
+	router.PUT("/api/users/:id", mw.AuthMiddleware, userController.UpdateUser)
+	router.DELETE("/api/users/:id", mw.AuthMiddleware, userController.DeleteUser)
 
 }
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"madang_api/controllers"
+	"madang_api/middleware"
+	"madang_api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupVerificationRoutes(router *gin.Engine, mw *middleware.Middleware, verificationService *services.VerificationService) {
+	verificationController := &controllers.VerificationController{
+		VerificationService: verificationService,
+	}
+
+	// the verifications of a restaurant are only shown to admins and to the people running it
+	viewRestaurantVerifications := mw.RequireRestaurantPermission(services.PermissionManageRestaurant, middleware.RestaurantFromParam("id"))
+	viewVerification := mw.RequireRestaurantPermission(services.PermissionManageRestaurant, mw.RestaurantOfRecord("restaurant_verifications", "id"))
+
+	verificationRoutes := router.Group("/api/verifications")
+	{
+		verificationRoutes.POST("/restaurant/:id", mw.AuthMiddleware, middleware.RequireRole("manager"), verificationController.SubmitVerification)
+		verificationRoutes.GET("/restaurant/:id", mw.AuthMiddleware, middleware.RequireRole("manager", "admin"), viewRestaurantVerifications, verificationController.GetRestaurantVerificationHistory)
+		verificationRoutes.PUT("/restaurant/:id/active", mw.AuthMiddleware, middleware.RequireRole("admin"), verificationController.SetRestaurantActive)
+		verificationRoutes.GET("/queue", mw.AuthMiddleware, middleware.RequireRole("admin"), verificationController.GetReviewQueue)
+		verificationRoutes.POST("/:id/approve", mw.AuthMiddleware, middleware.RequireRole("admin"), verificationController.ApproveVerification)
+		verificationRoutes.POST("/:id/reject", mw.AuthMiddleware, middleware.RequireRole("admin"), verificationController.RejectVerification)
+		verificationRoutes.GET("/:id", mw.AuthMiddleware, middleware.RequireRole("manager", "admin"), viewVerification, verificationController.GetVerification)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"madang_api/app"
+	"madang_api/config"
+	"madang_api/migrations"
+	"madang_api/seed"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// runSeed runs the seed subcommand, which fills the database with deterministic fake data
+func runSeed(cfg *config.Config, db *gorm.DB, args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	size := flags.String("size", "small", "amount of data: "+strings.Join(seed.SizeNames(), ", "))
+	randomSeed := flags.Int64("seed", 1, "seed of the generator, the same seed gives the same data")
+	reset := flags.Bool("reset", false, "empty every table before seeding")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: madang_api seed [-size small|medium|large] [-seed n] [-reset]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	preset, ok := seed.Sizes[*size]
+	if !ok {
+		log.Fatalf("Unknown size %q, expected one of %s", *size, strings.Join(seed.SizeNames(), ", "))
+	}
+	if pending, err := migrations.Pending(db); err != nil {
+		log.Fatal(err)
+	} else if len(pending) > 0 {
+		log.Fatalf("%d migrations are pending, run `madang_api migrate up` first", len(pending))
+	}
+
+	if *reset {
+		if cfg.Env == "production" {
+			log.Fatal("Refusing to reset a production database")
+		}
+		if err := seed.Reset(db); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Database reset")
+	}
+
+	summary, err := seed.Run(db, preset, *randomSeed)
+	if errors.Is(err, seed.ErrNotEmpty) {
+		log.Fatal("The database already has data, run `madang_api seed -reset` to replace it")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Seeded %s", summary)
+
+	// the home feed and the recommended items need the recommendations of the new orders
+	container := app.NewContainer(cfg, db)
+	if err := container.RecommendationService.RefreshRecommendations(context.Background()); err != nil {
+		log.Printf("Failed to refresh recommendations: %v", err)
+	}
+	log.Printf("Log in as admin@madang.dev, manager1@madang.dev or customer1@madang.dev with the password %s", seed.Password)
+}
@@ -0,0 +1,98 @@
+package seed
+
+// The word lists the generated data is drawn from. Appending to a list changes the data of every seed,
+// which is fine, nothing should depend on the exact generated values
+
+var familyNames = []string{"Kim", "Lee", "Park", "Choi", "Jung", "Kang", "Cho", "Yoon", "Jang", "Lim", "Han", "Oh", "Seo", "Shin", "Kwon"}
+
+var givenNames = []string{
+	"Minjun", "Seoyeon", "Jiho", "Hayoon", "Dohyun", "Jiwoo", "Eunwoo", "Seoah", "Junseo", "Haeun",
+	"Yejun", "Sua", "Siwoo", "Jiyu", "Hajun", "Chaewon", "Juwon", "Yuna", "Gunwoo", "Dayeon",
+}
+
+type city struct {
+	name      string
+	latitude  float64
+	longitude float64
+}
+
+var cities = []city{
+	{"Seoul", 37.5665, 126.9780},
+	{"Busan", 35.1796, 129.0756},
+	{"Incheon", 37.4563, 126.7052},
+	{"Daegu", 35.8714, 128.6014},
+	{"Daejeon", 36.3504, 127.3845},
+	{"Gwangju", 35.1595, 126.8526},
+}
+
+var streets = []string{"Sejong-daero", "Teheran-ro", "Itaewon-ro", "Haeundae-ro", "Dongseong-ro", "Chungjang-ro", "Eulji-ro", "Gangnam-daero"}
+
+var restaurantPrefixes = []string{"Madang", "Hanok", "Golden", "Jeju", "Seoul", "Blue House", "Maru", "Sarang", "Haneul", "Baram"}
+
+var restaurantSuffixes = []string{"Kitchen", "Table", "Bistro", "Garden", "Grill", "House", "Dining", "Pocha"}
+
+var tableNames = []string{"Window", "Garden", "Terrace", "Hall", "Private Room", "Corner", "Booth"}
+
+var specialNotes = []string{"No spicy food please", "Birthday celebration", "Allergic to peanuts", "Vegetarian option if possible", "High chair needed"}
+
+type menuItem struct {
+	name        string
+	description string
+	price       float64
+}
+
+type menuCategory struct {
+	name  string
+	kind  string
+	items []menuItem
+}
+
+// menuCategories are the categories of every generated restaurant, the one of kind table holds its tables
+var menuCategories = []menuCategory{
+	{"Mains", "food", []menuItem{
+		{"Bibimbap", "Rice with seasoned vegetables, beef and a fried egg", 12},
+		{"Bulgogi", "Marinated grilled beef", 16},
+		{"Galbi", "Grilled short ribs", 24},
+		{"Dakgalbi", "Spicy stir-fried chicken with rice cakes", 15},
+		{"Samgyeopsal", "Grilled pork belly with lettuce wraps", 18},
+		{"Japchae", "Stir-fried glass noodles with vegetables", 11},
+		{"Kimchi Jjigae", "Kimchi stew with pork and tofu", 10},
+		{"Naengmyeon", "Cold buckwheat noodles", 11},
+	}},
+	{"Starters", "food", []menuItem{
+		{"Mandu", "Pan-fried dumplings", 7},
+		{"Pajeon", "Green onion pancake", 9},
+		{"Tteokbokki", "Spicy rice cakes", 8},
+		{"Gimbap", "Seaweed rice rolls", 6},
+		{"Japchae Croquettes", "Fried glass noodle croquettes", 7},
+		{"Kimchi", "House fermented cabbage", 4},
+	}},
+	{"Desserts", "food", []menuItem{
+		{"Bingsu", "Shaved ice with red beans and fruit", 9},
+		{"Hotteok", "Sweet filled pancake", 5},
+		{"Yakgwa", "Honey cookies", 4},
+		{"Tteok", "Assorted rice cakes", 6},
+	}},
+	{"Drinks", "drink", []menuItem{
+		{"Sikhye", "Sweet rice drink", 4},
+		{"Barley Tea", "Roasted barley tea", 3},
+		{"Makgeolli", "Rice wine", 8},
+		{"Soju", "Classic soju", 6},
+		{"Yuja Tea", "Citron tea", 4},
+	}},
+	{"Tables", "table", nil},
+}
+
+var addonTemplates = []struct {
+	name  string
+	kind  string
+	price float64
+}{
+	{"Flowers", "flower", 15},
+	{"Birthday Cake", "cake", 25},
+	{"Balloons", "decoration", 10},
+	{"Candles", "decoration", 5},
+	{"High Chair", "chair", 0},
+	{"Extra Chair", "chair", 2},
+	{"Champagne", "drink", 40},
+}
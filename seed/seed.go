@@ -0,0 +1,497 @@
+// Package seed fills a database with fake but realistic data for development and QA. The same size and
+// seed always give the same rows, only the dates move with the day the data is generated
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"madang_api/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Password is the password of every generated user
+const Password = "madang123"
+
+// Size is how much data is generated
+type Size struct {
+	Managers              int
+	Customers             int
+	RestaurantsPerManager int
+	FoodsPerCategory      int
+	AddonsPerRestaurant   int
+	TablesPerRestaurant   int
+	OrdersPerCustomer     int
+}
+
+// Sizes are the presets of the seed subcommand
+var Sizes = map[string]Size{
+	"small":  {Managers: 2, Customers: 10, RestaurantsPerManager: 1, FoodsPerCategory: 3, AddonsPerRestaurant: 3, TablesPerRestaurant: 4, OrdersPerCustomer: 3},
+	"medium": {Managers: 5, Customers: 50, RestaurantsPerManager: 2, FoodsPerCategory: 5, AddonsPerRestaurant: 4, TablesPerRestaurant: 8, OrdersPerCustomer: 8},
+	"large":  {Managers: 20, Customers: 500, RestaurantsPerManager: 3, FoodsPerCategory: 8, AddonsPerRestaurant: 6, TablesPerRestaurant: 15, OrdersPerCustomer: 20},
+}
+
+// SizeNames lists the presets from the smallest to the largest
+func SizeNames() []string {
+	names := make([]string, 0, len(Sizes))
+	for name := range Sizes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return Sizes[names[i]].Customers < Sizes[names[j]].Customers })
+	return names
+}
+
+// Summary counts the rows generated by Run
+type Summary struct {
+	Users        int
+	Restaurants  int
+	Categories   int
+	Foods        int
+	Addons       int
+	Tables       int
+	Orders       int
+	Payments     int
+	Transactions int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d users, %d restaurants, %d categories, %d foods, %d addons, %d tables, %d orders, %d payments, %d transactions",
+		s.Users, s.Restaurants, s.Categories, s.Foods, s.Addons, s.Tables, s.Orders, s.Payments, s.Transactions)
+}
+
+// ErrNotEmpty is returned by Run when the database already has users, the generated IDs and logins would
+// collide with them
+var ErrNotEmpty = errors.New("the database already has data, reset it first")
+
+// batchSize is how many rows go in one INSERT
+const batchSize = 500
+
+// Run generates the data of the size in one transaction. The first restaurant is the one of
+// manager1@madang.dev, and admin@madang.dev, manager<n>@madang.dev and customer<n>@madang.dev all log in
+// with Password
+func Run(db *gorm.DB, size Size, seed int64) (*Summary, error) {
+	var users int64
+	if err := db.Model(&models.User{}).Count(&users).Error; err != nil {
+		return nil, err
+	}
+	if users > 0 {
+		return nil, ErrNotEmpty
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		random:   rand.New(rand.NewSource(seed)),
+		password: string(hash),
+		today:    time.Now().UTC().Truncate(24 * time.Hour),
+		size:     size,
+	}
+
+	summary := &Summary{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// the generated rows carry no association worth saving, every table is inserted on its own
+		tx = tx.Omit(clause.Associations).Session(&gorm.Session{})
+		return g.run(tx, summary)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Reset empties every table but the migrations bookkeeping and restarts the IDs, so the generated data
+// starts at 1
+func Reset(db *gorm.DB) error {
+	var tables []string
+	err := db.Raw("SELECT tablename FROM pg_tables WHERE schemaname = 'public' AND tablename <> 'schema_migrations'").Scan(&tables).Error
+	if err != nil || len(tables) == 0 {
+		return err
+	}
+
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = fmt.Sprintf("%q", table)
+	}
+	return db.Exec("TRUNCATE TABLE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE").Error
+}
+
+// generator draws every value from one seeded source, so the order of the calls must stay the same for
+// a seed to keep giving the same data
+type generator struct {
+	random   *rand.Rand
+	password string
+	today    time.Time
+	size     Size
+}
+
+// restaurantMenu is what a generated restaurant sells
+type restaurantMenu struct {
+	restaurant models.Restaurant
+	foods      []models.Food
+	addons     []models.Addon
+	tables     []models.Table
+}
+
+func (g *generator) run(tx *gorm.DB, summary *Summary) error {
+	admin := g.user("Admin", "admin@madang.dev", "admin", 365)
+	managers := make([]models.User, g.size.Managers)
+	for i := range managers {
+		managers[i] = g.user(g.personName(), fmt.Sprintf("manager%d@madang.dev", i+1), "manager", 365)
+	}
+	customers := make([]models.User, g.size.Customers)
+	for i := range customers {
+		customers[i] = g.user(g.personName(), fmt.Sprintf("customer%d@madang.dev", i+1), "customer", 180)
+	}
+
+	users := append([]models.User{admin}, managers...)
+	users = append(users, customers...)
+	if err := insert(tx, users); err != nil {
+		return err
+	}
+	summary.Users = len(users)
+	managers = users[1 : 1+len(managers)]
+	customers = users[1+len(managers):]
+
+	var menus []*restaurantMenu
+	for _, manager := range managers {
+		for i := 0; i < g.size.RestaurantsPerManager; i++ {
+			menu, err := g.restaurant(tx, manager, summary)
+			if err != nil {
+				return err
+			}
+			menus = append(menus, menu)
+		}
+	}
+
+	return g.orders(tx, customers, menus, summary)
+}
+
+func (g *generator) user(name string, email string, role string, maxAgeDays int) models.User {
+	createdAt := g.daysAgo(maxAgeDays)
+	return models.User{
+		Name:          name,
+		Email:         email,
+		Password:      g.password,
+		Phone:         fmt.Sprintf("010-%04d-%04d", g.random.Intn(10000), g.random.Intn(10000)),
+		Role:          role,
+		Active:        true,
+		EmailVerified: true,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+}
+
+func (g *generator) restaurant(tx *gorm.DB, manager models.User, summary *Summary) (*restaurantMenu, error) {
+	city := cities[g.random.Intn(len(cities))]
+	// within about 5km of the city center
+	latitude := round(city.latitude+(g.random.Float64()-0.5)*0.09, 6)
+	longitude := round(city.longitude+(g.random.Float64()-0.5)*0.11, 6)
+	createdAt := g.daysAgo(300)
+	verifiedAt := createdAt.Add(48 * time.Hour)
+	name := pick(g.random, restaurantPrefixes) + " " + pick(g.random, restaurantSuffixes)
+
+	menu := &restaurantMenu{restaurant: models.Restaurant{
+		Name:               name,
+		Address:            fmt.Sprintf("%d %s", 1+g.random.Intn(300), pick(g.random, streets)),
+		UserID:             manager.ID,
+		Phone:              fmt.Sprintf("02-%03d-%04d", g.random.Intn(1000), g.random.Intn(10000)),
+		Email:              fmt.Sprintf("restaurant%d@madang.dev", summary.Restaurants+1),
+		Location:           city.name,
+		Latitude:           &latitude,
+		Longitude:          &longitude,
+		State:              city.name,
+		Country:            "KR",
+		Timezone:           "Asia/Seoul",
+		Active:             true,
+		Verified:           true,
+		VerifiedAt:         &verifiedAt,
+		VerificationStatus: "approved",
+		AverageRating:      round(3+g.random.Float64()*2, 1),
+		CreatedAt:          createdAt,
+		UpdatedAt:          createdAt,
+	}}
+	if err := tx.Create(&menu.restaurant).Error; err != nil {
+		return nil, err
+	}
+	summary.Restaurants++
+	restaurantID := menu.restaurant.ID
+
+	var tableCategoryID uint
+	for _, template := range menuCategories {
+		category := models.Category{Name: template.name, Type: template.kind, RestaurantID: restaurantID}
+		category.CreatedAt, category.UpdatedAt = createdAt, createdAt
+		if err := tx.Create(&category).Error; err != nil {
+			return nil, err
+		}
+		summary.Categories++
+		if template.kind == "table" {
+			tableCategoryID = category.ID
+			continue
+		}
+
+		for _, i := range g.random.Perm(len(template.items))[:min(g.size.FoodsPerCategory, len(template.items))] {
+			item := template.items[i]
+			menu.foods = append(menu.foods, models.Food{
+				Name:          item.name,
+				Description:   item.description,
+				Price:         price(g.random, item.price),
+				RestaurantID:  restaurantID,
+				CategoryId:    category.ID,
+				AverageRating: round(3+g.random.Float64()*2, 1),
+				Available:     true,
+				CreatedAt:     createdAt,
+				UpdatedAt:     createdAt,
+			})
+		}
+	}
+	if err := insert(tx, menu.foods); err != nil {
+		return nil, err
+	}
+	summary.Foods += len(menu.foods)
+
+	for _, i := range g.random.Perm(len(addonTemplates))[:min(g.size.AddonsPerRestaurant, len(addonTemplates))] {
+		item := addonTemplates[i]
+		menu.addons = append(menu.addons, models.Addon{
+			Name: item.name, Type: item.kind, Price: price(g.random, item.price), RestaurantID: restaurantID,
+			CreatedAt: createdAt, UpdatedAt: createdAt,
+		})
+	}
+	if err := insert(tx, menu.addons); err != nil {
+		return nil, err
+	}
+	summary.Addons += len(menu.addons)
+
+	for i := 0; i < g.size.TablesPerRestaurant; i++ {
+		capacity := 2 + 2*g.random.Intn(4)
+		menu.tables = append(menu.tables, models.Table{
+			Name:          fmt.Sprintf("%s %d", pick(g.random, tableNames), i+1),
+			Number:        i + 1,
+			Capacity:      capacity,
+			Price:         float64(capacity) * 2,
+			AverageRating: round(3+g.random.Float64()*2, 1),
+			RestaurantID:  restaurantID,
+			CategoryId:    tableCategoryID,
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		})
+	}
+	if err := insert(tx, menu.tables); err != nil {
+		return nil, err
+	}
+	summary.Tables += len(menu.tables)
+	return menu, nil
+}
+
+// orderStatuses are drawn in these proportions, most orders of the past being done
+var orderStatuses = []struct {
+	status string
+	weight int
+}{
+	{"completed", 70},
+	{"cancelled", 10},
+	{"confirmed", 10},
+	{"pending", 10},
+}
+
+var paymentMethods = []string{"credit_card", "credit_card", "paypal", "cash"}
+
+func (g *generator) orders(tx *gorm.DB, customers []models.User, menus []*restaurantMenu, summary *Summary) error {
+	if len(menus) == 0 {
+		return nil
+	}
+
+	// customers keep going back to a few favorite restaurants, which gives the recommendations something to work with
+	var orders []models.Order
+	for _, customer := range customers {
+		favorites := []*restaurantMenu{menus[g.random.Intn(len(menus))], menus[g.random.Intn(len(menus))]}
+		for i := 0; i < g.size.OrdersPerCustomer; i++ {
+			menu := favorites[g.random.Intn(len(favorites))]
+			if g.random.Intn(4) == 0 {
+				menu = menus[g.random.Intn(len(menus))]
+			}
+			if order, ok := g.order(customer, menu); ok {
+				orders = append(orders, order)
+			}
+		}
+	}
+	// created in time order so the IDs follow the dates like they do in production
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+	if err := insert(tx, orders); err != nil {
+		return err
+	}
+	summary.Orders = len(orders)
+
+	var foodOrders []models.FoodOrder
+	var tableOrders []models.TableOrder
+	var addonOrders []models.AddonOrder
+	var payments []models.Payment
+	for _, order := range orders {
+		for _, item := range order.FoodOrders {
+			item.OrderID = order.ID
+			foodOrders = append(foodOrders, item)
+		}
+		for _, item := range order.TableOrders {
+			item.OrderID = order.ID
+			tableOrders = append(tableOrders, item)
+		}
+		for _, item := range order.AddonOrders {
+			item.OrderID = order.ID
+			addonOrders = append(addonOrders, item)
+		}
+		payments = append(payments, g.payments(order)...)
+	}
+	if err := insert(tx, foodOrders); err != nil {
+		return err
+	}
+	if err := insert(tx, tableOrders); err != nil {
+		return err
+	}
+	if err := insert(tx, addonOrders); err != nil {
+		return err
+	}
+	if err := insert(tx, payments); err != nil {
+		return err
+	}
+	summary.Payments = len(payments)
+
+	transactions := make([]models.Transaction, 0, len(payments))
+	for _, payment := range payments {
+		status := "completed"
+		switch payment.Status {
+		case "pending":
+			status = "initiated"
+		case "failed":
+			status = "failed"
+		}
+		transactions = append(transactions, models.Transaction{
+			OrderID: payment.OrderID, PaymentID: payment.ID, Status: status, Amount: payment.Amount,
+			RestaurantID: payment.RestaurantID, CreatedAt: payment.CreatedAt, UpdatedAt: payment.UpdatedAt,
+		})
+	}
+	if err := insert(tx, transactions); err != nil {
+		return err
+	}
+	summary.Transactions = len(transactions)
+	return nil
+}
+
+// order draws an order of the customer at the restaurant. Its items are kept on the order until it has an ID
+func (g *generator) order(customer models.User, menu *restaurantMenu) (models.Order, bool) {
+	if len(menu.foods) == 0 {
+		return models.Order{}, false
+	}
+
+	status := g.orderStatus()
+	createdAt := g.daysAgo(60).Add(time.Duration(11*60+g.random.Intn(10*60)) * time.Minute)
+	if status == "pending" || status == "confirmed" {
+		// orders still in progress are from the evening before
+		createdAt = g.today.Add(-time.Duration(1+g.random.Intn(6*60)) * time.Minute)
+	}
+	order := models.Order{
+		UserID:       customer.ID,
+		RestaurantID: menu.restaurant.ID,
+		Status:       status,
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt.Add(time.Duration(g.random.Intn(90)) * time.Minute),
+	}
+
+	for _, i := range g.random.Perm(len(menu.foods))[:1+g.random.Intn(min(3, len(menu.foods)))] {
+		quantity := 1 + g.random.Intn(3)
+		order.FoodOrders = append(order.FoodOrders, models.FoodOrder{FoodID: menu.foods[i].ID, Quantity: quantity})
+		order.TotalPrice += menu.foods[i].Price * float64(quantity)
+	}
+	if len(menu.tables) > 0 && g.random.Intn(2) == 0 {
+		table := menu.tables[g.random.Intn(len(menu.tables))]
+		tableID := table.ID
+		order.TableID = &tableID
+		order.TableOrders = append(order.TableOrders, models.TableOrder{TableID: table.ID})
+		order.TotalPrice += table.Price
+	}
+	if len(menu.addons) > 0 && g.random.Intn(4) == 0 {
+		addon := menu.addons[g.random.Intn(len(menu.addons))]
+		order.AddonOrders = append(order.AddonOrders, models.AddonOrder{AddonID: addon.ID, Quantity: 1})
+		order.TotalPrice += addon.Price
+	}
+	if g.random.Intn(5) == 0 {
+		order.SpecialNotes = pick(g.random, specialNotes)
+	}
+	order.TotalPrice = round(order.TotalPrice, 2)
+	return order, true
+}
+
+func (g *generator) orderStatus() string {
+	draw := g.random.Intn(100)
+	for _, candidate := range orderStatuses {
+		if draw < candidate.weight {
+			return candidate.status
+		}
+		draw -= candidate.weight
+	}
+	return orderStatuses[0].status
+}
+
+// payments draws the payments of an order: none while it is pending, sometimes a failed attempt before the
+// one that went through, and a pending one for orders cancelled before being paid
+func (g *generator) payments(order models.Order) []models.Payment {
+	if order.Status == "pending" {
+		return nil
+	}
+	payment := func(status string, at time.Time) models.Payment {
+		return models.Payment{
+			OrderID: order.ID, Amount: order.TotalPrice, Method: pick(g.random, paymentMethods), Status: status,
+			RestaurantID: order.RestaurantID, CreatedAt: at, UpdatedAt: at,
+		}
+	}
+
+	paidAt := order.CreatedAt.Add(time.Duration(1+g.random.Intn(10)) * time.Minute)
+	var payments []models.Payment
+	if g.random.Intn(10) == 0 {
+		payments = append(payments, payment("failed", paidAt))
+		paidAt = paidAt.Add(2 * time.Minute)
+	}
+	if order.Status == "cancelled" {
+		return append(payments, payment("pending", paidAt))
+	}
+	return append(payments, payment("completed", paidAt))
+}
+
+// insert creates the rows in batches and sets their IDs. Empty slices are skipped, gorm refuses them
+func insert[T any](tx *gorm.DB, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&rows, batchSize).Error
+}
+
+// daysAgo is a random day among the last days, at midnight
+func (g *generator) daysAgo(days int) time.Time {
+	return g.today.AddDate(0, 0, -1-g.random.Intn(days))
+}
+
+func (g *generator) personName() string {
+	return pick(g.random, familyNames) + " " + pick(g.random, givenNames)
+}
+
+func pick(random *rand.Rand, values []string) string {
+	return values[random.Intn(len(values))]
+}
+
+// price varies a base price by up to 20%, rounded to the half unit
+func price(random *rand.Rand, base float64) float64 {
+	return math.Round(base*(0.8+random.Float64()*0.4)*2) / 2
+}
+
+func round(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
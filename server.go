@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"madang_api/app"
+	"madang_api/metrics"
+	"madang_api/tracing"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// serve runs the API until SIGINT or SIGTERM, then stops accepting connections, lets the in-flight
+// requests and the background jobs finish within the shutdown timeout, flushes the spans left and closes
+// the database pool
+func serve(container *app.Container) {
+	cfg := container.Config
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, cfg.TracingEndpoint)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      container.Router(),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	if sqlDB, err := container.DB.DB(); err != nil {
+		slog.Error("Failed to get the database pool, its metrics are left out", "error", err)
+	} else {
+		metrics.RegisterDB(sqlDB)
+	}
+	container.StartWorkers()
+
+	failed := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	var serverErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+	case serverErr = <-failed:
+		slog.Error("Server failed", "error", serverErr)
+	}
+	// a second signal kills the process right away
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("In-flight requests did not finish in time", "error", err)
+	} else {
+		slog.Info("Server stopped accepting requests, in-flight requests finished")
+	}
+	if err := container.StopWorkers(shutdownCtx); err != nil {
+		slog.Error("Background workers did not finish in time", "error", err)
+	} else {
+		slog.Info("Background workers stopped")
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush the spans", "error", err)
+	}
+
+	if sqlDB, err := container.DB.DB(); err != nil {
+		slog.Error("Failed to get the database pool", "error", err)
+	} else if err := sqlDB.Close(); err != nil {
+		slog.Error("Failed to close the database pool", "error", err)
+	} else {
+		slog.Info("Database pool closed")
+	}
+	if serverErr != nil {
+		os.Exit(1)
+	}
+	slog.Info("Server stopped")
+}
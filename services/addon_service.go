@@ -2,22 +2,28 @@ package services
 
 import (
 	"errors"
-	"madang_api/config"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
 )
 
-type AddonService struct{}
+type AddonService struct {
+	addons repositories.AddonRepository
+}
+
+func NewAddonService(addons repositories.AddonRepository) *AddonService {
+	return &AddonService{addons: addons}
+}
 
 // Add a new addon item return the addon or error if it exist and also check if the addon exist for that restaurant
 func (s *AddonService) AddAddon(addon *models.Addon) (*models.Addon, error) {
-	var existingAddon models.Addon
 	//Check if the addon exist for that restaurant
-	if err := config.DB.Where("restaurant_id = ? AND name = ? ", existingAddon.RestaurantID, existingAddon.Name).First(&models.Addon{}).Error; err == nil {
+	if _, err := s.addons.FindByName(addon.RestaurantID, addon.Name); err == nil {
 		return nil, errors.New("addon already exists for this restaurant")
 	}
 
 	//Add the addon item
-	if err := config.DB.Create(&addon).Error; err != nil {
+	if err := s.addons.Create(addon); err != nil {
 		return nil, err
 	}
 
@@ -26,7 +32,7 @@ func (s *AddonService) AddAddon(addon *models.Addon) (*models.Addon, error) {
 
 // UpdateAddon updates an existing addon item and returns the updated addon item or an error if it fails
 func (s *AddonService) UpdateAddon(addon *models.Addon) (*models.Addon, error) {
-	if err := config.DB.Save(&addon).Error; err != nil {
+	if err := s.addons.Save(addon); err != nil {
 		return nil, err
 	}
 	return addon, nil
@@ -34,48 +40,61 @@ func (s *AddonService) UpdateAddon(addon *models.Addon) (*models.Addon, error) {
 
 // Delete a Addon by ID
 func (s *AddonService) DeleteAddon(id uint) error {
-	var addon models.Addon
-	if err := config.DB.First(&addon, id).Error; err != nil {
-		return err
-	}
-	if err := config.DB.Delete(&addon).Error; err != nil {
+	if _, err := s.addons.FindByID(id); err != nil {
 		return err
 	}
-	return nil
+	return s.addons.Delete(id)
 }
 
 // GetAddon retrieves a addon item by its ID and returns the addon item or an error if it fails
 func (s *AddonService) GetAddon(id uint) (*models.Addon, error) {
-	var addon models.Addon
-	if err := config.DB.First(&addon, id).Error; err != nil {
-		return nil, err
-	}
-	return &addon, nil
+	return s.addons.FindByID(id)
 }
 
-// GetAllAddons retrieves all addon items and returns a slice of addon items or an error if it fails
-func (s *AddonService) GetAllAddons() ([]models.Addon, error) {
-	var addons []models.Addon
-	if err := config.DB.Find(&addons).Error; err != nil {
-		return nil, err
-	}
-	return addons, nil
+// GetAllAddons retrieves a page of addon items matching the query and returns it with its pagination or an error if it fails
+func (s *AddonService) GetAllAddons(query *utils.ListQuery) ([]models.Addon, *utils.Pagination, error) {
+	return s.addons.List(query)
 }
 
 // GetRestaurantAddons retrieves all addon items for a specific restaurant and returns a slice of addon items or an error if it fails
 func (s *AddonService) GetRestaurantAddons(restaurantID uint) ([]models.Addon, error) {
-	var addons []models.Addon
-	if err := config.DB.Where("restaurant_id = ?", restaurantID).Find(&addons).Error; err != nil {
+	return s.addons.FindByRestaurant(restaurantID)
+}
+
+// AddonSearchResult is an addon matched by SearchAddons with its relevance and highlighted snippet
+type AddonSearchResult struct {
+	models.Addon
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchAddons runs a full-text search over the addon name and type, matching words as prefixes
+// and tolerating typos in the name. Results come back most relevant first
+func (s *AddonService) SearchAddons(query string, restaurantID uint) ([]AddonSearchResult, error) {
+	return searchAddons(s.addons, query, restaurantID)
+}
+
+// searchAddons pairs every addon hit with its row
+func searchAddons(repository repositories.AddonRepository, query string, restaurantID uint) ([]AddonSearchResult, error) {
+	hits, err := repository.Search(query, restaurantID)
+	if err != nil {
 		return nil, err
 	}
-	return addons, nil
-}
 
-// implement search for addon it should be by name or fhe name of the category the addon belongs too
-func (s *AddonService) SearchAddons(query string) ([]models.Addon, error) {
-	var addons []models.Addon
-	if err := config.DB.Where("name LIKE ? OR category_id IN (SELECT id FROM categories WHERE name LIKE ?)", "%"+query+"%", "%"+query+"%").Find(&addons).Error; err != nil {
+	addons, err := repository.FindByIDs(hitIDs(hits))
+	if err != nil {
 		return nil, err
 	}
-	return addons, nil
+	byID := make(map[uint]models.Addon)
+	for _, addon := range addons {
+		byID[addon.ID] = addon
+	}
+
+	results := []AddonSearchResult{}
+	for _, hit := range hits {
+		if addon, ok := byID[hit.ID]; ok {
+			results = append(results, AddonSearchResult{Addon: addon, Rank: hit.Rank, Snippet: hit.Snippet})
+		}
+	}
+	return results, nil
 }
@@ -0,0 +1,813 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"madang_api/models"
+	"madang_api/tracing"
+	"madang_api/utils"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+type BrandService struct {
+	db *gorm.DB
+}
+
+func NewBrandService(db *gorm.DB) *BrandService {
+	return &BrandService{db: db}
+}
+
+// errAlreadyBranch is returned by AddBranch for a restaurant that is already a branch of a brand
+var errAlreadyBranch = errors.New("restaurant already belongs to a brand")
+
+// BrandReport aggregates the activity of every branch of a brand over a period
+type BrandReport struct {
+	BrandID  uint               `json:"brand_id"`
+	From     *time.Time         `json:"from,omitempty"`
+	To       *time.Time         `json:"to,omitempty"`
+	Totals   BranchReport       `json:"totals"`
+	Branches []BranchReport     `json:"branches"`
+	TopFoods []BrandFoodSummary `json:"top_foods"`
+}
+
+// BranchReport is the activity of one branch, or of all of them for BrandReport.Totals
+type BranchReport struct {
+	RestaurantID      uint    `json:"restaurant_id,omitempty"`
+	Name              string  `json:"name,omitempty"`
+	Orders            int64   `json:"orders"`
+	CancelledOrders   int64   `json:"cancelled_orders"`
+	Revenue           float64 `json:"revenue"`
+	AverageOrderValue float64 `json:"average_order_value"`
+	PaymentsCollected float64 `json:"payments_collected"`
+}
+
+// BrandFoodSummary is how many times a template food was ordered across all branches
+type BrandFoodSummary struct {
+	BrandFoodID uint   `json:"brand_food_id"`
+	Name        string `json:"name"`
+	Quantity    int64  `json:"quantity"`
+}
+
+// IsBrandOwner reports whether the user owns the brand. Admins own every brand
+func (s *BrandService) IsBrandOwner(ctx context.Context, user models.User, brandID uint) bool {
+	if user.Role == "admin" {
+		return true
+	}
+	var brand models.Brand
+	if err := s.db.WithContext(ctx).Select("id", "user_id").First(&brand, brandID).Error; err != nil {
+		return false
+	}
+	return brand.UserID == user.ID
+}
+
+// CreateBrand creates a brand owned by brand.UserID
+func (s *BrandService) CreateBrand(ctx context.Context, brand *models.Brand) (_ *models.Brand, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.CreateBrand")
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	brand.Name = strings.TrimSpace(brand.Name)
+	if brand.Name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+	if err := db.Where("name = ?", brand.Name).First(&models.Brand{}).Error; err == nil {
+		return nil, errors.New("brand already exists")
+	}
+
+	var user models.User
+	if err := db.First(&user, brand.UserID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if (user.Role != "manager" && user.Role != "admin") || !user.EmailVerified {
+		return nil, errors.New("user is not authorized to create a brand")
+	}
+
+	if err := db.Omit("Foods", "Addons", "Restaurants").Create(brand).Error; err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+// GetBrand retrieves a brand with its menu template and branches
+func (s *BrandService) GetBrand(ctx context.Context, id uint) (*models.Brand, error) {
+	var brand models.Brand
+	if err := s.db.WithContext(ctx).Preload("Foods", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Addons", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Restaurants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&brand, id).Error; err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// GetAllBrands retrieves a page of brands matching the query
+func (s *BrandService) GetAllBrands(ctx context.Context, query *utils.ListQuery) ([]models.Brand, *utils.Pagination, error) {
+	brands := []models.Brand{}
+	pagination, err := utils.Paginate(s.db.WithContext(ctx).Model(&models.Brand{}), query, &brands)
+	if err != nil {
+		return nil, nil, err
+	}
+	return brands, pagination, nil
+}
+
+// UpdateBrand updates the details of a brand
+func (s *BrandService) UpdateBrand(ctx context.Context, brand *models.Brand) (_ *models.Brand, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.UpdateBrand", attribute.Int64("brand.id", int64(brand.ID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var existingBrand models.Brand
+	if err := db.First(&existingBrand, brand.ID).Error; err != nil {
+		return nil, err
+	}
+	if brand.Name != "" && brand.Name != existingBrand.Name {
+		if err := db.Where("name = ? AND id <> ?", brand.Name, brand.ID).First(&models.Brand{}).Error; err == nil {
+			return nil, errors.New("brand already exists")
+		}
+	}
+
+	if err := db.Model(&existingBrand).Updates(models.Brand{
+		Name:        brand.Name,
+		Description: brand.Description,
+		Image:       brand.Image,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return s.GetBrand(ctx, brand.ID)
+}
+
+// DeleteBrand deletes a brand and its template. Branches become independent restaurants and keep
+// their menu as regular items
+func (s *BrandService) DeleteBrand(ctx context.Context, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.DeleteBrand", attribute.Int64("brand.id", int64(id)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var brand models.Brand
+	if err := db.First(&brand, id).Error; err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var branchIDs []uint
+		if err := tx.Model(&models.Restaurant{}).Where("brand_id = ?", id).Pluck("id", &branchIDs).Error; err != nil {
+			return err
+		}
+		for _, branchID := range branchIDs {
+			if err := detachBranch(tx, branchID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("brand_id = ?", id).Delete(&models.BrandFood{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("brand_id = ?", id).Delete(&models.BrandAddon{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&brand).Error
+	})
+}
+
+// AddBranch attaches a restaurant to a brand and gives it the brand's menu. The user adding it must
+// manage the restaurant, unless the restaurant is already managed by the brand owner
+func (s *BrandService) AddBranch(ctx context.Context, actor models.User, brandID uint, restaurantID uint) (_ *models.Restaurant, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.AddBranch",
+		attribute.Int64("brand.id", int64(brandID)),
+		attribute.Int64("restaurant.id", int64(restaurantID)),
+	)
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var brand models.Brand
+	if err := db.First(&brand, brandID).Error; err != nil {
+		return nil, errors.New("brand not found")
+	}
+	var restaurant models.Restaurant
+	if err := db.First(&restaurant, restaurantID).Error; err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	if restaurant.BrandID != nil {
+		return nil, errAlreadyBranch
+	}
+	if actor.Role != "admin" && restaurant.UserID != actor.ID && restaurant.UserID != brand.UserID {
+		return nil, errors.New("only the manager of the restaurant can add it to a brand")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// only joins if no concurrent request attached the restaurant to a brand since it was read
+		result := tx.Model(&models.Restaurant{}).Where("id = ? AND brand_id IS NULL", restaurantID).Update("brand_id", brandID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyBranch
+		}
+		return syncBranch(tx, brandID, restaurantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	restaurant.BrandID = &brandID
+	return &restaurant, nil
+}
+
+// RemoveBranch detaches a restaurant from its brand. It keeps its menu as regular items
+func (s *BrandService) RemoveBranch(ctx context.Context, brandID uint, restaurantID uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.RemoveBranch",
+		attribute.Int64("brand.id", int64(brandID)),
+		attribute.Int64("restaurant.id", int64(restaurantID)),
+	)
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var restaurant models.Restaurant
+	if err := db.Where("brand_id = ?", brandID).First(&restaurant, restaurantID).Error; err != nil {
+		return errors.New("restaurant is not a branch of this brand")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return detachBranch(tx, restaurantID)
+	})
+}
+
+// SetBranchManager makes another user the manager of one of the brand's branches
+func (s *BrandService) SetBranchManager(ctx context.Context, brandID uint, restaurantID uint, userID uint) (_ *models.Restaurant, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.SetBranchManager",
+		attribute.Int64("brand.id", int64(brandID)),
+		attribute.Int64("restaurant.id", int64(restaurantID)),
+	)
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var restaurant models.Restaurant
+	if err := db.Where("brand_id = ?", brandID).First(&restaurant, restaurantID).Error; err != nil {
+		return nil, errors.New("restaurant is not a branch of this brand")
+	}
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Role != "manager" || !user.EmailVerified {
+		return nil, errors.New("user must be a manager with a verified email")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&restaurant).Update("user_id", userID).Error; err != nil {
+			return err
+		}
+		return notify(tx, userID, "branch_manager", "New branch",
+			fmt.Sprintf("You are now the manager of %s.", restaurant.Name))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+// SyncBrand pushes the brand's menu template to every branch
+func (s *BrandService) SyncBrand(ctx context.Context, brandID uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.SyncBrand", attribute.Int64("brand.id", int64(brandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	if err := db.First(&models.Brand{}, brandID).Error; err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return syncBrand(tx, brandID)
+	})
+}
+
+// AddTemplateFood adds a food to the brand menu and to every branch
+func (s *BrandService) AddTemplateFood(ctx context.Context, food *models.BrandFood) (_ *models.BrandFood, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.AddTemplateFood", attribute.Int64("brand.id", int64(food.BrandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	if err := s.validateTemplateItem(ctx, food.BrandID, food.Name, food.Price); err != nil {
+		return nil, err
+	}
+	if err := db.Where("brand_id = ? AND lower(name) = lower(?)", food.BrandID, food.Name).First(&models.BrandFood{}).Error; err == nil {
+		return nil, errors.New("the brand menu already has a food with this name")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(food).Error; err != nil {
+			return err
+		}
+		return syncBrand(tx, food.BrandID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return food, nil
+}
+
+// UpdateTemplateFood changes a food of the brand menu and every branch copy of it
+func (s *BrandService) UpdateTemplateFood(ctx context.Context, food *models.BrandFood) (_ *models.BrandFood, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.UpdateTemplateFood", attribute.Int64("brand.id", int64(food.BrandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var existingFood models.BrandFood
+	if err := db.Where("brand_id = ?", food.BrandID).First(&existingFood, food.ID).Error; err != nil {
+		return nil, err
+	}
+	if food.Price < 0 {
+		return nil, errors.New("price cannot be negative")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&existingFood).Updates(models.BrandFood{
+			Name:         food.Name,
+			Description:  food.Description,
+			Image:        food.Image,
+			Price:        food.Price,
+			Category:     food.Category,
+			CategoryType: food.CategoryType,
+		}).Error; err != nil {
+			return err
+		}
+		return syncBrand(tx, food.BrandID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &existingFood, nil
+}
+
+// DeleteTemplateFood removes a food from the brand menu and from every branch. Branch copies that have
+// been ordered or rated stay behind unavailable, as foods of the branch (see retireFoods)
+func (s *BrandService) DeleteTemplateFood(ctx context.Context, brandID uint, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.DeleteTemplateFood", attribute.Int64("brand.id", int64(brandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var food models.BrandFood
+	if err := db.Where("brand_id = ?", brandID).First(&food, id).Error; err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var copies []models.Food
+		if err := tx.Where("brand_food_id = ?", id).Find(&copies).Error; err != nil {
+			return err
+		}
+		if err := retireFoods(tx, copies); err != nil {
+			return err
+		}
+		if err := tx.Where("brand_food_id = ?", id).Delete(&models.BranchOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&food).Error
+	})
+}
+
+// AddTemplateAddon adds an addon to the brand menu and to every branch
+func (s *BrandService) AddTemplateAddon(ctx context.Context, addon *models.BrandAddon) (_ *models.BrandAddon, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.AddTemplateAddon", attribute.Int64("brand.id", int64(addon.BrandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	if err := s.validateTemplateItem(ctx, addon.BrandID, addon.Name, addon.Price); err != nil {
+		return nil, err
+	}
+	if err := db.Where("brand_id = ? AND lower(name) = lower(?)", addon.BrandID, addon.Name).First(&models.BrandAddon{}).Error; err == nil {
+		return nil, errors.New("the brand menu already has an addon with this name")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(addon).Error; err != nil {
+			return err
+		}
+		return syncBrand(tx, addon.BrandID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addon, nil
+}
+
+// UpdateTemplateAddon changes an addon of the brand menu and every branch copy of it
+func (s *BrandService) UpdateTemplateAddon(ctx context.Context, addon *models.BrandAddon) (_ *models.BrandAddon, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.UpdateTemplateAddon", attribute.Int64("brand.id", int64(addon.BrandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var existingAddon models.BrandAddon
+	if err := db.Where("brand_id = ?", addon.BrandID).First(&existingAddon, addon.ID).Error; err != nil {
+		return nil, err
+	}
+	if addon.Price < 0 {
+		return nil, errors.New("price cannot be negative")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&existingAddon).Updates(models.BrandAddon{
+			Name:  addon.Name,
+			Type:  addon.Type,
+			Price: addon.Price,
+		}).Error; err != nil {
+			return err
+		}
+		return syncBrand(tx, addon.BrandID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &existingAddon, nil
+}
+
+// DeleteTemplateAddon removes an addon from the brand menu and from every branch. Branch copies that have
+// been ordered stay behind as addons of the branch (see retireAddons)
+func (s *BrandService) DeleteTemplateAddon(ctx context.Context, brandID uint, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.DeleteTemplateAddon", attribute.Int64("brand.id", int64(brandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var addon models.BrandAddon
+	if err := db.Where("brand_id = ?", brandID).First(&addon, id).Error; err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var copies []models.Addon
+		if err := tx.Where("brand_addon_id = ?", id).Find(&copies).Error; err != nil {
+			return err
+		}
+		if err := retireAddons(tx, copies); err != nil {
+			return err
+		}
+		if err := tx.Where("brand_addon_id = ?", id).Delete(&models.BranchOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&addon).Error
+	})
+}
+
+// GetBranchOverrides retrieves the price and availability overrides of a branch
+func (s *BrandService) GetBranchOverrides(ctx context.Context, restaurantID uint) ([]models.BranchOverride, error) {
+	var overrides []models.BranchOverride
+	if err := s.db.WithContext(ctx).Where("restaurant_id = ?", restaurantID).Order("id").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// SetBranchOverride creates or replaces the override of one template item for a branch and applies it
+func (s *BrandService) SetBranchOverride(ctx context.Context, override *models.BranchOverride) (_ *models.BranchOverride, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.SetBranchOverride", attribute.Int64("restaurant.id", int64(override.RestaurantID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var restaurant models.Restaurant
+	if err := db.First(&restaurant, override.RestaurantID).Error; err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	if restaurant.BrandID == nil {
+		return nil, errors.New("restaurant is not a branch of a brand")
+	}
+	if (override.BrandFoodID == nil) == (override.BrandAddonID == nil) {
+		return nil, errors.New("exactly one of brand_food_id and brand_addon_id is required")
+	}
+	if override.Price != nil && *override.Price < 0 {
+		return nil, errors.New("price cannot be negative")
+	}
+	if override.BrandAddonID != nil && override.Available != nil {
+		return nil, errors.New("availability can only be overridden for foods")
+	}
+
+	column, itemID := "brand_food_id", override.BrandFoodID
+	if override.BrandFoodID != nil {
+		if err := db.Where("brand_id = ?", *restaurant.BrandID).First(&models.BrandFood{}, *override.BrandFoodID).Error; err != nil {
+			return nil, errors.New("food is not part of the brand menu")
+		}
+	} else {
+		column, itemID = "brand_addon_id", override.BrandAddonID
+		if err := db.Where("brand_id = ?", *restaurant.BrandID).First(&models.BrandAddon{}, *override.BrandAddonID).Error; err != nil {
+			return nil, errors.New("addon is not part of the brand menu")
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var existingOverride models.BranchOverride
+		if err := tx.Where("restaurant_id = ? AND "+column+" = ?", restaurant.ID, *itemID).First(&existingOverride).Error; err == nil {
+			override.ID = existingOverride.ID
+			override.CreatedAt = existingOverride.CreatedAt
+		} else {
+			override.ID = 0
+		}
+		if err := tx.Save(override).Error; err != nil {
+			return err
+		}
+		return syncBranch(tx, *restaurant.BrandID, restaurant.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// DeleteBranchOverride removes an override so the branch goes back to the template value
+func (s *BrandService) DeleteBranchOverride(ctx context.Context, restaurantID uint, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.DeleteBranchOverride", attribute.Int64("restaurant.id", int64(restaurantID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	var override models.BranchOverride
+	if err := db.Where("restaurant_id = ?", restaurantID).First(&override, id).Error; err != nil {
+		return err
+	}
+	var restaurant models.Restaurant
+	if err := db.First(&restaurant, restaurantID).Error; err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&override).Error; err != nil {
+			return err
+		}
+		if restaurant.BrandID == nil {
+			return nil
+		}
+		return syncBranch(tx, *restaurant.BrandID, restaurantID)
+	})
+}
+
+// GetBrandReport aggregates orders, revenue and payments of every branch between from and to (both optional)
+func (s *BrandService) GetBrandReport(ctx context.Context, brandID uint, from *time.Time, to *time.Time) (_ *BrandReport, err error) {
+	ctx, span := tracing.Start(ctx, "BrandService.GetBrandReport", attribute.Int64("brand.id", int64(brandID)))
+	defer tracing.End(span, &err)
+	db := s.db.WithContext(ctx)
+
+	if err := db.First(&models.Brand{}, brandID).Error; err != nil {
+		return nil, err
+	}
+
+	var branches []models.Restaurant
+	if err := db.Select("id", "name").Where("brand_id = ?", brandID).Order("id").Find(&branches).Error; err != nil {
+		return nil, err
+	}
+
+	period := func(db *gorm.DB, column string) *gorm.DB {
+		if from != nil {
+			db = db.Where(column+" >= ?", *from)
+		}
+		if to != nil {
+			db = db.Where(column+" < ?", *to)
+		}
+		return db
+	}
+
+	report := &BrandReport{
+		BrandID:  brandID,
+		From:     from,
+		To:       to,
+		Branches: []BranchReport{},
+		TopFoods: []BrandFoodSummary{},
+	}
+	for _, branch := range branches {
+		branchReport := BranchReport{RestaurantID: branch.ID, Name: branch.Name}
+
+		var orders struct {
+			Orders    int64
+			Cancelled int64
+			Revenue   float64
+		}
+		if err := period(db.Model(&models.Order{}), "created_at").
+			Select("count(*) AS orders, count(*) FILTER (WHERE status = 'cancelled') AS cancelled, coalesce(sum(total_price) FILTER (WHERE status <> 'cancelled'), 0) AS revenue").
+			Where("restaurant_id = ?", branch.ID).
+			Scan(&orders).Error; err != nil {
+			return nil, err
+		}
+		branchReport.Orders = orders.Orders
+		branchReport.CancelledOrders = orders.Cancelled
+		branchReport.Revenue = orders.Revenue
+		if orders.Orders > orders.Cancelled {
+			branchReport.AverageOrderValue = orders.Revenue / float64(orders.Orders-orders.Cancelled)
+		}
+
+		if err := period(db.Model(&models.Payment{}), "created_at").
+			Select("coalesce(sum(amount), 0)").
+			Where("restaurant_id = ? AND status = ?", branch.ID, "completed").
+			Scan(&branchReport.PaymentsCollected).Error; err != nil {
+			return nil, err
+		}
+
+		report.Branches = append(report.Branches, branchReport)
+		report.Totals.Orders += branchReport.Orders
+		report.Totals.CancelledOrders += branchReport.CancelledOrders
+		report.Totals.Revenue += branchReport.Revenue
+		report.Totals.PaymentsCollected += branchReport.PaymentsCollected
+	}
+	if served := report.Totals.Orders - report.Totals.CancelledOrders; served > 0 {
+		report.Totals.AverageOrderValue = report.Totals.Revenue / float64(served)
+	}
+
+	if err := period(db.Table("food_orders"), "orders.created_at").
+		Select("brand_foods.id AS brand_food_id, brand_foods.name AS name, sum(food_orders.quantity) AS quantity").
+		Joins("JOIN orders ON orders.id = food_orders.order_id").
+		Joins("JOIN foods ON foods.id = food_orders.food_id").
+		Joins("JOIN brand_foods ON brand_foods.id = foods.brand_food_id").
+		Where("brand_foods.brand_id = ? AND orders.status <> ?", brandID, "cancelled").
+		Group("brand_foods.id, brand_foods.name").
+		Order("quantity DESC").
+		Limit(10).
+		Scan(&report.TopFoods).Error; err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// validateTemplateItem checks the fields shared by template foods and addons
+func (s *BrandService) validateTemplateItem(ctx context.Context, brandID uint, name string, price float64) error {
+	if err := s.db.WithContext(ctx).First(&models.Brand{}, brandID).Error; err != nil {
+		return errors.New("brand not found")
+	}
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name cannot be empty")
+	}
+	if price < 0 {
+		return errors.New("price cannot be negative")
+	}
+	return nil
+}
+
+// syncBrand pushes the brand's menu template to every branch
+func syncBrand(tx *gorm.DB, brandID uint) error {
+	var branchIDs []uint
+	if err := tx.Model(&models.Restaurant{}).Where("brand_id = ?", brandID).Pluck("id", &branchIDs).Error; err != nil {
+		return err
+	}
+	for _, branchID := range branchIDs {
+		if err := syncBranch(tx, brandID, branchID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncBranch makes the template items of a branch match the brand menu, applying the branch's overrides.
+// Items the branch added itself are left alone, and a template item is not copied to a branch already
+// having an item of its own with the same name
+func syncBranch(tx *gorm.DB, brandID uint, restaurantID uint) error {
+	var foods []models.BrandFood
+	if err := tx.Where("brand_id = ?", brandID).Find(&foods).Error; err != nil {
+		return err
+	}
+	var addons []models.BrandAddon
+	if err := tx.Where("brand_id = ?", brandID).Find(&addons).Error; err != nil {
+		return err
+	}
+	var overrides []models.BranchOverride
+	if err := tx.Where("restaurant_id = ?", restaurantID).Find(&overrides).Error; err != nil {
+		return err
+	}
+	foodOverrides := make(map[uint]models.BranchOverride)
+	addonOverrides := make(map[uint]models.BranchOverride)
+	for _, override := range overrides {
+		if override.BrandFoodID != nil {
+			foodOverrides[*override.BrandFoodID] = override
+		}
+		if override.BrandAddonID != nil {
+			addonOverrides[*override.BrandAddonID] = override
+		}
+	}
+
+	var foodIDs []uint
+	for _, template := range foods {
+		brandFoodID := template.ID
+		var food models.Food
+		err := tx.Where("restaurant_id = ? AND brand_food_id = ?", restaurantID, template.ID).First(&food).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if food.ID == 0 {
+			taken, err := branchHasOwn(tx, &models.Food{}, "brand_food_id", restaurantID, template.Name)
+			if err != nil {
+				return err
+			}
+			if taken {
+				continue
+			}
+		}
+
+		categoryID, err := branchCategory(tx, restaurantID, template.Category, template.CategoryType)
+		if err != nil {
+			return err
+		}
+		price := template.Price
+		if override, ok := foodOverrides[template.ID]; ok && override.Price != nil {
+			price = *override.Price
+		}
+		food.Name = template.Name
+		food.Description = template.Description
+		food.Image = template.Image
+		food.Price = price
+		food.CategoryId = categoryID
+		food.RestaurantID = restaurantID
+		food.BrandFoodID = &brandFoodID
+		if food.ID == 0 {
+			food.Available = true
+			if err := tx.Create(&food).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&food).Select("name", "description", "image", "price", "category_id").Updates(&food).Error; err != nil {
+			return err
+		}
+		foodIDs = append(foodIDs, food.ID)
+	}
+
+	for _, template := range addons {
+		price := template.Price
+		if override, ok := addonOverrides[template.ID]; ok && override.Price != nil {
+			price = *override.Price
+		}
+
+		brandAddonID := template.ID
+		var addon models.Addon
+		err := tx.Where("restaurant_id = ? AND brand_addon_id = ?", restaurantID, template.ID).First(&addon).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if addon.ID == 0 {
+			taken, err := branchHasOwn(tx, &models.Addon{}, "brand_addon_id", restaurantID, template.Name)
+			if err != nil {
+				return err
+			}
+			if taken {
+				continue
+			}
+		}
+		addon.Name = template.Name
+		addon.Type = template.Type
+		addon.Price = price
+		addon.RestaurantID = restaurantID
+		addon.BrandAddonID = &brandAddonID
+		if err := tx.Save(&addon).Error; err != nil {
+			return err
+		}
+	}
+
+	// availability depends on both the overrides and the ingredient stock
+	return refreshFoodsByID(tx, foodIDs)
+}
+
+// branchHasOwn reports whether a branch has an item of its own, not synced through brandColumn, with the name
+func branchHasOwn(tx *gorm.DB, model interface{}, brandColumn string, restaurantID uint, name string) (bool, error) {
+	var count int64
+	err := tx.Model(model).Where("restaurant_id = ? AND "+brandColumn+" IS NULL AND lower(name) = lower(?)", restaurantID, name).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// branchCategory finds the branch category with the given name, creating it when missing
+func branchCategory(tx *gorm.DB, restaurantID uint, name string, categoryType string) (uint, error) {
+	if name == "" {
+		return 0, nil
+	}
+	var category models.Category
+	err := tx.Where("restaurant_id = ? AND name = ?", restaurantID, name).First(&category).Error
+	if err == nil {
+		return category.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	if categoryType == "" {
+		categoryType = "food"
+	}
+	category = models.Category{Name: name, Type: categoryType, RestaurantID: restaurantID}
+	if err := tx.Create(&category).Error; err != nil {
+		return 0, err
+	}
+	return category.ID, nil
+}
+
+// detachBranch removes a restaurant from its brand, turning its template items into regular items
+func detachBranch(tx *gorm.DB, restaurantID uint) error {
+	var foodIDs []uint
+	if err := tx.Model(&models.Food{}).Where("restaurant_id = ? AND brand_food_id IS NOT NULL", restaurantID).Pluck("id", &foodIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Food{}).Where("restaurant_id = ?", restaurantID).Update("brand_food_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Addon{}).Where("restaurant_id = ?", restaurantID).Update("brand_addon_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("restaurant_id = ?", restaurantID).Delete(&models.BranchOverride{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Restaurant{}).Where("id = ?", restaurantID).Update("brand_id", nil).Error; err != nil {
+		return err
+	}
+	// foods the branch had switched off go back to depending on stock only
+	return refreshFoodsByID(tx, foodIDs)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache keeps values in memory for a fixed time. It is meant for data that many requests read and
+// that may be a little stale, such as the menu sections of the home feed
+type ttlCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// drop expired entries now and then so restaurants nobody looks at anymore do not pile up
+	if len(c.entries) > 1000 {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// cached returns the cached value for key, loading and caching it on a miss. Errors are not cached
+func cached[T any](c *ttlCache, key string, load func() (T, error)) (T, error) {
+	if value, ok := c.get(key); ok {
+		return value.(T), nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	c.set(key, value)
+	return value, nil
+}
@@ -1,55 +1,95 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"madang_api/config"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
 )
 
-type CategoryService struct{}
+type CategoryService struct {
+	categories repositories.CategoryRepository
+}
+
+func NewCategoryService(categories repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{categories: categories}
+}
 
-func (s *CategoryService) GetCategories() ([]models.Category, error) {
-	var categories []models.Category
-	result := config.DB.Find(&categories)
-	return categories, result.Error
+func (s *CategoryService) GetCategories(query *utils.ListQuery) ([]models.Category, *utils.Pagination, error) {
+	return s.categories.List(query)
 }
 
 func (s *CategoryService) GetCategory(id uint) (models.Category, error) {
-	var category models.Category
-	result := config.DB.First(&category, id)
-	return category, result.Error
+	category, err := s.categories.FindByID(id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	return *category, nil
 }
 
 func (s *CategoryService) CreateCategory(category *models.Category) (*models.Category, error) {
-	var existingCategory models.Category
-	if err := config.DB.Where("name = ? AND restaurant_id = ?", category.Name, category.RestaurantID).First(&existingCategory).Error; err == nil {
+	if _, err := s.categories.FindByName(category.RestaurantID, category.Name); err == nil {
 		return nil, errors.New("a category with the same name already exists")
 	}
 
-	result := config.DB.Create(category)
-	if result.Error != nil {
-		return nil, result.Error
+	if err := s.categories.Create(category); err != nil {
+		return nil, err
 	}
 
 	return category, nil
 }
 
 func (s *CategoryService) UpdateCategory(category *models.Category) (*models.Category, error) {
-	result := config.DB.Save(category)
-	if result.Error != nil {
-		return nil, result.Error
+	if err := s.categories.Save(category); err != nil {
+		return nil, err
 	}
 
 	return category, nil
 }
 
 func (s *CategoryService) DeleteCategory(id uint) error {
-	result := config.DB.Delete(&models.Category{}, id)
-	return result.Error
+	return s.categories.Delete(id)
+}
+
+func (s *CategoryService) GetRestaurantCategories(ctx context.Context, restaurantID uint) ([]models.Category, error) {
+	return s.categories.WithContext(ctx).FindByRestaurant(restaurantID)
+}
+
+// CategorySearchResult is a category matched by SearchCategories with its relevance and highlighted snippet
+type CategorySearchResult struct {
+	models.Category
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
 }
 
-func (s *CategoryService) GetRestaurantCategories(restaurantID uint) ([]models.Category, error) {
-	var categories []models.Category
-	result := config.DB.Where("restaurant_id = ?", restaurantID).Find(&categories)
-	return categories, result.Error
+// SearchCategories runs a full-text search over the category name and type, matching words as prefixes
+// and tolerating typos in the name. Results come back most relevant first
+func (s *CategoryService) SearchCategories(query string, restaurantID uint) ([]CategorySearchResult, error) {
+	return searchCategories(s.categories, query, restaurantID)
+}
+
+// searchCategories loads the hit categories, most relevant first
+func searchCategories(repository repositories.CategoryRepository, query string, restaurantID uint) ([]CategorySearchResult, error) {
+	hits, err := repository.Search(query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := repository.FindByIDs(hitIDs(hits))
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Category)
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	results := []CategorySearchResult{}
+	for _, hit := range hits {
+		if category, ok := byID[hit.ID]; ok {
+			results = append(results, CategorySearchResult{Category: category, Rank: hit.Rank, Snippet: hit.Snippet})
+		}
+	}
+	return results, nil
 }
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"madang_api/config"
+	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/tracing"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// FeedService builds the home feed out of the other services and a few queries of its own over db
+type FeedService struct {
+	config      *config.Config
+	db          *gorm.DB
+	restaurants *RestaurantService
+	categories  *CategoryService
+	foods       *FoodService
+	tables      *TableService
+	cache       *ttlCache // the restaurant wide sections of the home feed
+}
+
+// NewFeedService creates the feed service with a cache living for cfg.FeedCacheTTL
+func NewFeedService(cfg *config.Config, db *gorm.DB, restaurants *RestaurantService, categories *CategoryService, foods *FoodService, tables *TableService) *FeedService {
+	return &FeedService{
+		config:      cfg,
+		db:          db,
+		restaurants: restaurants,
+		categories:  categories,
+		foods:       foods,
+		tables:      tables,
+		cache:       newTTLCache(cfg.FeedCacheTTL),
+	}
+}
+
+// HomeFeed is everything the app shows on its home screen for one user
+type HomeFeed struct {
+	Restaurant         *models.Restaurant  `json:"restaurant"` // the restaurant the menu sections belong to, nil when none could be picked
+	RestaurantSource   string              `json:"restaurant_source,omitempty"`
+	Categories         []models.Category   `json:"categories"`
+	Foods              []models.Food       `json:"foods"`
+	Tables             []models.Table      `json:"tables"`
+	RecommendedFoods   []models.Food       `json:"recommended_foods"`
+	RecommendedTables  []models.Table      `json:"recommended_tables"`
+	NearbyRestaurants  []NearbyRestaurant  `json:"nearby_restaurants"`
+	RecentRestaurants  []models.Restaurant `json:"recent_restaurants"`
+	PopularFoods       []PopularFood       `json:"popular_foods"`
+	ReorderSuggestions []ReorderSuggestion `json:"reorder_suggestions"`
+	ActiveOrders       []models.Order      `json:"active_orders"`
+	FailedSections     []SectionError      `json:"failed_sections"`
+}
+
+// SectionError tells the client which part of the home feed could not be loaded and why
+type SectionError struct {
+	Section string `json:"section"`
+	Error   string `json:"error"`
+}
+
+// FeedOptions are what the client tells us about where the user is and what they are looking at
+type FeedOptions struct {
+	RestaurantID uint // explicitly chosen restaurant, 0 when none
+	Latitude     *float64
+	Longitude    *float64
+}
+
+// PopularFood is a food together with how many times it was ordered recently
+type PopularFood struct {
+	models.Food
+	OrderCount int64 `json:"order_count"`
+}
+
+// ReorderSuggestion is a food the user ordered before and may want again
+type ReorderSuggestion struct {
+	Food          models.Food `json:"food"`
+	TimesOrdered  int64       `json:"times_ordered"`
+	LastOrderedAt time.Time   `json:"last_ordered_at"`
+}
+
+const (
+	feedNearbyRadiusKm = 10
+	feedSectionLimit   = 10
+	popularFoodsWindow = 30 * 24 * time.Hour
+)
+
+// closedOrderStatuses are the statuses of orders that need nothing more from the user
+var closedOrderStatuses = []string{"completed", "cancelled"}
+
+// GetHomeFeed builds the home feed of a user. The menu sections belong to the restaurant the user chose,
+// else the one they last ordered from, else the nearest open one, else the configured default restaurant.
+// Sections are loaded concurrently, each with its own timeout; a section that fails or times out is left
+// empty and listed in FailedSections so the rest of the feed can still be shown
+func (s *FeedService) GetHomeFeed(ctx context.Context, userID uint, options FeedOptions) *HomeFeed {
+	ctx, span := tracing.Start(ctx, "FeedService.GetHomeFeed")
+	defer span.End()
+
+	feed := &HomeFeed{
+		Categories:         []models.Category{},
+		Foods:              []models.Food{},
+		Tables:             []models.Table{},
+		RecommendedFoods:   []models.Food{},
+		RecommendedTables:  []models.Table{},
+		NearbyRestaurants:  []NearbyRestaurant{},
+		RecentRestaurants:  []models.Restaurant{},
+		PopularFoods:       []PopularFood{},
+		ReorderSuggestions: []ReorderSuggestion{},
+		ActiveOrders:       []models.Order{},
+		FailedSections:     []SectionError{},
+	}
+
+	// the user specific sections, some of which decide which restaurant the feed shows
+	sections := []feedSection{
+		section("recent_restaurants", &feed.RecentRestaurants, func(ctx context.Context) ([]models.Restaurant, error) {
+			return s.GetRecentRestaurants(ctx, userID, feedSectionLimit)
+		}),
+		section("reorder_suggestions", &feed.ReorderSuggestions, func(ctx context.Context) ([]ReorderSuggestion, error) {
+			return s.GetReorderSuggestions(ctx, userID, feedSectionLimit)
+		}),
+		section("active_orders", &feed.ActiveOrders, func(ctx context.Context) ([]models.Order, error) {
+			return s.GetActiveOrders(ctx, userID)
+		}),
+	}
+	if options.Latitude != nil && options.Longitude != nil {
+		sections = append(sections, section("nearby_restaurants", &feed.NearbyRestaurants, func(ctx context.Context) ([]NearbyRestaurant, error) {
+			nearby, err := s.restaurants.GetNearbyRestaurants(ctx, *options.Latitude, *options.Longitude, feedNearbyRadiusKm, false)
+			if len(nearby) > feedSectionLimit {
+				nearby = nearby[:feedSectionLimit]
+			}
+			return nearby, err
+		}))
+	}
+	feed.FailedSections = append(feed.FailedSections, runSections(ctx, sections, s.config.FeedSectionTimeout)...)
+
+	// the restaurant wide sections are the same for every user, so they are cached for a short while
+	restaurantID, source := s.pickRestaurant(options, feed)
+	sections = []feedSection{
+		section("popular_foods", &feed.PopularFoods, func(ctx context.Context) ([]PopularFood, error) {
+			return cached(s.cache, fmt.Sprintf("popular_foods:%d", restaurantID), func() ([]PopularFood, error) {
+				return s.GetPopularFoods(ctx, restaurantID, feedSectionLimit)
+			})
+		}),
+	}
+	if restaurantID != 0 {
+		feed.RestaurantSource = source
+		sections = append(sections,
+			section("restaurant", &feed.Restaurant, func(ctx context.Context) (*models.Restaurant, error) {
+				// not cached, whether it is open changes by the minute
+				restaurant, err := s.restaurants.GetRestaurantByID(ctx, restaurantID)
+				return &restaurant, err
+			}),
+			section("categories", &feed.Categories, func(ctx context.Context) ([]models.Category, error) {
+				return cached(s.cache, fmt.Sprintf("categories:%d", restaurantID), func() ([]models.Category, error) {
+					return s.categories.GetRestaurantCategories(ctx, restaurantID)
+				})
+			}),
+			section("foods", &feed.Foods, func(ctx context.Context) ([]models.Food, error) {
+				return cached(s.cache, fmt.Sprintf("foods:%d", restaurantID), func() ([]models.Food, error) {
+					return s.foods.GetRestaurantFoods(ctx, restaurantID)
+				})
+			}),
+			section("tables", &feed.Tables, func(ctx context.Context) ([]models.Table, error) {
+				return cached(s.cache, fmt.Sprintf("tables:%d", restaurantID), func() ([]models.Table, error) {
+					return s.tables.GetRestaurantTables(ctx, restaurantID)
+				})
+			}),
+			section("recommended_foods", &feed.RecommendedFoods, func(ctx context.Context) ([]models.Food, error) {
+				return s.foods.GetRecommendedFoods(ctx, userID, restaurantID)
+			}),
+			section("recommended_tables", &feed.RecommendedTables, func(ctx context.Context) ([]models.Table, error) {
+				return s.tables.GetRecommendedTables(ctx, userID, restaurantID)
+			}),
+		)
+	}
+	feed.FailedSections = append(feed.FailedSections, runSections(ctx, sections, s.config.FeedSectionTimeout)...)
+
+	return feed
+}
+
+// feedSection is one independently loaded part of the home feed. load runs in its own goroutine and
+// returns a function that stores the result, which is only called from the goroutine building the feed
+type feedSection struct {
+	name string
+	load func(ctx context.Context) (func(), error)
+}
+
+// section wraps a loader so its result ends up in target
+func section[T any](name string, target *T, load func(ctx context.Context) (T, error)) feedSection {
+	return feedSection{name: name, load: func(ctx context.Context) (func(), error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func() { *target = value }, nil
+	}}
+}
+
+// runSections loads the sections concurrently, each within timeout, and stores the results of the ones
+// that finish in time. Sections that fail or time out are returned
+func runSections(ctx context.Context, sections []feedSection, timeout time.Duration) []SectionError {
+	type result struct {
+		name  string
+		store func()
+		err   error
+	}
+
+	results := make(chan result, len(sections)) // buffered so late sections never block once we stop waiting
+	for _, sec := range sections {
+		go func(sec feedSection) {
+			sectionCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan result, 1)
+			go func() {
+				spanCtx, span := tracing.Start(sectionCtx, "FeedService.section", attribute.String("feed.section", sec.name))
+				store, err := sec.load(spanCtx)
+				tracing.End(span, &err)
+				done <- result{name: sec.name, store: store, err: err}
+			}()
+			select {
+			case res := <-done:
+				results <- res
+			case <-sectionCtx.Done():
+				results <- result{name: sec.name, err: fmt.Errorf("timed out after %s", timeout)}
+			}
+		}(sec)
+	}
+
+	failed := []SectionError{}
+	for range sections {
+		res := <-results
+		if res.err != nil {
+			failed = append(failed, SectionError{Section: res.name, Error: res.err.Error()})
+			continue
+		}
+		res.store()
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Section < failed[j].Section })
+	return failed
+}
+
+// pickRestaurant chooses the restaurant whose menu the feed shows and says why it was chosen
+func (s *FeedService) pickRestaurant(options FeedOptions, feed *HomeFeed) (uint, string) {
+	if options.RestaurantID != 0 {
+		return options.RestaurantID, "selected"
+	}
+	if len(feed.RecentRestaurants) > 0 {
+		return feed.RecentRestaurants[0].ID, "recent"
+	}
+	for _, nearby := range feed.NearbyRestaurants {
+		if nearby.OpenNow {
+			return nearby.ID, "nearby"
+		}
+	}
+	if len(feed.NearbyRestaurants) > 0 {
+		return feed.NearbyRestaurants[0].ID, "nearby"
+	}
+	if id := s.config.DefaultRestaurantID; id != 0 {
+		return id, "default"
+	}
+	return 0, ""
+}
+
+// GetRecentRestaurants retrieves the restaurants a user ordered from, most recent first
+func (s *FeedService) GetRecentRestaurants(ctx context.Context, userID uint, limit int) ([]models.Restaurant, error) {
+	var recent []struct {
+		RestaurantID uint
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Order{}).
+		Select("restaurant_id, max(created_at) AS last_ordered_at").
+		Where("user_id = ?", userID).
+		Group("restaurant_id").
+		Order("last_ordered_at DESC").
+		Limit(limit).
+		Scan(&recent).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(recent))
+	for i, row := range recent {
+		ids[i] = row.RestaurantID
+	}
+	var restaurants []models.Restaurant
+	if err := s.db.WithContext(ctx).Scopes(repositories.WithSchedule).Where("id IN ? AND active = ?", ids, true).Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+	setOpeningStatuses(restaurants)
+
+	byID := make(map[uint]models.Restaurant)
+	for _, restaurant := range restaurants {
+		byID[restaurant.ID] = restaurant
+	}
+	results := []models.Restaurant{}
+	for _, id := range ids {
+		if restaurant, ok := byID[id]; ok {
+			results = append(results, restaurant)
+		}
+	}
+	return results, nil
+}
+
+// GetPopularFoods retrieves the foods ordered most over the last 30 days, optionally within one restaurant
+func (s *FeedService) GetPopularFoods(ctx context.Context, restaurantID uint, limit int) ([]PopularFood, error) {
+	query := s.db.WithContext(ctx).Table("food_orders").
+		Select("food_orders.food_id AS id, sum(food_orders.quantity) AS order_count").
+		Joins("JOIN orders ON orders.id = food_orders.order_id").
+		Where("orders.created_at >= ? AND orders.status <> ?", time.Now().Add(-popularFoodsWindow), "cancelled").
+		Group("food_orders.food_id").
+		Order("order_count DESC, food_orders.food_id").
+		Limit(limit)
+	if restaurantID != 0 {
+		query = query.Where("orders.restaurant_id = ?", restaurantID)
+	}
+
+	var counts []struct {
+		ID         uint
+		OrderCount int64
+	}
+	if err := query.Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(counts))
+	for i, count := range counts {
+		ids[i] = count.ID
+	}
+	foods, err := s.foods.foodsByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	popular := []PopularFood{}
+	for _, count := range counts {
+		if food, ok := foods[count.ID]; ok && food.Available {
+			popular = append(popular, PopularFood{Food: food, OrderCount: count.OrderCount})
+		}
+	}
+	return popular, nil
+}
+
+// GetReorderSuggestions retrieves the foods a user orders most, so they can order them again in one tap
+func (s *FeedService) GetReorderSuggestions(ctx context.Context, userID uint, limit int) ([]ReorderSuggestion, error) {
+	var counts []struct {
+		FoodID        uint
+		TimesOrdered  int64
+		LastOrderedAt time.Time
+	}
+	if err := s.db.WithContext(ctx).Table("food_orders").
+		Select("food_orders.food_id, count(DISTINCT orders.id) AS times_ordered, max(orders.created_at) AS last_ordered_at").
+		Joins("JOIN orders ON orders.id = food_orders.order_id").
+		Where("orders.user_id = ? AND orders.status <> ?", userID, "cancelled").
+		Group("food_orders.food_id").
+		Order("times_ordered DESC, last_ordered_at DESC").
+		Limit(limit).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(counts))
+	for i, count := range counts {
+		ids[i] = count.FoodID
+	}
+	foods, err := s.foods.foodsByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []ReorderSuggestion{}
+	for _, count := range counts {
+		if food, ok := foods[count.FoodID]; ok && food.Available {
+			suggestions = append(suggestions, ReorderSuggestion{
+				Food:          food,
+				TimesOrdered:  count.TimesOrdered,
+				LastOrderedAt: count.LastOrderedAt,
+			})
+		}
+	}
+	return suggestions, nil
+}
+
+// GetActiveOrders retrieves the orders of a user that are not completed or cancelled yet
+func (s *FeedService) GetActiveOrders(ctx context.Context, userID uint) ([]models.Order, error) {
+	var orders []models.Order
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND status NOT IN ?", userID, closedOrderStatuses).
+		Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table").
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
@@ -1,23 +1,31 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"madang_api/config"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
 )
 
-type FoodService struct{}
+type FoodService struct {
+	foods           repositories.FoodRepository
+	recommendations repositories.RecommendationRepository
+}
+
+func NewFoodService(foods repositories.FoodRepository, recommendations repositories.RecommendationRepository) *FoodService {
+	return &FoodService{foods: foods, recommendations: recommendations}
+}
 
 // Add a new food item return the food or error if it exist and also check if the food exist for that restaurant
 func (s *FoodService) AddFood(food *models.Food) (*models.Food, error) {
-	var existingFood models.Food
 	//Check if the food exist for that restaurant
-	if err := config.DB.Where("restaurant_id = ? AND name = ? ", existingFood.RestaurantID, existingFood.Name).First(&models.Food{}).Error; err == nil {
+	if _, err := s.foods.FindByName(food.RestaurantID, food.Name); err == nil {
 		return nil, errors.New("food already exists for this restaurant")
 	}
 
 	//Add the food item
-	if err := config.DB.Create(&food).Error; err != nil {
+	if err := s.foods.Create(food); err != nil {
 		return nil, err
 	}
 
@@ -26,7 +34,7 @@ func (s *FoodService) AddFood(food *models.Food) (*models.Food, error) {
 
 // UpdateFood updates an existing food item and returns the updated food item or an error if it fails
 func (s *FoodService) UpdateFood(food *models.Food) (*models.Food, error) {
-	if err := config.DB.Save(&food).Error; err != nil {
+	if err := s.foods.Save(food); err != nil {
 		return nil, err
 	}
 	return food, nil
@@ -34,57 +42,101 @@ func (s *FoodService) UpdateFood(food *models.Food) (*models.Food, error) {
 
 // Delete a Food by ID
 func (s *FoodService) DeleteFood(id uint) error {
-	var food models.Food
-	if err := config.DB.First(&food, id).Error; err != nil {
-		return err
-	}
-	if err := config.DB.Delete(&food).Error; err != nil {
+	if _, err := s.foods.FindByID(id); err != nil {
 		return err
 	}
-	return nil
+	return s.foods.Delete(id)
 }
 
 // GetFood retrieves a food item by its ID and returns the food item or an error if it fails
 func (s *FoodService) GetFood(id uint) (*models.Food, error) {
-	var food models.Food
-	if err := config.DB.First(&food, id).Error; err != nil {
+	return s.foods.FindByID(id)
+}
+
+// GetAllFoods retrieves a page of food items matching the query and returns it with its pagination or an error if it fails
+func (s *FoodService) GetAllFoods(query *utils.ListQuery) ([]models.Food, *utils.Pagination, error) {
+	return s.foods.List(query)
+}
+
+// GetRestaurantFoods retrieves all food items for a specific restaurant and returns a slice of food items or an error if it fails
+func (s *FoodService) GetRestaurantFoods(ctx context.Context, restaurantID uint) ([]models.Food, error) {
+	return s.foods.WithContext(ctx).FindByRestaurant(restaurantID)
+}
+
+// FoodSearchResult is a food matched by SearchFoods with its relevance and highlighted snippet
+type FoodSearchResult struct {
+	models.Food
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchFoods runs a full-text search over the food name, description and category name (weighted in that order),
+// matching words as prefixes and tolerating typos in the name. Results come back most relevant first
+func (s *FoodService) SearchFoods(query string, restaurantID uint) ([]FoodSearchResult, error) {
+	return searchFoods(s.foods, query, restaurantID)
+}
+
+// searchFoods loads the foods hit by the search, in ranking order
+func searchFoods(repository repositories.FoodRepository, query string, restaurantID uint) ([]FoodSearchResult, error) {
+	hits, err := repository.Search(query, restaurantID)
+	if err != nil {
 		return nil, err
 	}
-	return &food, nil
-}
 
-// GetAllFoods retrieves all food items and returns a slice of food items or an error if it fails
-func (s *FoodService) GetAllFoods() ([]models.Food, error) {
-	var foods []models.Food
-	if err := config.DB.Find(&foods).Error; err != nil {
+	foods, err := repository.FindByIDs(hitIDs(hits))
+	if err != nil {
 		return nil, err
 	}
-	return foods, nil
+	byID := make(map[uint]models.Food)
+	for _, food := range foods {
+		byID[food.ID] = food
+	}
+
+	results := []FoodSearchResult{}
+	for _, hit := range hits {
+		if food, ok := byID[hit.ID]; ok {
+			results = append(results, FoodSearchResult{Food: food, Rank: hit.Rank, Snippet: hit.Snippet})
+		}
+	}
+	return results, nil
 }
 
-// GetRestaurantFoods retrieves all food items for a specific restaurant and returns a slice of food items or an error if it fails
-func (s *FoodService) GetRestaurantFoods(restaurantID uint) ([]models.Food, error) {
-	var foods []models.Food
-	if err := config.DB.Where("restaurant_id = ?", restaurantID).Find(&foods).Error; err != nil {
+// get recommended foods of a restaurant for a user, as computed by the recommendation job.
+// Until the job has run for the restaurant the best rated foods are returned
+func (s *FoodService) GetRecommendedFoods(ctx context.Context, userID uint, restaurantID uint) ([]models.Food, error) {
+	ids, err := s.recommendations.WithContext(ctx).ItemIDs(userID, restaurantID, "food", recommendedLimit)
+	if err != nil {
 		return nil, err
 	}
-	return foods, nil
-}
+	if len(ids) == 0 {
+		return s.foods.WithContext(ctx).BestRated(restaurantID, recommendedLimit)
+	}
 
-// implement search for food it should be by name or fhe name of the category the food belongs too
-func (s *FoodService) SearchFoods(query string) ([]models.Food, error) {
-	var foods []models.Food
-	if err := config.DB.Where("name LIKE ? OR category_id IN (SELECT id FROM categories WHERE name LIKE ?)", "%"+query+"%", "%"+query+"%").Find(&foods).Error; err != nil {
+	byID, err := s.foodsByID(ctx, ids)
+	if err != nil {
 		return nil, err
 	}
+	foods := []models.Food{}
+	for _, id := range ids {
+		if food, ok := byID[id]; ok && food.Available {
+			foods = append(foods, food)
+		}
+	}
 	return foods, nil
 }
 
-// get recommended foods for a particular restaurant which is the 5 most recent foods
-func (s *FoodService) GetRecommendedFoods(restaurantID uint) ([]models.Food, error) {
-	var foods []models.Food
-	if err := config.DB.Where("restaurant_id = ?", restaurantID).Order("id desc").Limit(5).Find(&foods).Error; err != nil {
+// foodsByID loads foods keyed by their ID
+func (s *FoodService) foodsByID(ctx context.Context, ids []uint) (map[uint]models.Food, error) {
+	byID := make(map[uint]models.Food)
+	if len(ids) == 0 {
+		return byID, nil
+	}
+	foods, err := s.foods.WithContext(ctx).FindByIDs(ids)
+	if err != nil {
 		return nil, err
 	}
-	return foods, nil
+	for _, food := range foods {
+		byID[food.ID] = food
+	}
+	return byID, nil
 }
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"madang_api/migrations"
+
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout bounds each dependency check, a probe must answer before the orchestrator gives up
+const healthCheckTimeout = 2 * time.Second
+
+// HealthService tells whether the API can serve requests
+type HealthService struct {
+	db      *gorm.DB
+	workers func() map[string]bool
+}
+
+// NewHealthService checks db and the background workers, whose running state is given by workers
+func NewHealthService(db *gorm.DB, workers func() map[string]bool) *HealthService {
+	return &HealthService{db: db, workers: workers}
+}
+
+// DependencyStatus is the result of the check of one dependency
+type DependencyStatus struct {
+	Status    string  `json:"status"` // "ok" or "failing"
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Readiness is the result of every check, Ready only when all of them pass
+type Readiness struct {
+	Ready  bool                        `json:"ready"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+// CheckReadiness checks that the database is reachable, that no migration is pending and that every
+// background worker is running
+func (s *HealthService) CheckReadiness(ctx context.Context) *Readiness {
+	readiness := &Readiness{Ready: true, Checks: make(map[string]DependencyStatus)}
+	checks := []struct {
+		name  string
+		check func(ctx context.Context) error
+	}{
+		{"database", s.checkDatabase},
+		{"migrations", s.checkMigrations},
+		{"workers", s.checkWorkers},
+	}
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		started := time.Now()
+		err := c.check(checkCtx)
+		cancel()
+
+		status := DependencyStatus{Status: "ok", LatencyMs: float64(time.Since(started).Microseconds()) / 1000}
+		if err != nil {
+			status.Status = "failing"
+			status.Error = err.Error()
+			readiness.Ready = false
+		}
+		readiness.Checks[c.name] = status
+	}
+	return readiness
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	pending, err := migrations.Pending(s.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations are pending", len(pending))
+	}
+	return nil
+}
+
+func (s *HealthService) checkWorkers(ctx context.Context) error {
+	workers := s.workers()
+	if len(workers) == 0 {
+		return fmt.Errorf("no worker started")
+	}
+	var stopped []string
+	for name, running := range workers {
+		if !running {
+			stopped = append(stopped, name)
+		}
+	}
+	if len(stopped) > 0 {
+		sort.Strings(stopped)
+		return fmt.Errorf("%s not running", strings.Join(stopped, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"madang_api/models"
+	"madang_api/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryService struct {
+	db *gorm.DB
+}
+
+func NewInventoryService(db *gorm.DB) *InventoryService {
+	return &InventoryService{db: db}
+}
+
+// AddIngredient adds a new ingredient to a restaurant's stock or returns an error if it already exists
+func (s *InventoryService) AddIngredient(ingredient *models.Ingredient) (*models.Ingredient, error) {
+	if err := s.db.Where("restaurant_id = ? AND name = ?", ingredient.RestaurantID, ingredient.Name).First(&models.Ingredient{}).Error; err == nil {
+		return nil, errors.New("ingredient already exists for this restaurant")
+	}
+
+	if err := s.db.Create(ingredient).Error; err != nil {
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+// UpdateIngredient updates the details of an ingredient. Stock levels are changed through AdjustStock so they stay audited
+func (s *InventoryService) UpdateIngredient(ingredient *models.Ingredient) (*models.Ingredient, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(ingredient).Select("name", "unit", "low_stock_threshold").Updates(ingredient).Error; err != nil {
+			return err
+		}
+		if err := tx.First(ingredient, ingredient.ID).Error; err != nil {
+			return err
+		}
+		// the threshold may have moved, so re-check the foods depending on it
+		return refreshFoodAvailability(tx, []uint{ingredient.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+// DeleteIngredient deletes an ingredient together with the recipe lines using it
+func (s *InventoryService) DeleteIngredient(id uint) error {
+	var ingredient models.Ingredient
+	if err := s.db.First(&ingredient, id).Error; err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var foodIDs []uint
+		if err := tx.Model(&models.FoodIngredient{}).Where("ingredient_id = ?", id).Pluck("food_id", &foodIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("ingredient_id = ?", id).Delete(&models.FoodIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("ingredient_id = ?", id).Delete(&models.AddonIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&ingredient).Error; err != nil {
+			return err
+		}
+		return refreshFoodsByID(tx, foodIDs)
+	})
+}
+
+// GetIngredient retrieves an ingredient by its ID
+func (s *InventoryService) GetIngredient(id uint) (*models.Ingredient, error) {
+	var ingredient models.Ingredient
+	if err := s.db.First(&ingredient, id).Error; err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// GetRestaurantIngredients retrieves a page of the ingredients of a restaurant
+func (s *InventoryService) GetRestaurantIngredients(restaurantID uint, query *utils.ListQuery) ([]models.Ingredient, *utils.Pagination, error) {
+	ingredients := []models.Ingredient{}
+	pagination, err := utils.Paginate(s.db.Model(&models.Ingredient{}), query.Where("restaurant_id", restaurantID), &ingredients)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ingredients, pagination, nil
+}
+
+// GetLowStockIngredients retrieves the ingredients of a restaurant that are at or below their low stock threshold
+func (s *InventoryService) GetLowStockIngredients(restaurantID uint) ([]models.Ingredient, error) {
+	var ingredients []models.Ingredient
+	if err := s.db.Where("restaurant_id = ? AND quantity <= low_stock_threshold", restaurantID).Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+// AdjustStock manually changes the stock of an ingredient (restock, waste, stock take...) and records it in the history
+func (s *InventoryService) AdjustStock(ingredientID uint, change float64, reason string, userID uint) (*models.Ingredient, error) {
+	var ingredient models.Ingredient
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ingredient, ingredientID).Error; err != nil {
+			return err
+		}
+		if ingredient.Quantity+change < 0 {
+			return errors.New("stock cannot go below zero")
+		}
+		if err := applyStockChange(tx, &ingredient, change, reason, nil, &userID); err != nil {
+			return err
+		}
+		return refreshFoodAvailability(tx, []uint{ingredient.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// GetStockHistory retrieves the stock adjustments of an ingredient, newest first
+func (s *InventoryService) GetStockHistory(ingredientID uint) ([]models.StockAdjustment, error) {
+	var adjustments []models.StockAdjustment
+	if err := s.db.Where("ingredient_id = ?", ingredientID).Order("id desc").Find(&adjustments).Error; err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// GetFoodRecipe retrieves the ingredients used by a food
+func (s *InventoryService) GetFoodRecipe(foodID uint) ([]models.FoodIngredient, error) {
+	var recipe []models.FoodIngredient
+	if err := s.db.Where("food_id = ?", foodID).Preload("Ingredient").Find(&recipe).Error; err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
+
+// SetFoodRecipe replaces the recipe of a food with the given lines
+func (s *InventoryService) SetFoodRecipe(foodID uint, recipe []models.FoodIngredient) ([]models.FoodIngredient, error) {
+	var food models.Food
+	if err := s.db.First(&food, foodID).Error; err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("food_id = ?", foodID).Delete(&models.FoodIngredient{}).Error; err != nil {
+			return err
+		}
+		for i := range recipe {
+			if err := checkRecipeIngredient(tx, food.RestaurantID, recipe[i].IngredientID, recipe[i].Quantity); err != nil {
+				return err
+			}
+			recipe[i].ID = 0
+			recipe[i].FoodID = foodID
+		}
+		if len(recipe) > 0 {
+			if err := tx.Omit("Ingredient").Create(&recipe).Error; err != nil {
+				return err
+			}
+		}
+		return refreshFoodsByID(tx, []uint{foodID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetFoodRecipe(foodID)
+}
+
+// GetAddonRecipe retrieves the ingredients used by an addon
+func (s *InventoryService) GetAddonRecipe(addonID uint) ([]models.AddonIngredient, error) {
+	var recipe []models.AddonIngredient
+	if err := s.db.Where("addon_id = ?", addonID).Preload("Ingredient").Find(&recipe).Error; err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
+
+// SetAddonRecipe replaces the recipe of an addon with the given lines
+func (s *InventoryService) SetAddonRecipe(addonID uint, recipe []models.AddonIngredient) ([]models.AddonIngredient, error) {
+	var addon models.Addon
+	if err := s.db.First(&addon, addonID).Error; err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("addon_id = ?", addonID).Delete(&models.AddonIngredient{}).Error; err != nil {
+			return err
+		}
+		for i := range recipe {
+			if err := checkRecipeIngredient(tx, addon.RestaurantID, recipe[i].IngredientID, recipe[i].Quantity); err != nil {
+				return err
+			}
+			recipe[i].ID = 0
+			recipe[i].AddonID = addonID
+		}
+		if len(recipe) > 0 {
+			return tx.Omit("Ingredient").Create(&recipe).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAddonRecipe(addonID)
+}
+
+// checkRecipeIngredient makes sure a recipe line points at an ingredient of the same restaurant
+func checkRecipeIngredient(tx *gorm.DB, restaurantID uint, ingredientID uint, quantity float64) error {
+	if quantity <= 0 {
+		return errors.New("recipe quantity must be greater than zero")
+	}
+	var ingredient models.Ingredient
+	if err := tx.First(&ingredient, ingredientID).Error; err != nil {
+		return fmt.Errorf("ingredient %d not found", ingredientID)
+	}
+	if ingredient.RestaurantID != restaurantID {
+		return fmt.Errorf("ingredient %d does not belong to this restaurant", ingredientID)
+	}
+	return nil
+}
+
+// applyStockChange updates the stock of a locked ingredient and writes the audit record
+func applyStockChange(tx *gorm.DB, ingredient *models.Ingredient, change float64, reason string, orderID *uint, userID *uint) error {
+	ingredient.Quantity += change
+	if err := tx.Model(ingredient).Update("quantity", ingredient.Quantity).Error; err != nil {
+		return err
+	}
+	adjustment := models.StockAdjustment{
+		IngredientID:  ingredient.ID,
+		Change:        change,
+		QuantityAfter: ingredient.Quantity,
+		Reason:        reason,
+		OrderID:       orderID,
+		UserID:        userID,
+	}
+	return tx.Create(&adjustment).Error
+}
+
+// orderIngredientUsage sums up how much of each ingredient an order consumes
+func orderIngredientUsage(tx *gorm.DB, order *models.Order) (map[uint]float64, error) {
+	usage := make(map[uint]float64)
+
+	for _, foodOrder := range order.FoodOrders {
+		var recipe []models.FoodIngredient
+		if err := tx.Where("food_id = ?", foodOrder.FoodID).Find(&recipe).Error; err != nil {
+			return nil, err
+		}
+		for _, line := range recipe {
+			usage[line.IngredientID] += line.Quantity * float64(foodOrder.Quantity)
+		}
+	}
+
+	for _, addonOrder := range order.AddonOrders {
+		var recipe []models.AddonIngredient
+		if err := tx.Where("addon_id = ?", addonOrder.AddonID).Find(&recipe).Error; err != nil {
+			return nil, err
+		}
+		for _, line := range recipe {
+			usage[line.IngredientID] += line.Quantity * float64(addonOrder.Quantity)
+		}
+	}
+
+	return usage, nil
+}
+
+// deductOrderStock takes the ingredients of a confirmed order out of stock. It fails if any ingredient runs short
+func deductOrderStock(tx *gorm.DB, order *models.Order) error {
+	if order.StockDeducted {
+		return nil
+	}
+	usage, err := orderIngredientUsage(tx, order)
+	if err != nil {
+		return err
+	}
+
+	ingredientIDs := make([]uint, 0, len(usage))
+	for ingredientID, amount := range usage {
+		var ingredient models.Ingredient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ingredient, ingredientID).Error; err != nil {
+			return err
+		}
+		if ingredient.Quantity < amount {
+			return fmt.Errorf("insufficient stock for %s", ingredient.Name)
+		}
+		if err := applyStockChange(tx, &ingredient, -amount, "order_confirmed", &order.ID, nil); err != nil {
+			return err
+		}
+		ingredientIDs = append(ingredientIDs, ingredientID)
+	}
+
+	order.StockDeducted = true
+	if err := tx.Model(order).Update("stock_deducted", true).Error; err != nil {
+		return err
+	}
+	return refreshFoodAvailability(tx, ingredientIDs)
+}
+
+// restoreOrderStock puts back the ingredients of an order that is cancelled after being confirmed
+func restoreOrderStock(tx *gorm.DB, order *models.Order) error {
+	if !order.StockDeducted {
+		return nil
+	}
+	usage, err := orderIngredientUsage(tx, order)
+	if err != nil {
+		return err
+	}
+
+	ingredientIDs := make([]uint, 0, len(usage))
+	for ingredientID, amount := range usage {
+		var ingredient models.Ingredient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ingredient, ingredientID).Error; err != nil {
+			return err
+		}
+		if err := applyStockChange(tx, &ingredient, amount, "order_cancelled", &order.ID, nil); err != nil {
+			return err
+		}
+		ingredientIDs = append(ingredientIDs, ingredientID)
+	}
+
+	order.StockDeducted = false
+	if err := tx.Model(order).Update("stock_deducted", false).Error; err != nil {
+		return err
+	}
+	return refreshFoodAvailability(tx, ingredientIDs)
+}
+
+// refreshFoodAvailability re-checks every food that uses one of the given ingredients
+func refreshFoodAvailability(tx *gorm.DB, ingredientIDs []uint) error {
+	if len(ingredientIDs) == 0 {
+		return nil
+	}
+	var foodIDs []uint
+	if err := tx.Model(&models.FoodIngredient{}).Where("ingredient_id IN ?", ingredientIDs).Distinct().Pluck("food_id", &foodIDs).Error; err != nil {
+		return err
+	}
+	return refreshFoodsByID(tx, foodIDs)
+}
+
+// refreshFoodsByID marks a food unavailable when any of its ingredients is at or below its low stock threshold,
+// or when its branch has switched it off through a brand menu override
+func refreshFoodsByID(tx *gorm.DB, foodIDs []uint) error {
+	for _, foodID := range foodIDs {
+		var lowStock int64
+		if err := tx.Model(&models.FoodIngredient{}).
+			Joins("JOIN ingredients ON ingredients.id = food_ingredients.ingredient_id").
+			Where("food_ingredients.food_id = ? AND ingredients.quantity <= ingredients.low_stock_threshold", foodID).
+			Count(&lowStock).Error; err != nil {
+			return err
+		}
+		var switchedOff int64
+		if err := tx.Model(&models.BranchOverride{}).
+			Joins("JOIN foods ON foods.brand_food_id = branch_overrides.brand_food_id AND foods.restaurant_id = branch_overrides.restaurant_id").
+			Where("foods.id = ? AND branch_overrides.available = ?", foodID, false).
+			Count(&switchedOff).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Food{}).Where("id = ?", foodID).Update("available", lowStock == 0 && switchedOff == 0).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
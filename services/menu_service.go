@@ -0,0 +1,799 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"madang_api/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type MenuService struct {
+	db *gorm.DB
+}
+
+func NewMenuService(db *gorm.DB) *MenuService {
+	return &MenuService{db: db}
+}
+
+// Menu is the portable representation of a restaurant's menu used for export and import.
+// Items are matched by name, so IDs never leave the restaurant they belong to
+type Menu struct {
+	Categories []MenuCategory `json:"categories"`
+	Foods      []MenuFood     `json:"foods"`
+	Addons     []MenuAddon    `json:"addons"`
+	Tables     []MenuTable    `json:"tables"`
+}
+
+type MenuCategory struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type MenuFood struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Image       string  `json:"image"`
+	Price       float64 `json:"price"`
+	Category    string  `json:"category"`
+}
+
+type MenuAddon struct {
+	Name  string  `json:"name"`
+	Type  string  `json:"type"`
+	Price float64 `json:"price"`
+}
+
+type MenuTable struct {
+	Name     string  `json:"name"`
+	Number   int     `json:"number"`
+	Capacity int     `json:"capacity"`
+	Image    string  `json:"image"`
+	Price    float64 `json:"price"`
+	Category string  `json:"category"`
+}
+
+// MenuChange is a single line of an import plan
+type MenuChange struct {
+	Type   string `json:"type"` // "category", "food", "addon" or "table"
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"` // why a food is deactivated or an item kept
+}
+
+// MenuImportPlan describes what an import does (or would do on a dry run) to the restaurant's menu
+type MenuImportPlan struct {
+	DryRun  bool         `json:"dry_run"`
+	Applied bool         `json:"applied"`
+	Creates []MenuChange `json:"creates"`
+	Updates []MenuChange `json:"updates"`
+	Deletes []MenuChange `json:"deletes"`
+	// foods left out of the import that past orders or ratings refer to, marked unavailable instead of deleted
+	Deactivates []MenuChange `json:"deactivates"`
+	// items the import leaves as they are: synced from the brand menu, or left out but still referred to
+	Kept   []MenuChange `json:"kept"`
+	Errors []string     `json:"errors"`
+}
+
+// ExportMenu builds the full menu of a restaurant
+func (s *MenuService) ExportMenu(restaurantID uint) (*Menu, error) {
+	var restaurant models.Restaurant
+	if err := s.db.First(&restaurant, restaurantID).Error; err != nil {
+		return nil, err
+	}
+
+	var categories []models.Category
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Order("id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	var foods []models.Food
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Order("id").Find(&foods).Error; err != nil {
+		return nil, err
+	}
+	var addons []models.Addon
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Order("id").Find(&addons).Error; err != nil {
+		return nil, err
+	}
+	var tables []models.Table
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Order("id").Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	categoryNames := make(map[uint]string)
+	menu := &Menu{
+		Categories: []MenuCategory{},
+		Foods:      []MenuFood{},
+		Addons:     []MenuAddon{},
+		Tables:     []MenuTable{},
+	}
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+		menu.Categories = append(menu.Categories, MenuCategory{Name: category.Name, Type: category.Type})
+	}
+	for _, food := range foods {
+		menu.Foods = append(menu.Foods, MenuFood{
+			Name:        food.Name,
+			Description: food.Description,
+			Image:       food.Image,
+			Price:       food.Price,
+			Category:    categoryNames[food.CategoryId],
+		})
+	}
+	for _, addon := range addons {
+		menu.Addons = append(menu.Addons, MenuAddon{Name: addon.Name, Type: addon.Type, Price: addon.Price})
+	}
+	for _, table := range tables {
+		menu.Tables = append(menu.Tables, MenuTable{
+			Name:     table.Name,
+			Number:   table.Number,
+			Capacity: table.Capacity,
+			Image:    table.Image,
+			Price:    table.Price,
+			Category: categoryNames[table.CategoryId],
+		})
+	}
+
+	return menu, nil
+}
+
+// ImportMenu replaces the menu of a restaurant with the given one. Items are created, updated or
+// deleted so the restaurant ends up with the imported menu, except for what past orders still refer to
+// and what is synced from the brand menu (see existingMenu). On a dry run, or when the menu
+// does not validate, nothing is written and only the plan is returned. Otherwise every change is
+// applied in a single transaction
+func (s *MenuService) ImportMenu(restaurantID uint, menu *Menu, dryRun bool) (*MenuImportPlan, error) {
+	var restaurant models.Restaurant
+	if err := s.db.First(&restaurant, restaurantID).Error; err != nil {
+		return nil, err
+	}
+
+	plan := &MenuImportPlan{
+		DryRun:      dryRun,
+		Creates:     []MenuChange{},
+		Updates:     []MenuChange{},
+		Deletes:     []MenuChange{},
+		Deactivates: []MenuChange{},
+		Kept:        []MenuChange{},
+		Errors:      validateMenu(menu),
+	}
+
+	var existing existingMenu
+	if err := existing.load(s.db, restaurantID); err != nil {
+		return nil, err
+	}
+	existing.plan(menu, plan)
+
+	if dryRun || len(plan.Errors) > 0 {
+		return plan, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return existing.apply(tx, restaurantID, menu, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// validateMenu collects every problem of an imported menu so they can be fixed in one go
+func validateMenu(menu *Menu) []string {
+	problems := []string{}
+
+	categories := make(map[string]bool)
+	for i, category := range menu.Categories {
+		if strings.TrimSpace(category.Name) == "" {
+			problems = append(problems, fmt.Sprintf("category %d: name cannot be empty", i+1))
+			continue
+		}
+		if strings.TrimSpace(category.Type) == "" {
+			problems = append(problems, fmt.Sprintf("category %q: type cannot be empty", category.Name))
+		}
+		if categories[category.Name] {
+			problems = append(problems, fmt.Sprintf("category %q: duplicate name", category.Name))
+		}
+		categories[category.Name] = true
+	}
+
+	foods := make(map[string]bool)
+	for i, food := range menu.Foods {
+		if strings.TrimSpace(food.Name) == "" {
+			problems = append(problems, fmt.Sprintf("food %d: name cannot be empty", i+1))
+			continue
+		}
+		if foods[food.Name] {
+			problems = append(problems, fmt.Sprintf("food %q: duplicate name", food.Name))
+		}
+		foods[food.Name] = true
+		if food.Price < 0 {
+			problems = append(problems, fmt.Sprintf("food %q: price cannot be negative", food.Name))
+		}
+		if food.Category != "" && !categories[food.Category] {
+			problems = append(problems, fmt.Sprintf("food %q: unknown category %q", food.Name, food.Category))
+		}
+	}
+
+	addons := make(map[string]bool)
+	for i, addon := range menu.Addons {
+		if strings.TrimSpace(addon.Name) == "" {
+			problems = append(problems, fmt.Sprintf("addon %d: name cannot be empty", i+1))
+			continue
+		}
+		if addons[addon.Name] {
+			problems = append(problems, fmt.Sprintf("addon %q: duplicate name", addon.Name))
+		}
+		addons[addon.Name] = true
+		if addon.Price < 0 {
+			problems = append(problems, fmt.Sprintf("addon %q: price cannot be negative", addon.Name))
+		}
+	}
+
+	tables := make(map[string]bool)
+	for i, table := range menu.Tables {
+		if strings.TrimSpace(table.Name) == "" {
+			problems = append(problems, fmt.Sprintf("table %d: name cannot be empty", i+1))
+			continue
+		}
+		if tables[table.Name] {
+			problems = append(problems, fmt.Sprintf("table %q: duplicate name", table.Name))
+		}
+		tables[table.Name] = true
+		if table.Price < 0 {
+			problems = append(problems, fmt.Sprintf("table %q: price cannot be negative", table.Name))
+		}
+		if table.Capacity < 0 {
+			problems = append(problems, fmt.Sprintf("table %q: capacity cannot be negative", table.Name))
+		}
+		if table.Category != "" && !categories[table.Category] {
+			problems = append(problems, fmt.Sprintf("table %q: unknown category %q", table.Name, table.Category))
+		}
+	}
+
+	return problems
+}
+
+// existingMenu is the current menu of a restaurant indexed by name, with the items that past orders or
+// ratings still refer to. Those are never deleted: a food is marked unavailable instead and addons and
+// tables are kept. Items synced from the brand menu are left to the brand
+type existingMenu struct {
+	categories map[string]models.Category
+	foods      map[string]models.Food
+	addons     map[string]models.Addon
+	tables     map[string]models.Table
+
+	referencedFoods  map[uint]bool
+	referencedAddons map[uint]bool
+	referencedTables map[uint]bool
+}
+
+func (e *existingMenu) load(db *gorm.DB, restaurantID uint) error {
+	var categories []models.Category
+	if err := db.Where("restaurant_id = ?", restaurantID).Find(&categories).Error; err != nil {
+		return err
+	}
+	var foods []models.Food
+	if err := db.Where("restaurant_id = ?", restaurantID).Find(&foods).Error; err != nil {
+		return err
+	}
+	var addons []models.Addon
+	if err := db.Where("restaurant_id = ?", restaurantID).Find(&addons).Error; err != nil {
+		return err
+	}
+	var tables []models.Table
+	if err := db.Where("restaurant_id = ?", restaurantID).Find(&tables).Error; err != nil {
+		return err
+	}
+
+	e.categories = make(map[string]models.Category)
+	for _, category := range categories {
+		e.categories[category.Name] = category
+	}
+	e.foods = make(map[string]models.Food)
+	foodIDs := make([]uint, 0, len(foods))
+	for _, food := range foods {
+		e.foods[food.Name] = food
+		foodIDs = append(foodIDs, food.ID)
+	}
+	e.addons = make(map[string]models.Addon)
+	addonIDs := make([]uint, 0, len(addons))
+	for _, addon := range addons {
+		e.addons[addon.Name] = addon
+		addonIDs = append(addonIDs, addon.ID)
+	}
+	e.tables = make(map[string]models.Table)
+	tableIDs := make([]uint, 0, len(tables))
+	for _, table := range tables {
+		e.tables[table.Name] = table
+		tableIDs = append(tableIDs, table.ID)
+	}
+
+	var err error
+	if e.referencedFoods, err = referencedFoods(db, foodIDs); err != nil {
+		return err
+	}
+	if e.referencedAddons, err = referencedAddons(db, addonIDs); err != nil {
+		return err
+	}
+	e.referencedTables, err = referencedTables(db, tableIDs)
+	return err
+}
+
+// menuReference is a column of a table holding the ID of a menu item
+type menuReference struct {
+	model  interface{}
+	column string
+}
+
+// referencedFoods returns which of the foods past orders or ratings refer to
+func referencedFoods(db *gorm.DB, ids []uint) (map[uint]bool, error) {
+	return referencedBy(db, ids, menuReference{&models.FoodOrder{}, "food_id"}, menuReference{&models.Rating{}, "food_id"})
+}
+
+// referencedAddons returns which of the addons past orders refer to
+func referencedAddons(db *gorm.DB, ids []uint) (map[uint]bool, error) {
+	return referencedBy(db, ids, menuReference{&models.AddonOrder{}, "addon_id"})
+}
+
+// referencedTables returns which of the tables past orders refer to
+func referencedTables(db *gorm.DB, ids []uint) (map[uint]bool, error) {
+	return referencedBy(db, ids, menuReference{&models.TableOrder{}, "table_id"}, menuReference{&models.Order{}, "table_id"})
+}
+
+func referencedBy(db *gorm.DB, ids []uint, references ...menuReference) (map[uint]bool, error) {
+	referenced := make(map[uint]bool)
+	if len(ids) == 0 {
+		return referenced, nil
+	}
+	for _, reference := range references {
+		// soft deleted rows keep their foreign keys, so they count too
+		var found []uint
+		if err := db.Unscoped().Model(reference.model).Where(reference.column+" IN ?", ids).
+			Distinct().Pluck(reference.column, &found).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			referenced[id] = true
+		}
+	}
+	return referenced, nil
+}
+
+// retireFoods takes foods off the menu along with their recipes. The ones past orders or ratings refer to
+// cannot be deleted: they are kept unavailable and no longer synced from a brand menu
+func retireFoods(tx *gorm.DB, foods []models.Food) error {
+	if len(foods) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(foods))
+	for i, food := range foods {
+		ids[i] = food.ID
+	}
+	referenced, err := referencedFoods(tx, ids)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("food_id IN ?", ids).Delete(&models.FoodIngredient{}).Error; err != nil {
+		return err
+	}
+	for _, food := range foods {
+		if referenced[food.ID] {
+			err = tx.Model(&food).Updates(map[string]interface{}{"available": false, "brand_food_id": nil}).Error
+		} else {
+			err = tx.Delete(&food).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retireAddons deletes addons along with their recipes and their place on tables. The ones past orders
+// refer to cannot be deleted: they are kept and no longer synced from a brand menu
+func retireAddons(tx *gorm.DB, addons []models.Addon) error {
+	if len(addons) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(addons))
+	for i, addon := range addons {
+		ids[i] = addon.ID
+	}
+	referenced, err := referencedAddons(tx, ids)
+	if err != nil {
+		return err
+	}
+	for _, addon := range addons {
+		if referenced[addon.ID] {
+			if err := tx.Model(&addon).Update("brand_addon_id", nil).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tx.Where("addon_id = ?", addon.ID).Delete(&models.AddonIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM table_addons WHERE addon_id = ?", addon.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&addon).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *existingMenu) categoryName(id uint) string {
+	for name, category := range e.categories {
+		if category.ID == id {
+			return name
+		}
+	}
+	return ""
+}
+
+// plan fills in the changes needed to turn the existing menu into the imported one. apply follows it, so
+// a dry run reports exactly what the import does
+func (e *existingMenu) plan(menu *Menu, plan *MenuImportPlan) {
+	record := func(current bool, changed bool, change MenuChange) {
+		if !current {
+			plan.Creates = append(plan.Creates, change)
+		} else if changed {
+			plan.Updates = append(plan.Updates, change)
+		}
+	}
+	keep := func(change MenuChange, reason string) {
+		change.Reason = reason
+		plan.Kept = append(plan.Kept, change)
+	}
+	const (
+		brandSynced = "synced from the brand menu"
+		ordered     = "past orders refer to it"
+	)
+
+	// categories still holding items that stay cannot go
+	holding := make(map[uint]bool)
+
+	imported := make(map[string]bool)
+	for _, food := range menu.Foods {
+		current, ok := e.foods[food.Name]
+		imported[food.Name] = true
+		if ok && current.BrandFoodID != nil {
+			keep(MenuChange{Type: "food", Name: food.Name}, brandSynced)
+			continue
+		}
+		changed := current.Description != food.Description || current.Image != food.Image ||
+			current.Price != food.Price || e.categoryName(current.CategoryId) != food.Category
+		record(ok, changed, MenuChange{Type: "food", Name: food.Name})
+	}
+	for _, name := range sortedNames(e.foods) {
+		food := e.foods[name]
+		switch {
+		case imported[name]:
+		case food.BrandFoodID != nil:
+			keep(MenuChange{Type: "food", Name: name}, brandSynced)
+			holding[food.CategoryId] = true
+		case e.referencedFoods[food.ID]:
+			plan.Deactivates = append(plan.Deactivates, MenuChange{Type: "food", Name: name, Reason: "past orders or ratings refer to it"})
+			holding[food.CategoryId] = true
+		default:
+			plan.Deletes = append(plan.Deletes, MenuChange{Type: "food", Name: name})
+		}
+	}
+
+	imported = make(map[string]bool)
+	for _, addon := range menu.Addons {
+		current, ok := e.addons[addon.Name]
+		imported[addon.Name] = true
+		if ok && current.BrandAddonID != nil {
+			keep(MenuChange{Type: "addon", Name: addon.Name}, brandSynced)
+			continue
+		}
+		record(ok, current.Type != addon.Type || current.Price != addon.Price, MenuChange{Type: "addon", Name: addon.Name})
+	}
+	for _, name := range sortedNames(e.addons) {
+		addon := e.addons[name]
+		switch {
+		case imported[name]:
+		case addon.BrandAddonID != nil:
+			keep(MenuChange{Type: "addon", Name: name}, brandSynced)
+		case e.referencedAddons[addon.ID]:
+			keep(MenuChange{Type: "addon", Name: name}, ordered)
+		default:
+			plan.Deletes = append(plan.Deletes, MenuChange{Type: "addon", Name: name})
+		}
+	}
+
+	imported = make(map[string]bool)
+	for _, table := range menu.Tables {
+		current, ok := e.tables[table.Name]
+		changed := current.Number != table.Number || current.Capacity != table.Capacity || current.Image != table.Image ||
+			current.Price != table.Price || e.categoryName(current.CategoryId) != table.Category
+		record(ok, changed, MenuChange{Type: "table", Name: table.Name})
+		imported[table.Name] = true
+	}
+	for _, name := range sortedNames(e.tables) {
+		table := e.tables[name]
+		switch {
+		case imported[name]:
+		case e.referencedTables[table.ID]:
+			keep(MenuChange{Type: "table", Name: name}, ordered)
+			holding[table.CategoryId] = true
+		default:
+			plan.Deletes = append(plan.Deletes, MenuChange{Type: "table", Name: name})
+		}
+	}
+
+	imported = make(map[string]bool)
+	for _, category := range menu.Categories {
+		current, ok := e.categories[category.Name]
+		record(ok, current.Type != category.Type, MenuChange{Type: "category", Name: category.Name})
+		imported[category.Name] = true
+	}
+	for _, name := range sortedNames(e.categories) {
+		category := e.categories[name]
+		switch {
+		case imported[name]:
+		case holding[category.ID]:
+			keep(MenuChange{Type: "category", Name: name}, "items that stay belong to it")
+		default:
+			plan.Deletes = append(plan.Deletes, MenuChange{Type: "category", Name: name})
+		}
+	}
+}
+
+// sortedNames lists the names of a menu map in order, so plans and the order they are applied in do not
+// change from one run to the next
+func sortedNames[T any](items map[string]T) []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply writes the imported menu over the existing one, following the plan for what goes away
+func (e *existingMenu) apply(tx *gorm.DB, restaurantID uint, menu *Menu, plan *MenuImportPlan) error {
+	categoryIDs := make(map[string]uint)
+	for _, item := range menu.Categories {
+		category, ok := e.categories[item.Name]
+		if !ok {
+			category = models.Category{Name: item.Name, RestaurantID: restaurantID}
+		}
+		category.Type = item.Type
+		if err := tx.Save(&category).Error; err != nil {
+			return err
+		}
+		categoryIDs[item.Name] = category.ID
+	}
+
+	for _, item := range menu.Foods {
+		food, ok := e.foods[item.Name]
+		if ok && food.BrandFoodID != nil {
+			continue
+		}
+		if !ok {
+			food = models.Food{Name: item.Name, RestaurantID: restaurantID, Available: true}
+		}
+		food.Description = item.Description
+		food.Image = item.Image
+		food.Price = item.Price
+		food.CategoryId = categoryIDs[item.Category]
+		if err := tx.Omit("Ratings").Save(&food).Error; err != nil {
+			return err
+		}
+		// a food taken off an earlier menu comes back as available as its stock allows
+		if !food.Available {
+			if err := refreshFoodsByID(tx, []uint{food.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	var retired []models.Food
+	for _, change := range plan.Deactivates {
+		retired = append(retired, e.foods[change.Name])
+	}
+	for _, change := range plan.Deletes {
+		if change.Type == "food" {
+			retired = append(retired, e.foods[change.Name])
+		}
+	}
+	if err := retireFoods(tx, retired); err != nil {
+		return err
+	}
+
+	for _, item := range menu.Addons {
+		addon, ok := e.addons[item.Name]
+		if ok && addon.BrandAddonID != nil {
+			continue
+		}
+		if !ok {
+			addon = models.Addon{Name: item.Name, RestaurantID: restaurantID}
+		}
+		addon.Type = item.Type
+		addon.Price = item.Price
+		if err := tx.Save(&addon).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, item := range menu.Tables {
+		table, ok := e.tables[item.Name]
+		if !ok {
+			table = models.Table{Name: item.Name, RestaurantID: restaurantID}
+		}
+		table.Number = item.Number
+		table.Capacity = item.Capacity
+		table.Image = item.Image
+		table.Price = item.Price
+		table.CategoryId = categoryIDs[item.Category]
+		if err := tx.Omit("Addons").Save(&table).Error; err != nil {
+			return err
+		}
+	}
+
+	var addons []models.Addon
+	for _, change := range plan.Deletes {
+		if change.Type == "addon" {
+			addons = append(addons, e.addons[change.Name])
+		}
+	}
+	if err := retireAddons(tx, addons); err != nil {
+		return err
+	}
+
+	// the plan lists the categories after the items so nothing still points at them while they are removed
+	for _, change := range plan.Deletes {
+		var err error
+		switch change.Type {
+		case "table":
+			table := e.tables[change.Name]
+			err = tx.Select("Addons").Delete(&table).Error
+		case "category":
+			category := e.categories[change.Name]
+			err = tx.Delete(&category).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// menuCSVHeader is the column layout of a CSV menu. Every row carries a "type" telling which
+// kind of item it describes; columns that do not apply to that type are left empty
+var menuCSVHeader = []string{"type", "name", "category", "description", "image", "price", "addon_type", "category_type", "number", "capacity"}
+
+// WriteMenuCSV writes a menu as a single CSV document
+func WriteMenuCSV(w io.Writer, menu *Menu) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(menuCSVHeader); err != nil {
+		return err
+	}
+
+	price := func(p float64) string { return strconv.FormatFloat(p, 'f', -1, 64) }
+	for _, category := range menu.Categories {
+		if err := writer.Write([]string{"category", category.Name, "", "", "", "", "", category.Type, "", ""}); err != nil {
+			return err
+		}
+	}
+	for _, food := range menu.Foods {
+		if err := writer.Write([]string{"food", food.Name, food.Category, food.Description, food.Image, price(food.Price), "", "", "", ""}); err != nil {
+			return err
+		}
+	}
+	for _, addon := range menu.Addons {
+		if err := writer.Write([]string{"addon", addon.Name, "", "", "", price(addon.Price), addon.Type, "", "", ""}); err != nil {
+			return err
+		}
+	}
+	for _, table := range menu.Tables {
+		if err := writer.Write([]string{"table", table.Name, table.Category, "", table.Image, price(table.Price), "", "", strconv.Itoa(table.Number), strconv.Itoa(table.Capacity)}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadMenuCSV parses a CSV document written by WriteMenuCSV. Columns are looked up by header name
+// so they may come in any order
+func ReadMenuCSV(r io.Reader) (*Menu, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv menu is empty")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	if _, ok := columns["type"]; !ok {
+		return nil, errors.New("csv menu is missing the type column")
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("csv menu is missing the name column")
+	}
+
+	menu := &Menu{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		get := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		number := func(column string) (float64, error) {
+			value := get(column)
+			if value == "" {
+				return 0, nil
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: invalid %s %q", line, column, value)
+			}
+			return parsed, nil
+		}
+
+		itemPrice, err := number("price")
+		if err != nil {
+			return nil, err
+		}
+
+		switch get("type") {
+		case "category":
+			menu.Categories = append(menu.Categories, MenuCategory{Name: get("name"), Type: get("category_type")})
+		case "food":
+			menu.Foods = append(menu.Foods, MenuFood{
+				Name:        get("name"),
+				Description: get("description"),
+				Image:       get("image"),
+				Price:       itemPrice,
+				Category:    get("category"),
+			})
+		case "addon":
+			menu.Addons = append(menu.Addons, MenuAddon{Name: get("name"), Type: get("addon_type"), Price: itemPrice})
+		case "table":
+			tableNumber, err := number("number")
+			if err != nil {
+				return nil, err
+			}
+			capacity, err := number("capacity")
+			if err != nil {
+				return nil, err
+			}
+			menu.Tables = append(menu.Tables, MenuTable{
+				Name:     get("name"),
+				Number:   int(tableNumber),
+				Capacity: int(capacity),
+				Image:    get("image"),
+				Price:    itemPrice,
+				Category: get("category"),
+			})
+		default:
+			return nil, fmt.Errorf("line %d: unknown type %q", line, get("type"))
+		}
+	}
+
+	return menu, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"madang_api/models"
+
+	"gorm.io/gorm"
+)
+
+func TestMenuPlanIsSortedByName(t *testing.T) {
+	existing := &existingMenu{
+		categories: map[string]models.Category{"Mains": {Model: gorm.Model{ID: 1}}, "Drinks": {Model: gorm.Model{ID: 2}}, "Sides": {Model: gorm.Model{ID: 3}}},
+		foods: map[string]models.Food{
+			"Japchae": {ID: 1, CategoryId: 1}, "Bibimbap": {ID: 2, CategoryId: 1}, "Kimchi": {ID: 3, CategoryId: 3},
+			"Sikhye": {ID: 4, CategoryId: 2}, "Bulgogi": {ID: 5, CategoryId: 1},
+		},
+		addons:           map[string]models.Addon{"Flowers": {ID: 1}, "Cake": {ID: 2}},
+		tables:           map[string]models.Table{"Window": {ID: 1, CategoryId: 3}, "Terrace": {ID: 2, CategoryId: 3}},
+		referencedFoods:  map[uint]bool{5: true, 2: true},
+		referencedAddons: map[uint]bool{},
+		referencedTables: map[uint]bool{},
+	}
+
+	// an empty menu takes everything away, the same way on every run
+	var first MenuImportPlan
+	existing.plan(&Menu{}, &first)
+	for run := 0; run < 10; run++ {
+		var plan MenuImportPlan
+		existing.plan(&Menu{}, &plan)
+		if !reflect.DeepEqual(plan, first) {
+			t.Fatalf("the plan changed between runs:\n%+v\n%+v", first, plan)
+		}
+	}
+
+	var deletes, deactivates []string
+	for _, change := range first.Deletes {
+		deletes = append(deletes, change.Type+":"+change.Name)
+	}
+	for _, change := range first.Deactivates {
+		deactivates = append(deactivates, change.Name)
+	}
+	expected := []string{"food:Japchae", "food:Kimchi", "food:Sikhye", "addon:Cake", "addon:Flowers", "table:Terrace", "table:Window", "category:Drinks", "category:Sides"}
+	if !reflect.DeepEqual(deletes, expected) {
+		t.Errorf("expected the deletions %v, got %v", expected, deletes)
+	}
+	if !reflect.DeepEqual(deactivates, []string{"Bibimbap", "Bulgogi"}) {
+		t.Errorf("expected the ordered foods to be deactivated in order, got %v", deactivates)
+	}
+}
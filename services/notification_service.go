@@ -0,0 +1,49 @@
+package services
+
+import (
+	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationService struct {
+	notifications repositories.NotificationRepository
+}
+
+func NewNotificationService(notifications repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{notifications: notifications}
+}
+
+// notify stores an in-app notification for a user. It takes the transaction of the change it reports on
+// so the notification only exists if that change is committed
+func notify(tx *gorm.DB, userID uint, notificationType string, title string, message string) error {
+	notification := models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+	}
+	return tx.Create(&notification).Error
+}
+
+// GetUserNotifications retrieves a page of the notifications of a user, newest first unless sorted otherwise
+func (s *NotificationService) GetUserNotifications(userID uint, query *utils.ListQuery) ([]models.Notification, *utils.Pagination, error) {
+	return s.notifications.ListForUser(userID, query)
+}
+
+// MarkAsRead marks a notification of the user as read
+func (s *NotificationService) MarkAsRead(userID uint, id uint) (*models.Notification, error) {
+	notification, err := s.notifications.FindForUser(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.ReadAt == nil {
+		if err := s.notifications.MarkRead(notification, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return notification, nil
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"madang_api/models"
+	"time"
+	_ "time/tzdata" // restaurant timezones must resolve even on hosts without a zoneinfo database
+
+	"gorm.io/gorm"
+)
+
+type OpeningHoursService struct {
+	db *gorm.DB
+}
+
+func NewOpeningHoursService(db *gorm.DB) *OpeningHoursService {
+	return &OpeningHoursService{db: db}
+}
+
+// OpeningHours is the full schedule of a restaurant together with its current status
+type OpeningHours struct {
+	RestaurantID uint                      `json:"restaurant_id"`
+	Timezone     string                    `json:"timezone"`
+	Intervals    []models.OpeningInterval  `json:"intervals"`
+	Exceptions   []models.OpeningException `json:"exceptions"`
+	OpenNow      bool                      `json:"open_now"`
+	NextOpening  *time.Time                `json:"next_opening,omitempty"`
+}
+
+// scheduleLookahead is how many days ahead NextOpening looks for an opening
+const scheduleLookahead = 14
+
+// GetOpeningHours retrieves the schedule of a restaurant
+func (s *OpeningHoursService) GetOpeningHours(restaurantID uint) (*OpeningHours, error) {
+	var restaurant models.Restaurant
+	if err := s.db.Preload("OpeningIntervals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("day_of_week, opens_at")
+	}).Preload("OpeningExceptions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date, opens_at")
+	}).First(&restaurant, restaurantID).Error; err != nil {
+		return nil, err
+	}
+	setOpeningStatus(&restaurant, time.Now())
+
+	return &OpeningHours{
+		RestaurantID: restaurant.ID,
+		Timezone:     restaurantTimezone(&restaurant).String(),
+		Intervals:    restaurant.OpeningIntervals,
+		Exceptions:   restaurant.OpeningExceptions,
+		OpenNow:      restaurant.OpenNow,
+		NextOpening:  restaurant.NextOpening,
+	}, nil
+}
+
+// SetWeeklySchedule replaces the weekly schedule and, when given, the timezone of a restaurant
+func (s *OpeningHoursService) SetWeeklySchedule(restaurantID uint, timezone string, intervals []models.OpeningInterval) (*OpeningHours, error) {
+	var restaurant models.Restaurant
+	if err := s.db.First(&restaurant, restaurantID).Error; err != nil {
+		return nil, err
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", timezone)
+		}
+	}
+	for i := range intervals {
+		if intervals[i].DayOfWeek < 0 || intervals[i].DayOfWeek > 6 {
+			return nil, errors.New("day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		if err := validateInterval(intervals[i].OpensAt, intervals[i].ClosesAt); err != nil {
+			return nil, err
+		}
+		intervals[i].ID = 0
+		intervals[i].RestaurantID = restaurantID
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if timezone != "" {
+			if err := tx.Model(&restaurant).Update("timezone", timezone).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("restaurant_id = ?", restaurantID).Delete(&models.OpeningInterval{}).Error; err != nil {
+			return err
+		}
+		if len(intervals) > 0 {
+			return tx.Create(&intervals).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOpeningHours(restaurantID)
+}
+
+// AddException adds a date specific closure or special opening to a restaurant
+func (s *OpeningHoursService) AddException(exception *models.OpeningException) (*models.OpeningException, error) {
+	if err := s.db.First(&models.Restaurant{}, exception.RestaurantID).Error; err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse("2006-01-02", exception.Date); err != nil {
+		return nil, errors.New("date must be formatted as YYYY-MM-DD")
+	}
+	if exception.Closed {
+		exception.OpensAt = ""
+		exception.ClosesAt = ""
+	} else if err := validateInterval(exception.OpensAt, exception.ClosesAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(exception).Error; err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+// DeleteException removes a date specific exception of a restaurant
+func (s *OpeningHoursService) DeleteException(restaurantID uint, exceptionID uint) error {
+	var exception models.OpeningException
+	if err := s.db.Where("restaurant_id = ?", restaurantID).First(&exception, exceptionID).Error; err != nil {
+		return err
+	}
+	return s.db.Delete(&exception).Error
+}
+
+// validateInterval checks an opening interval uses HH:MM times and is not empty
+func validateInterval(opensAt string, closesAt string) error {
+	if _, err := time.Parse("15:04", opensAt); err != nil {
+		return errors.New("opens_at must be formatted as HH:MM")
+	}
+	if _, err := time.Parse("15:04", closesAt); err != nil {
+		return errors.New("closes_at must be formatted as HH:MM")
+	}
+	if opensAt == closesAt {
+		return errors.New("opens_at and closes_at cannot be the same")
+	}
+	return nil
+}
+
+// restaurantTimezone resolves the timezone of a restaurant, falling back to UTC
+func restaurantTimezone(restaurant *models.Restaurant) *time.Location {
+	if restaurant.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(restaurant.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// hasSchedule reports whether a restaurant has configured a weekly schedule or any exception.
+// Restaurants without either are treated as always open so existing data keeps working
+func hasSchedule(restaurant *models.Restaurant) bool {
+	return len(restaurant.OpeningIntervals) > 0 || len(restaurant.OpeningExceptions) > 0
+}
+
+type openingWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// windowsOn returns the opening windows starting on the given local date. Exceptions for that date
+// replace the weekly schedule entirely. Without a weekly schedule the other dates are open all day
+func windowsOn(restaurant *models.Restaurant, date time.Time) []openingWindow {
+	day := date.Format("2006-01-02")
+
+	type period struct{ opensAt, closesAt string }
+	var periods []period
+	hasException := false
+	for _, exception := range restaurant.OpeningExceptions {
+		if exception.Date != day {
+			continue
+		}
+		// a Closed row wins over any special hours on the same date
+		if exception.Closed {
+			return nil
+		}
+		hasException = true
+		periods = append(periods, period{exception.OpensAt, exception.ClosesAt})
+	}
+	if !hasException && len(restaurant.OpeningIntervals) == 0 {
+		periods = append(periods, period{"00:00", "00:00"}) // runs to the next midnight
+	} else if !hasException {
+		for _, interval := range restaurant.OpeningIntervals {
+			if interval.DayOfWeek == int(date.Weekday()) {
+				periods = append(periods, period{interval.OpensAt, interval.ClosesAt})
+			}
+		}
+	}
+
+	var windows []openingWindow
+	for _, p := range periods {
+		opens, err := time.Parse("15:04", p.opensAt)
+		if err != nil {
+			continue
+		}
+		closes, err := time.Parse("15:04", p.closesAt)
+		if err != nil {
+			continue
+		}
+		start := time.Date(date.Year(), date.Month(), date.Day(), opens.Hour(), opens.Minute(), 0, 0, date.Location())
+		end := time.Date(date.Year(), date.Month(), date.Day(), closes.Hour(), closes.Minute(), 0, 0, date.Location())
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1) // runs past midnight
+		}
+		windows = append(windows, openingWindow{Start: start, End: end})
+	}
+	return windows
+}
+
+// IsRestaurantOpen reports whether a restaurant (loaded with its schedule) is open at the given time
+func IsRestaurantOpen(restaurant *models.Restaurant, at time.Time) bool {
+	if !hasSchedule(restaurant) {
+		return true
+	}
+	local := at.In(restaurantTimezone(restaurant))
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	// yesterday's windows can run past midnight into today
+	for _, date := range []time.Time{today.AddDate(0, 0, -1), today} {
+		for _, window := range windowsOn(restaurant, date) {
+			if !local.Before(window.Start) && local.Before(window.End) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextOpening returns when a restaurant next opens after the given time, or nil if it does not
+// open within the lookahead period
+func NextOpening(restaurant *models.Restaurant, after time.Time) *time.Time {
+	if !hasSchedule(restaurant) {
+		return nil
+	}
+	local := after.In(restaurantTimezone(restaurant))
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	for day := 0; day <= scheduleLookahead; day++ {
+		var next *time.Time
+		for _, window := range windowsOn(restaurant, today.AddDate(0, 0, day)) {
+			if window.Start.After(local) && (next == nil || window.Start.Before(*next)) {
+				start := window.Start
+				next = &start
+			}
+		}
+		if next != nil {
+			return next
+		}
+	}
+	return nil
+}
+
+// setOpeningStatus fills in the computed OpenNow and NextOpening fields of a restaurant
+func setOpeningStatus(restaurant *models.Restaurant, now time.Time) {
+	restaurant.OpenNow = IsRestaurantOpen(restaurant, now)
+	restaurant.NextOpening = nil
+	if !restaurant.OpenNow {
+		restaurant.NextOpening = NextOpening(restaurant, now)
+	}
+}
+
+// setOpeningStatuses fills in the opening status of every restaurant in the slice
+func setOpeningStatuses(restaurants []models.Restaurant) {
+	now := time.Now()
+	for i := range restaurants {
+		setOpeningStatus(&restaurants[i], now)
+	}
+}
+
+// filterOpenRestaurants keeps only the restaurants that are open right now
+func filterOpenRestaurants(restaurants []models.Restaurant) []models.Restaurant {
+	open := []models.Restaurant{}
+	for _, restaurant := range restaurants {
+		if restaurant.OpenNow {
+			open = append(open, restaurant)
+		}
+	}
+	return open
+}
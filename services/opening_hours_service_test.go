@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // the schedules below use named timezones
+
+	"madang_api/models"
+)
+
+// at parses a UTC time. The schedules below are around Wednesday 2024-01-03
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func interval(day time.Weekday, opensAt string, closesAt string) models.OpeningInterval {
+	return models.OpeningInterval{DayOfWeek: int(day), OpensAt: opensAt, ClosesAt: closesAt}
+}
+
+func assertNextOpening(t *testing.T, restaurant *models.Restaurant, after time.Time, expected *time.Time) {
+	t.Helper()
+	next := NextOpening(restaurant, after)
+	if (next == nil) != (expected == nil) || (next != nil && !next.Equal(*expected)) {
+		t.Fatalf("after %s: expected the next opening at %v, got %v", after, expected, next)
+	}
+}
+
+func TestRestaurantWithoutScheduleIsAlwaysOpen(t *testing.T) {
+	restaurant := &models.Restaurant{}
+	if hasSchedule(restaurant) || !IsRestaurantOpen(restaurant, at(t, "2024-01-03 03:00")) {
+		t.Fatal("a restaurant without a schedule is always open")
+	}
+	assertNextOpening(t, restaurant, at(t, "2024-01-03 03:00"), nil)
+}
+
+func TestWeeklySchedule(t *testing.T) {
+	restaurant := &models.Restaurant{OpeningIntervals: []models.OpeningInterval{
+		interval(time.Wednesday, "11:30", "14:00"),
+		interval(time.Wednesday, "17:00", "22:00"),
+	}}
+
+	open := map[string]bool{
+		"2024-01-03 11:29": false,
+		"2024-01-03 11:30": true,
+		"2024-01-03 13:59": true,
+		"2024-01-03 14:00": false,
+		"2024-01-03 18:00": true,
+		"2024-01-04 12:00": false, // Thursday
+	}
+	for value, expected := range open {
+		if IsRestaurantOpen(restaurant, at(t, value)) != expected {
+			t.Errorf("%s: expected open to be %t", value, expected)
+		}
+	}
+
+	dinner := at(t, "2024-01-03 17:00")
+	assertNextOpening(t, restaurant, at(t, "2024-01-03 15:00"), &dinner)
+	nextWeek := at(t, "2024-01-10 11:30")
+	assertNextOpening(t, restaurant, at(t, "2024-01-03 23:00"), &nextWeek)
+}
+
+func TestIntervalPastMidnight(t *testing.T) {
+	restaurant := &models.Restaurant{OpeningIntervals: []models.OpeningInterval{interval(time.Wednesday, "20:00", "02:00")}}
+
+	if !IsRestaurantOpen(restaurant, at(t, "2024-01-04 01:30")) {
+		t.Error("expected Wednesday's interval to run into Thursday night")
+	}
+	if IsRestaurantOpen(restaurant, at(t, "2024-01-04 02:00")) {
+		t.Error("expected the restaurant to close at 02:00")
+	}
+}
+
+func TestScheduleInRestaurantTimezone(t *testing.T) {
+	restaurant := &models.Restaurant{
+		Timezone:         "Asia/Seoul", // UTC+9
+		OpeningIntervals: []models.OpeningInterval{interval(time.Wednesday, "09:00", "10:00")},
+	}
+
+	if !IsRestaurantOpen(restaurant, at(t, "2024-01-03 00:30")) {
+		t.Error("expected the restaurant to be open at 09:30 in Seoul")
+	}
+	if IsRestaurantOpen(restaurant, at(t, "2024-01-03 09:30")) {
+		t.Error("expected the schedule to be read in Seoul time, not UTC")
+	}
+
+	restaurant.Timezone = "Nowhere/City"
+	if !IsRestaurantOpen(restaurant, at(t, "2024-01-03 09:30")) {
+		t.Error("expected an unknown timezone to fall back to UTC")
+	}
+}
+
+func TestExceptionsReplaceTheWeeklySchedule(t *testing.T) {
+	restaurant := &models.Restaurant{
+		OpeningIntervals: []models.OpeningInterval{
+			interval(time.Wednesday, "09:00", "17:00"),
+			interval(time.Thursday, "09:00", "17:00"),
+		},
+		OpeningExceptions: []models.OpeningException{
+			{Date: "2024-01-03", Closed: true},
+			{Date: "2024-01-03", OpensAt: "12:00", ClosesAt: "13:00"}, // the closure wins
+			{Date: "2024-01-04", OpensAt: "18:00", ClosesAt: "20:00"},
+		},
+	}
+
+	if IsRestaurantOpen(restaurant, at(t, "2024-01-03 12:30")) {
+		t.Error("expected the restaurant to be closed for the day")
+	}
+	if IsRestaurantOpen(restaurant, at(t, "2024-01-04 10:00")) || !IsRestaurantOpen(restaurant, at(t, "2024-01-04 19:00")) {
+		t.Error("expected the special hours to replace Thursday's schedule")
+	}
+	special := at(t, "2024-01-04 18:00")
+	assertNextOpening(t, restaurant, at(t, "2024-01-03 08:00"), &special)
+}
+
+func TestExceptionsWithoutWeeklyScheduleAreApplied(t *testing.T) {
+	restaurant := &models.Restaurant{OpeningExceptions: []models.OpeningException{{Date: "2024-01-03", Closed: true}}}
+
+	if !hasSchedule(restaurant) {
+		t.Fatal("expected the exception to count as a schedule")
+	}
+	if IsRestaurantOpen(restaurant, at(t, "2024-01-03 12:00")) || !IsRestaurantOpen(restaurant, at(t, "2024-01-04 12:00")) {
+		t.Error("expected the restaurant to be closed on the exception only")
+	}
+	midnight := at(t, "2024-01-04 00:00")
+	assertNextOpening(t, restaurant, at(t, "2024-01-03 12:00"), &midnight)
+}
+
+func TestNoOpeningWithinTheLookahead(t *testing.T) {
+	var exceptions []models.OpeningException
+	start := at(t, "2024-01-03 00:00")
+	for day := 0; day <= scheduleLookahead; day++ {
+		exceptions = append(exceptions, models.OpeningException{Date: start.AddDate(0, 0, day).Format("2006-01-02"), Closed: true})
+	}
+	restaurant := &models.Restaurant{OpeningExceptions: exceptions}
+	assertNextOpening(t, restaurant, at(t, "2024-01-03 12:00"), nil)
+}
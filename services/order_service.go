@@ -1,125 +1,378 @@
 package services
 
 import (
-	"log"
-	"madang_api/config"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"madang_api/metrics"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/tracing"
+	"madang_api/utils"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type OrderService struct{}
+// OrderService places and tracks orders. Placing an order or changing its status also moves the stock of
+// its ingredients, so those changes run in a transaction on db rather than through the repository
+type OrderService struct {
+	db          *gorm.DB
+	orders      repositories.OrderRepository
+	restaurants repositories.RestaurantRepository
+}
+
+func NewOrderService(db *gorm.DB, orders repositories.OrderRepository, restaurants repositories.RestaurantRepository) *OrderService {
+	return &OrderService{db: db, orders: orders, restaurants: restaurants}
+}
+
+// orderTransitions lists the statuses an order can move to from each status, completed and cancelled
+// orders are final
+var orderTransitions = map[string][]string{
+	"pending":   {"confirmed", "cancelled"},
+	"confirmed": {"completed", "cancelled"},
+	"completed": nil,
+	"cancelled": nil,
+}
+
+// ErrOrderStatusChange is returned by UpdateOrder when the order comes with another status, which only
+// UpdateOrderStatus changes since it also moves the stock
+var ErrOrderStatusChange = errors.New("the status of an order is changed through its status endpoint")
+
+// ErrOrderItemsLocked is returned by UpdateOrder when the items of an order that is no longer pending change,
+// its stock may already be deducted and a cancellation restores the items it was deducted for
+var ErrOrderItemsLocked = errors.New("only the items of a pending order can be changed")
+
+// ErrOrderNotDeletable is returned by DeleteOrder for confirmed and completed orders, whose stock is deducted
+var ErrOrderNotDeletable = errors.New("only pending and cancelled orders can be deleted")
+
+// checkTransition returns an error unless an order can move from one status to the other
+func checkTransition(from string, to string) error {
+	if _, ok := orderTransitions[to]; !ok {
+		return fmt.Errorf("unknown order status %q", to)
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("an order cannot go from %s to %s", from, to)
+}
 
 // Add a new order item return the order or error if it exist and also check if the order exist for that restaurant
-func (s *OrderService) AddOrder(order *models.Order) (*models.Order, error) {
+func (s *OrderService) AddOrder(ctx context.Context, order *models.Order) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.AddOrder",
+		attribute.Int64("restaurant.id", int64(order.RestaurantID)),
+		attribute.Int("order.foods", len(order.FoodOrders)),
+		attribute.Int("order.tables", len(order.TableOrders)),
+		attribute.Int("order.addons", len(order.AddonOrders)),
+	)
+	defer tracing.End(span, &err)
+
+	// Orders are placed either pending or already confirmed
+	if order.Status == "" {
+		order.Status = "pending"
+	}
+	if order.Status != "pending" && order.Status != "confirmed" {
+		return nil, fmt.Errorf("an order cannot be placed %s", order.Status)
+	}
+
+	// Refuse orders while the restaurant is closed
+	restaurant, err := s.restaurants.WithContext(ctx).FindWithSchedule(order.RestaurantID)
+	if err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	if !IsRestaurantOpen(restaurant, time.Now()) {
+		if next := NextOpening(restaurant, time.Now()); next != nil {
+			return nil, fmt.Errorf("restaurant is closed, it opens again at %s", next.Format(time.RFC3339))
+		}
+		return nil, errors.New("restaurant is closed")
+	}
+	if err := checkItemsAvailable(s.db.WithContext(ctx), order); err != nil {
+		return nil, err
+	}
+
 	// Begin a transaction
-	tx := config.DB.Begin()
+	tx := s.db.WithContext(ctx).Begin()
 
 	// Create the order
 	if err := tx.Create(&order).Error; err != nil {
 		tx.Rollback()
-		log.Printf("Error adding order: %v", err)
+		slog.Error("Failed to add order", "restaurant_id", order.RestaurantID, "error", err)
 		return nil, err
 	}
 
 	// Explicitly save associations
 	if err := tx.Model(&order).Association("FoodOrders").Append(order.FoodOrders); err != nil {
 		tx.Rollback()
-		log.Printf("Error adding food orders: %v", err)
+		slog.Error("Failed to add food orders", "restaurant_id", order.RestaurantID, "error", err)
 		return nil, err
 	}
 
 	if err := tx.Model(&order).Association("TableOrders").Append(order.TableOrders); err != nil {
 		tx.Rollback()
-		log.Printf("Error adding table orders: %v", err)
+		slog.Error("Failed to add table orders", "restaurant_id", order.RestaurantID, "error", err)
 		return nil, err
 	}
 
 	if err := tx.Model(&order).Association("AddonOrders").Append(order.AddonOrders); err != nil {
 		tx.Rollback()
-		log.Printf("Error adding addon orders: %v", err)
+		slog.Error("Failed to add addon orders", "restaurant_id", order.RestaurantID, "error", err)
 		return nil, err
 	}
 
 	if err := tx.Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table").First(&order, order.ID).Error; err != nil {
 		tx.Rollback()
-		log.Printf("Error preloading order details: %v", err)
+		slog.Error("Failed to preload order details", "restaurant_id", order.RestaurantID, "error", err)
 		return nil, err
 	}
 
+	// Orders placed already confirmed take their ingredients out of stock straight away
+	if order.Status == "confirmed" {
+		if err := deductOrderStock(tx, order); err != nil {
+			tx.Rollback()
+			slog.Error("Failed to deduct stock", "restaurant_id", order.RestaurantID, "error", err)
+			return nil, err
+		}
+	}
+
 	// Commit the transaction
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		slog.Error("Failed to commit order", "restaurant_id", order.RestaurantID, "error", err)
+		return nil, err
+	}
+	metrics.OrderCreated(order.Status, order.RestaurantID)
 	return order, nil
 }
 
-// UpdateOrder updates an existing order item and returns the updated order item or an error if it fails
-func (s *OrderService) UpdateOrder(order *models.Order) (*models.Order, error) {
-	if err := config.DB.Save(&order).Error; err != nil {
+// checkItemsAvailable returns an error unless every food and addon of the order is on the menu of its
+// restaurant, and every food is available. Addons take their ingredients out of the stock of their own
+// restaurant, so one of another restaurant would move the wrong stock
+func checkItemsAvailable(db *gorm.DB, order *models.Order) error {
+	if len(order.FoodOrders) > 0 {
+		ids := make([]uint, len(order.FoodOrders))
+		for i, foodOrder := range order.FoodOrders {
+			ids[i] = foodOrder.FoodID
+		}
+
+		var foods []models.Food
+		if err := db.Select("id", "name", "available").
+			Where("id IN ? AND restaurant_id = ?", ids, order.RestaurantID).Find(&foods).Error; err != nil {
+			return err
+		}
+		available := make(map[uint]bool, len(foods))
+		for _, food := range foods {
+			if !food.Available {
+				return fmt.Errorf("%s is not available", food.Name)
+			}
+			available[food.ID] = true
+		}
+		for _, id := range ids {
+			if !available[id] {
+				return fmt.Errorf("food %d is not on the menu of the restaurant", id)
+			}
+		}
+	}
+
+	if len(order.AddonOrders) > 0 {
+		ids := make([]uint, len(order.AddonOrders))
+		for i, addonOrder := range order.AddonOrders {
+			ids[i] = addonOrder.AddonID
+		}
+
+		var onMenu []uint
+		if err := db.Model(&models.Addon{}).Where("id IN ? AND restaurant_id = ?", ids, order.RestaurantID).Pluck("id", &onMenu).Error; err != nil {
+			return err
+		}
+		known := make(map[uint]bool, len(onMenu))
+		for _, id := range onMenu {
+			known[id] = true
+		}
+		for _, id := range ids {
+			if !known[id] {
+				return fmt.Errorf("addon %d is not on the menu of the restaurant", id)
+			}
+		}
+	}
+	return nil
+}
+
+// sameItems reports whether two versions of an order hold the same foods, addons and tables in the same quantities
+func sameItems(a *models.Order, b *models.Order) bool {
+	count := func(order *models.Order) map[string]int {
+		items := make(map[string]int)
+		for _, foodOrder := range order.FoodOrders {
+			items[fmt.Sprintf("food:%d", foodOrder.FoodID)] += foodOrder.Quantity
+		}
+		for _, addonOrder := range order.AddonOrders {
+			items[fmt.Sprintf("addon:%d", addonOrder.AddonID)] += addonOrder.Quantity
+		}
+		for _, tableOrder := range order.TableOrders {
+			items[fmt.Sprintf("table:%d", tableOrder.TableID)]++
+		}
+		return items
+	}
+
+	before, after := count(a), count(b)
+	if len(before) != len(after) {
+		return false
+	}
+	for item, quantity := range before {
+		if after[item] != quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateOrder updates an existing order item and returns the updated order item or an error if it fails.
+// The status is left to UpdateOrderStatus, an order coming with another status is refused. The items of
+// an order are replaced only while it is pending, before its stock is deducted
+func (s *OrderService) UpdateOrder(ctx context.Context, order *models.Order) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.UpdateOrder", attribute.Int64("order.id", int64(order.ID)))
+	defer tracing.End(span, &err)
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("FoodOrders").Preload("AddonOrders").Preload("TableOrders").First(&current, order.ID).Error; err != nil {
+			return err
+		}
+		if order.Status != current.Status {
+			return ErrOrderStatusChange
+		}
+		order.StockDeducted = current.StockDeducted
+
+		if !sameItems(&current, order) {
+			if current.Status != "pending" {
+				return ErrOrderItemsLocked
+			}
+			if err := checkItemsAvailable(tx, order); err != nil {
+				return err
+			}
+			if err := replaceOrderItems(tx, order); err != nil {
+				return err
+			}
+		}
+		return tx.Omit(clause.Associations).Save(order).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return order, nil
+	return s.GetOrder(ctx, order.ID)
 }
 
-// Delete a Order by ID
-func (s *OrderService) DeleteOrder(id uint) error {
-	var order models.Order
-	if err := config.DB.First(&order, id).Error; err != nil {
-		return err
+// replaceOrderItems swaps the stored foods, addons and tables of an order for the ones it holds
+func replaceOrderItems(tx *gorm.DB, order *models.Order) error {
+	for _, item := range []interface{}{&models.FoodOrder{}, &models.AddonOrder{}, &models.TableOrder{}} {
+		if err := tx.Where("order_id = ?", order.ID).Delete(item).Error; err != nil {
+			return err
+		}
 	}
-	if err := config.DB.Delete(&order).Error; err != nil {
-		return err
+	for i := range order.FoodOrders {
+		order.FoodOrders[i].ID, order.FoodOrders[i].OrderID = 0, order.ID
+	}
+	for i := range order.AddonOrders {
+		order.AddonOrders[i].ID, order.AddonOrders[i].OrderID = 0, order.ID
+	}
+	for i := range order.TableOrders {
+		order.TableOrders[i].ID, order.TableOrders[i].OrderID = 0, order.ID
+	}
+	if len(order.FoodOrders) > 0 {
+		if err := tx.Omit("Food").Create(&order.FoodOrders).Error; err != nil {
+			return err
+		}
+	}
+	if len(order.AddonOrders) > 0 {
+		if err := tx.Omit("Addon").Create(&order.AddonOrders).Error; err != nil {
+			return err
+		}
+	}
+	if len(order.TableOrders) > 0 {
+		return tx.Omit("Table").Create(&order.TableOrders).Error
 	}
 	return nil
 }
 
-// GetOrder retrieves a order item by its ID and returns the order item or an error if it fails
-func (s *OrderService) GetOrder(id uint) (*models.Order, error) {
+// UpdateOrderStatus moves an order to a new status, deducting stock when it is confirmed and restoring it when it is cancelled
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id uint, status string) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.UpdateOrderStatus",
+		attribute.Int64("order.id", int64(id)),
+		attribute.String("order.status", status),
+	)
+	defer tracing.End(span, &err)
+
 	var order models.Order
-	if err := config.DB.Preload("FoodOrders.Food").First(&order, id).Error; err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("FoodOrders").Preload("AddonOrders").First(&order, id).Error; err != nil {
+			return err
+		}
+		if err := checkTransition(order.Status, status); err != nil {
+			return err
+		}
+
+		switch status {
+		case "confirmed":
+			if err := deductOrderStock(tx, &order); err != nil {
+				return err
+			}
+		case "cancelled":
+			if err := restoreOrderStock(tx, &order); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&order).Update("status", status).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &order, nil
+	metrics.OrderStatusChanged(status, order.RestaurantID)
+	return s.GetOrder(ctx, id)
 }
 
-// GetAllOrders retrieves all order items and returns a slice of order items or an error if it fails
-func (s *OrderService) GetAllOrders() ([]models.Order, error) {
-	var orders []models.Order
-	if err := config.DB.Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table").Find(&orders).Error; err != nil {
-		return nil, err
+// Delete a Order by ID. Confirmed and completed orders hold deducted stock, they are cancelled instead
+func (s *OrderService) DeleteOrder(ctx context.Context, id uint) error {
+	orders := s.orders.WithContext(ctx)
+	order, err := orders.FindByID(id)
+	if err != nil {
+		return err
 	}
-	return orders, nil
+	if order.StockDeducted || (order.Status != "pending" && order.Status != "cancelled") {
+		return ErrOrderNotDeletable
+	}
+	return orders.Delete(id)
+}
+
+// GetOrder retrieves a order item by its ID and returns the order item or an error if it fails
+func (s *OrderService) GetOrder(ctx context.Context, id uint) (*models.Order, error) {
+	return s.orders.WithContext(ctx).FindByID(id)
+}
+
+// GetAllOrders retrieves a page of orders matching the query with their items and returns it with its pagination or an error if it fails
+func (s *OrderService) GetAllOrders(ctx context.Context, query *utils.ListQuery) ([]models.Order, *utils.Pagination, error) {
+	return s.orders.WithContext(ctx).List(query)
 }
 
 // GetRestaurantOrders retrieves all order items for a specific restaurant and returns a slice of order items or an error if it fails
-func (s *OrderService) GetRestaurantOrders(restaurantID uint) ([]models.Order, error) {
-	var orders []models.Order
-	if err := config.DB.Where("restaurant_id = ?", restaurantID).Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table").Find(&orders).Error; err != nil {
-		return nil, err
-	}
-	return orders, nil
+func (s *OrderService) GetRestaurantOrders(ctx context.Context, restaurantID uint) ([]models.Order, error) {
+	return s.orders.WithContext(ctx).FindByRestaurant(restaurantID)
 }
 
 // implement search for order it should be by name or fhe name of the category the order belongs too
-func (s *OrderService) SearchOrders(query string) ([]models.Order, error) {
-	var orders []models.Order
-	if err := config.DB.Where("name LIKE ? OR category_id IN (SELECT id FROM categories WHERE name LIKE ?)", "%"+query+"%", "%"+query+"%").Find(&orders).Error; err != nil {
-		return nil, err
-	}
-	return orders, nil
+func (s *OrderService) SearchOrders(ctx context.Context, query string) ([]models.Order, error) {
+	return s.orders.WithContext(ctx).SearchByName(query)
 }
 
 // Implement get user orders
-func (s *OrderService) GetUserOrders(userID uint) ([]models.Order, error) {
-	var orders []models.Order
-	if err := config.DB.Where("user_id = ?", userID).Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table").Find(&orders).Error; err != nil {
-		return nil, err
-	}
-	return orders, nil
+func (s *OrderService) GetUserOrders(ctx context.Context, userID uint) ([]models.Order, error) {
+	return s.orders.WithContext(ctx).FindByUser(userID)
 }
 
 // Get orders by status
-func (s *OrderService) GetOrdersByStatus(status string) ([]models.Order, error) {
-	var orders []models.Order
-	if err := config.DB.Where("status = ?", status).Preload("FoodOrders.Food").Preload("AddonOrders.Addon").Preload("TableOrders.Table").Find(&orders).Error; err != nil {
-		return nil, err
-	}
-	return orders, nil
+func (s *OrderService) GetOrdersByStatus(ctx context.Context, status string) ([]models.Order, error) {
+	return s.orders.WithContext(ctx).FindByStatus(status)
 }
@@ -1,49 +1,75 @@
 package services
 
 import (
-	"madang_api/config"
+	"context"
+	"madang_api/metrics"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/tracing"
+	"madang_api/utils"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type PaymentService struct{}
+type PaymentService struct {
+	payments repositories.PaymentRepository
+}
 
-func (s *PaymentService) GetPayments() ([]models.Payment, error) {
-	var payments []models.Payment
-	result := config.DB.Find(&payments)
-	return payments, result.Error
+func NewPaymentService(payments repositories.PaymentRepository) *PaymentService {
+	return &PaymentService{payments: payments}
 }
 
-func (s *PaymentService) GetPayment(id uint) (models.Payment, error) {
-	var payment models.Payment
-	result := config.DB.First(&payment, id)
-	return payment, result.Error
+func (s *PaymentService) GetPayments(ctx context.Context, query *utils.ListQuery) ([]models.Payment, *utils.Pagination, error) {
+	return s.payments.WithContext(ctx).List(query)
 }
 
-func (s *PaymentService) CreatePayment(payment *models.Payment) (*models.Payment, error) {
-	result := config.DB.Create(payment)
-	if result.Error != nil {
-		return nil, result.Error
+func (s *PaymentService) GetPayment(ctx context.Context, id uint) (models.Payment, error) {
+	payment, err := s.payments.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return models.Payment{}, err
 	}
+	return *payment, nil
+}
+
+func (s *PaymentService) CreatePayment(ctx context.Context, payment *models.Payment) (_ *models.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.CreatePayment",
+		attribute.Int64("order.id", int64(payment.OrderID)),
+		attribute.String("payment.method", payment.Method),
+	)
+	defer tracing.End(span, &err)
+
+	if err := s.payments.WithContext(ctx).Create(payment); err != nil {
+		return nil, err
+	}
+	metrics.PaymentRecorded(payment.Method, payment.Status, payment.RestaurantID)
 
 	return payment, nil
 }
 
-func (s *PaymentService) UpdatePayment(payment *models.Payment) (*models.Payment, error) {
-	result := config.DB.Save(payment)
-	if result.Error != nil {
-		return nil, result.Error
+// UpdatePayment saves the payment, which is counted again in the metrics when its status changes
+func (s *PaymentService) UpdatePayment(ctx context.Context, payment *models.Payment) (_ *models.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.UpdatePayment", attribute.Int64("payment.id", int64(payment.ID)))
+	defer tracing.End(span, &err)
+	payments := s.payments.WithContext(ctx)
+
+	previous, err := payments.FindByID(payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := payments.Save(payment); err != nil {
+		return nil, err
+	}
+	if payment.Status != previous.Status {
+		metrics.PaymentRecorded(payment.Method, payment.Status, payment.RestaurantID)
 	}
 
 	return payment, nil
 }
 
-func (s *PaymentService) DeletePayment(id uint) error {
-	result := config.DB.Delete(&models.Payment{}, id)
-	return result.Error
+func (s *PaymentService) DeletePayment(ctx context.Context, id uint) error {
+	return s.payments.WithContext(ctx).Delete(id)
 }
 
-func (s *PaymentService) GetRestaurantPayments(restaurantID uint) ([]models.Payment, error) {
-	var payments []models.Payment
-	result := config.DB.Where("restaurant_id = ?", restaurantID).Find(&payments)
-	return payments, result.Error
+func (s *PaymentService) GetRestaurantPayments(ctx context.Context, restaurantID uint) ([]models.Payment, error) {
+	return s.payments.WithContext(ctx).FindByRestaurant(restaurantID)
 }
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"madang_api/models"
+	"madang_api/tracing"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationService struct {
+	db *gorm.DB
+
+	// refreshing keeps two refreshes (the scheduler and a manual one) from rebuilding the table at the same time
+	refreshing sync.Mutex
+}
+
+func NewRecommendationService(db *gorm.DB) *RecommendationService {
+	return &RecommendationService{db: db}
+}
+
+const (
+	recommendationWindow   = 90 * 24 * time.Hour // orders older than this are ignored
+	recommendationHalfLife = 14.0                // days after which an order counts half as much
+	recommendationsPerList = 20                  // stored per user, restaurant and item type
+	recommendedLimit       = 5                   // served by the recommended endpoints
+)
+
+type signalWeight struct {
+	Signal string
+	Weight float64
+}
+
+// weights of the signals; personal ones only exist for users who ordered at the restaurant
+var (
+	personalWeights = []signalWeight{{"ordered_before", 0.35}, {"ordered_together", 0.30}, {"popular", 0.20}, {"top_rated", 0.15}}
+	generalWeights  = []signalWeight{{"popular", 0.70}, {"top_rated", 0.30}}
+)
+
+// Start recomputes the recommendations right away and then on every interval until ctx is cancelled
+func (s *RecommendationService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		started := time.Now()
+		if err := s.RefreshRecommendations(ctx); err != nil {
+			slog.Error("Failed to refresh recommendations", "error", err)
+		} else {
+			slog.Info("Recommendations refreshed", "elapsed_ms", time.Since(started).Milliseconds())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// orderLine is one item of one order, as used to score items
+type orderLine struct {
+	OrderID      uint
+	UserID       uint
+	RestaurantID uint
+	ItemID       uint
+	Quantity     int
+	CreatedAt    time.Time
+}
+
+// scoredItem is an item that can be recommended, with its rating normalized to 0..1
+type scoredItem struct {
+	ID           uint
+	RestaurantID uint
+	Rating       float64
+}
+
+// RefreshRecommendations recomputes every recommendation from the order history and replaces the stored ones
+func (s *RecommendationService) RefreshRecommendations(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "RecommendationService.RefreshRecommendations")
+	defer tracing.End(span, &err)
+
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+	since := now.Add(-recommendationWindow)
+
+	var foods []scoredItem
+	if err := db.Model(&models.Food{}).Select("id, restaurant_id, average_rating / 5 AS rating").Where("available = ?", true).Scan(&foods).Error; err != nil {
+		return err
+	}
+	var tables []scoredItem
+	if err := db.Model(&models.Table{}).Select("id, restaurant_id, average_rating / 5 AS rating").Scan(&tables).Error; err != nil {
+		return err
+	}
+
+	foodHistory, err := loadOrderHistory(db, db.Table("food_orders").
+		Select("orders.id AS order_id, orders.user_id, orders.restaurant_id, food_orders.food_id AS item_id, food_orders.quantity, orders.created_at").
+		Joins("JOIN orders ON orders.id = food_orders.order_id").
+		Where("orders.created_at >= ? AND orders.status <> ?", since, "cancelled"), foods, now)
+	if err != nil {
+		return err
+	}
+	tableHistory, err := loadOrderHistory(db, db.Table("table_orders").
+		Select("orders.id AS order_id, orders.user_id, orders.restaurant_id, table_orders.table_id AS item_id, 1 AS quantity, orders.created_at").
+		Joins("JOIN orders ON orders.id = table_orders.order_id").
+		Where("orders.created_at >= ? AND orders.status <> ?", since, "cancelled"), tables, now)
+	if err != nil {
+		return err
+	}
+
+	recommendations := scoreRecommendations("food", foods, foodHistory, now)
+	recommendations = append(recommendations, scoreRecommendations("table", tables, tableHistory, now)...)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.Recommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(recommendations, 500).Error
+	})
+}
+
+// decay weighs an order by its age so recent orders count more
+func decay(createdAt time.Time, now time.Time) float64 {
+	ageDays := now.Sub(createdAt).Hours() / 24
+	return math.Exp(-math.Ln2 * ageDays / recommendationHalfLife)
+}
+
+// orderHistory is the order history of one item type, aggregated while the order lines are read so
+// only the totals are held in memory
+type orderHistory struct {
+	popularity map[uint]float64                   // item -> decayed quantity
+	together   map[uint]map[uint]float64          // item -> item ordered in the same order -> decayed count
+	customers  map[uint]map[uint]map[uint]float64 // restaurant -> user -> item -> decayed number of orders
+
+	orderID uint             // order of the basket being read
+	basket  map[uint]float64 // item -> decay of the items of that order
+}
+
+func newOrderHistory() *orderHistory {
+	return &orderHistory{
+		popularity: make(map[uint]float64),
+		together:   make(map[uint]map[uint]float64),
+		customers:  make(map[uint]map[uint]map[uint]float64),
+		basket:     make(map[uint]float64),
+	}
+}
+
+// add counts one order line. The lines of an order must be added one after the other
+func (h *orderHistory) add(line orderLine, now time.Time) {
+	if line.OrderID != h.orderID {
+		h.closeBasket()
+		h.orderID = line.OrderID
+	}
+	weight := decay(line.CreatedAt, now)
+	h.popularity[line.ItemID] += float64(line.Quantity) * weight
+	h.basket[line.ItemID] = weight
+
+	users := h.customers[line.RestaurantID]
+	if users == nil {
+		users = make(map[uint]map[uint]float64)
+		h.customers[line.RestaurantID] = users
+	}
+	if users[line.UserID] == nil {
+		users[line.UserID] = make(map[uint]float64)
+	}
+	users[line.UserID][line.ItemID] += weight
+}
+
+// closeBasket counts the items of the current order as ordered together
+func (h *orderHistory) closeBasket() {
+	for a, weight := range h.basket {
+		for b := range h.basket {
+			if a == b {
+				continue
+			}
+			if h.together[a] == nil {
+				h.together[a] = make(map[uint]float64)
+			}
+			h.together[a][b] += weight
+		}
+	}
+	clear(h.basket)
+}
+
+// loadOrderHistory reads the order lines of query one row at a time, skipping the items that can't be
+// recommended
+func loadOrderHistory(db *gorm.DB, query *gorm.DB, items []scoredItem, now time.Time) (*orderHistory, error) {
+	known := make(map[uint]bool, len(items))
+	for _, item := range items {
+		known[item.ID] = true
+	}
+
+	rows, err := query.Order("orders.id").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := newOrderHistory()
+	for rows.Next() {
+		var line orderLine
+		if err := db.ScanRows(rows, &line); err != nil {
+			return nil, err
+		}
+		if !known[line.ItemID] {
+			continue // deleted or unavailable
+		}
+		history.add(line, now)
+	}
+	history.closeBasket()
+	return history, rows.Err()
+}
+
+// scoreRecommendations builds the general recommendations of every restaurant and the personal ones of
+// every user who ordered there
+func scoreRecommendations(itemType string, items []scoredItem, history *orderHistory, now time.Time) []models.Recommendation {
+	byRestaurant := make(map[uint][]scoredItem)
+	for _, item := range items {
+		byRestaurant[item.RestaurantID] = append(byRestaurant[item.RestaurantID], item)
+	}
+
+	var recommendations []models.Recommendation
+	for restaurantID, restaurantItems := range byRestaurant {
+		popular := normalize(restaurantItems, func(item scoredItem) float64 { return history.popularity[item.ID] })
+
+		rated := make(map[uint]float64)
+		for _, item := range restaurantItems {
+			rated[item.ID] = item.Rating
+		}
+		general := map[string]map[uint]float64{"popular": popular, "top_rated": rated}
+		recommendations = append(recommendations, rankItems(0, restaurantID, itemType, restaurantItems, general, generalWeights, now)...)
+
+		for userID, ordered := range history.customers[restaurantID] {
+			// items are only ordered together within a restaurant, so the user's orders there are enough
+			together := make(map[uint]float64)
+			for orderedID, orderedWeight := range ordered {
+				for itemID, count := range history.together[orderedID] {
+					together[itemID] += orderedWeight * count
+				}
+			}
+			signals := map[string]map[uint]float64{
+				"popular":          popular,
+				"top_rated":        rated,
+				"ordered_before":   normalize(restaurantItems, func(item scoredItem) float64 { return ordered[item.ID] }),
+				"ordered_together": normalize(restaurantItems, func(item scoredItem) float64 { return together[item.ID] }),
+			}
+			recommendations = append(recommendations, rankItems(userID, restaurantID, itemType, restaurantItems, signals, personalWeights, now)...)
+		}
+	}
+	return recommendations
+}
+
+// normalize scales a signal to 0..1 within one restaurant
+func normalize(items []scoredItem, value func(scoredItem) float64) map[uint]float64 {
+	scores := make(map[uint]float64)
+	max := 0.0
+	for _, item := range items {
+		scores[item.ID] = value(item)
+		if scores[item.ID] > max {
+			max = scores[item.ID]
+		}
+	}
+	if max > 0 {
+		for id := range scores {
+			scores[id] /= max
+		}
+	}
+	return scores
+}
+
+// rankItems combines the signals of every item and keeps the best ones. The reason is the signal that
+// contributed most to the score
+func rankItems(userID uint, restaurantID uint, itemType string, items []scoredItem, signals map[string]map[uint]float64, weights []signalWeight, now time.Time) []models.Recommendation {
+	ranked := make([]models.Recommendation, 0, len(items))
+	for _, item := range items {
+		recommendation := models.Recommendation{
+			UserID:       userID,
+			RestaurantID: restaurantID,
+			ItemType:     itemType,
+			ItemID:       item.ID,
+			ComputedAt:   now,
+		}
+		best := 0.0
+		for _, weight := range weights {
+			contribution := weight.Weight * signals[weight.Signal][item.ID]
+			recommendation.Score += contribution
+			if contribution > best {
+				best = contribution
+				recommendation.Reason = weight.Signal
+			}
+		}
+		if recommendation.Score > 0 {
+			ranked = append(ranked, recommendation)
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ItemID < ranked[j].ItemID
+	})
+	if len(ranked) > recommendationsPerList {
+		ranked = ranked[:recommendationsPerList]
+	}
+	return ranked
+}
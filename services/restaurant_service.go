@@ -1,33 +1,45 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"madang_api/config"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
+	"time"
 )
 
-type RestaurantService struct{}
+type RestaurantService struct {
+	restaurants repositories.RestaurantRepository
+	users       repositories.UserRepository
+}
+
+func NewRestaurantService(restaurants repositories.RestaurantRepository, users repositories.UserRepository) *RestaurantService {
+	return &RestaurantService{restaurants: restaurants, users: users}
+}
 
 // Add a new restaurant
 func (s *RestaurantService) AddRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error) {
 	// Check if the restaurant already exists
-	var existingRestaurant models.Restaurant
-	if err := config.DB.Where("name = ?", restaurant.Name).First(&existingRestaurant).Error; err == nil {
+	if _, err := s.restaurants.FindByName(restaurant.Name); err == nil {
 		return nil, errors.New("restaurant already exists")
 	}
 	//verify that the user creating the restaurant has a role of manager and is email verified
-	var user models.User
 	// if the user is not found, return an error
-	if err := config.DB.First(&user, restaurant.UserID).Error; err != nil {
+	user, err := s.users.FindByID(restaurant.UserID)
+	if err != nil {
 		return nil, errors.New("user not found")
 	}
 	// if the user is not a manager or the email is not verified, return an error
 	if user.Role != "manager" || user.EmailVerified == false {
 		return nil, errors.New("user is not authorized to create a restaurant")
 	}
+	if err := validateCoordinates(restaurant.Latitude, restaurant.Longitude); err != nil {
+		return nil, err
+	}
 
 	// Add the new restaurant
-	if err := config.DB.Create(&restaurant).Error; err != nil {
+	if err := s.restaurants.Create(restaurant); err != nil {
 		return nil, err
 	}
 
@@ -35,28 +47,25 @@ func (s *RestaurantService) AddRestaurant(restaurant *models.Restaurant) (*model
 }
 
 // Get a restaurant by ID
-func (s *RestaurantService) GetRestaurantByID(id uint) (models.Restaurant, error) {
-	var restaurant models.Restaurant
-	if err := config.DB.First(&restaurant, id).Error; err != nil {
+func (s *RestaurantService) GetRestaurantByID(ctx context.Context, id uint) (models.Restaurant, error) {
+	restaurant, err := s.restaurants.WithContext(ctx).FindWithSchedule(id)
+	if err != nil {
 		return models.Restaurant{}, err
 	}
-	return restaurant, nil
+	setOpeningStatus(restaurant, time.Now())
+	return *restaurant, nil
 }
 
-// Get all restaurants
-func (s *RestaurantService) GetAllRestaurants() ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
-	if err := config.DB.Find(&restaurants).Error; err != nil {
-		return nil, err
-	}
-	return restaurants, nil
+// Get a page of the restaurants matching the query, only the ones open right now when openNow is set
+func (s *RestaurantService) GetAllRestaurants(query *utils.ListQuery, openNow bool) ([]models.Restaurant, *utils.Pagination, error) {
+	return s.listRestaurants(query, openNow)
 }
 
 // Update a restaurant by ID
 func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant) (models.Restaurant, error) {
 	// Check if the restaurant exists
-	var existingRestaurant models.Restaurant
-	if err := config.DB.First(&existingRestaurant, restaurant.ID).Error; err != nil {
+	existingRestaurant, err := s.restaurants.FindByID(restaurant.ID)
+	if err != nil {
 		return models.Restaurant{}, err
 	}
 
@@ -70,14 +79,19 @@ func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant) (mod
 	if restaurant.Location != "" {
 		existingRestaurant.Location = restaurant.Location
 	}
-	if restaurant.Verified != existingRestaurant.Verified {
-		existingRestaurant.Verified = restaurant.Verified
-	}
-	if restaurant.Active != existingRestaurant.Active {
-		existingRestaurant.Active = restaurant.Active
+	if restaurant.Latitude != nil || restaurant.Longitude != nil {
+		if err := validateCoordinates(restaurant.Latitude, restaurant.Longitude); err != nil {
+			return models.Restaurant{}, err
+		}
+		existingRestaurant.Latitude = restaurant.Latitude
+		existingRestaurant.Longitude = restaurant.Longitude
 	}
-	if restaurant.VerfiedAt != "" {
-		existingRestaurant.VerfiedAt = restaurant.VerfiedAt
+	// Verified, VerifiedAt and Active are only changed through the verification workflow
+	if restaurant.Timezone != "" {
+		if _, err := time.LoadLocation(restaurant.Timezone); err != nil {
+			return models.Restaurant{}, errors.New("unknown timezone " + restaurant.Timezone)
+		}
+		existingRestaurant.Timezone = restaurant.Timezone
 	}
 	if restaurant.State != "" {
 		existingRestaurant.State = restaurant.State
@@ -88,69 +102,183 @@ func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant) (mod
 	if restaurant.Phone != "" {
 		existingRestaurant.Phone = restaurant.Phone
 	}
-	if restaurant.UserID != 0 {
-		// Ensure the user ID is valid
-		var user models.User
-		if err := config.DB.First(&user, restaurant.UserID).Error; err != nil {
-			return models.Restaurant{}, err // Return error if user does not exist
-		}
-		existingRestaurant.UserID = restaurant.UserID
-	}
+	// UserID is only changed by TransferRestaurant
 
 	// Save the updated restaurant
-	if err := config.DB.Save(&existingRestaurant).Error; err != nil {
+	if err := s.restaurants.Save(existingRestaurant); err != nil {
 		return models.Restaurant{}, err
 	}
 
-	return existingRestaurant, nil
+	return *existingRestaurant, nil
+}
+
+// Hand a restaurant over to another manager, who becomes its owner
+func (s *RestaurantService) TransferRestaurant(id uint, userID uint) (models.Restaurant, error) {
+	restaurant, err := s.restaurants.FindByID(id)
+	if err != nil {
+		return models.Restaurant{}, err
+	}
+	// the new owner must be allowed to create restaurants themselves
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return models.Restaurant{}, errors.New("user not found")
+	}
+	if user.Role != "manager" || !user.EmailVerified {
+		return models.Restaurant{}, errors.New("a restaurant can only be transferred to a verified manager")
+	}
+
+	restaurant.UserID = user.ID
+	if err := s.restaurants.Save(restaurant); err != nil {
+		return models.Restaurant{}, err
+	}
+	return *restaurant, nil
 }
 
 // Delete a restaurant by ID
 func (s *RestaurantService) DeleteRestaurant(id uint) error {
-	var restaurant models.Restaurant
-	if err := config.DB.First(&restaurant, id).Error; err != nil {
-		return err
-	}
-	if err := config.DB.Delete(&restaurant).Error; err != nil {
+	if _, err := s.restaurants.FindByID(id); err != nil {
 		return err
 	}
-	return nil
+	return s.restaurants.Delete(id)
 }
 
-// Get All Verified Restaurants
-func (s *RestaurantService) GetAllVerifiedRestaurants() ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
-	if err := config.DB.Where("verified = ?", true).Find(&restaurants).Error; err != nil {
-		return nil, err
+// Get a page of the verified restaurants, only the ones open right now when openNow is set
+func (s *RestaurantService) GetAllVerifiedRestaurants(query *utils.ListQuery, openNow bool) ([]models.Restaurant, *utils.Pagination, error) {
+	return s.listRestaurants(query.Where("verified", true), openNow)
+}
+
+// Get a page of the restaurants of a user
+func (s *RestaurantService) GetUserRestaurants(id uint, query *utils.ListQuery) ([]models.Restaurant, *utils.Pagination, error) {
+	return s.listRestaurants(query.Where("user_id", id), false)
+}
+
+// listRestaurants pages restaurants with their schedule. Whether a restaurant is open is only known once it
+// is loaded, so with openNow every matching restaurant is loaded and the open ones are paged in memory
+func (s *RestaurantService) listRestaurants(query *utils.ListQuery, openNow bool) ([]models.Restaurant, *utils.Pagination, error) {
+	if !openNow {
+		restaurants, pagination, err := s.restaurants.List(query)
+		if err != nil {
+			return nil, nil, err
+		}
+		setOpeningStatuses(restaurants)
+		return restaurants, pagination, nil
+	}
+
+	restaurants, err := s.restaurants.ListAll(query)
+	if err != nil {
+		return nil, nil, err
 	}
-	return restaurants, nil
+	setOpeningStatuses(restaurants)
+	page, pagination := utils.PaginateSlice(filterOpenRestaurants(restaurants), query)
+	return page, pagination, nil
 }
 
-// Get User Restaurants	by ID
-func (s *RestaurantService) GetUserRestaurants(id uint) ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
-	if err := config.DB.Where("user_id = ?", id).Find(&restaurants).Error; err != nil {
+// RestaurantSearchResult is a restaurant matched by SearchRestaurants with its relevance and highlighted snippet
+type RestaurantSearchResult struct {
+	models.Restaurant
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// search restaurant by name, then address/location, then state/country, matching words as prefixes
+// and tolerating typos in the name. Results come back most relevant first
+func (s *RestaurantService) SearchRestaurants(query string, restaurantID uint) ([]RestaurantSearchResult, error) {
+	return searchRestaurants(s.restaurants, query, restaurantID)
+}
+
+// searchRestaurants loads the hit restaurants with their opening status, most relevant first
+func searchRestaurants(repository repositories.RestaurantRepository, query string, restaurantID uint) ([]RestaurantSearchResult, error) {
+	hits, err := repository.Search(query, restaurantID)
+	if err != nil {
 		return nil, err
 	}
-	return restaurants, nil
-}
 
-// search restaurant by name,location,address,state,country
-func (s *RestaurantService) SearchRestaurants(query string) ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
-	if err := config.DB.Where("name LIKE ? OR location LIKE ? OR address LIKE ? OR state LIKE ? OR country LIKE ?", "%"+query+"%", "%"+query+"%", "%"+query+"%", "%"+query+"%", "%"+query+"%").Find(&restaurants).Error; err != nil {
+	restaurants, err := repository.FindByIDsWithSchedule(hitIDs(hits))
+	if err != nil {
 		return nil, err
 	}
-	return restaurants, nil
+	setOpeningStatuses(restaurants)
+	byID := make(map[uint]models.Restaurant)
+	for _, restaurant := range restaurants {
+		byID[restaurant.ID] = restaurant
+	}
+
+	results := []RestaurantSearchResult{}
+	for _, hit := range hits {
+		if restaurant, ok := byID[hit.ID]; ok {
+			results = append(results, RestaurantSearchResult{Restaurant: restaurant, Rank: hit.Rank, Snippet: hit.Snippet})
+		}
+	}
+	return results, nil
 }
 
 // filter restaurant by rating,location
 func (s *RestaurantService) FilterRestaurants(state string, country string, location string) ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
-	if err := config.DB.Where("state = ? AND location = ? AND country = ? AND ", state, location, country).Find(&restaurants).Error; err != nil {
+	return s.restaurants.FindByPlace(state, country, location)
+}
+
+// NearbyRestaurant is a restaurant returned by GetNearbyRestaurants with its distance from the searched point
+type NearbyRestaurant struct {
+	models.Restaurant
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// validateCoordinates makes sure latitude and longitude are given together and within range
+func validateCoordinates(latitude *float64, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return errors.New("latitude and longitude must be provided together")
+	}
+	if *latitude < -90 || *latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if *longitude < -180 || *longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// GetNearbyRestaurants returns the restaurants within radiusKm of the given point, closest first
+func (s *RestaurantService) GetNearbyRestaurants(ctx context.Context, latitude float64, longitude float64, radiusKm float64, openNow bool) ([]NearbyRestaurant, error) {
+	if err := validateCoordinates(&latitude, &longitude); err != nil {
+		return nil, err
+	}
+	if radiusKm <= 0 {
+		return nil, errors.New("radius must be greater than zero")
+	}
+
+	repository := s.restaurants.WithContext(ctx)
+	hits, err := repository.WithinRadius(latitude, longitude, radiusKm)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	restaurants, err := repository.FindByIDsWithSchedule(ids)
+	if err != nil {
 		return nil, err
 	}
-	return restaurants, nil
+	setOpeningStatuses(restaurants)
+	if openNow {
+		restaurants = filterOpenRestaurants(restaurants)
+	}
+	byID := make(map[uint]models.Restaurant)
+	for _, restaurant := range restaurants {
+		byID[restaurant.ID] = restaurant
+	}
+
+	results := []NearbyRestaurant{}
+	for _, hit := range hits {
+		if restaurant, ok := byID[hit.ID]; ok {
+			results = append(results, NearbyRestaurant{Restaurant: restaurant, DistanceKm: hit.DistanceKm})
+		}
+	}
+	return results, nil
 }
 
 // Get All Restaurants with pagination
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"madang_api/repositories"
+	"madang_api/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type SearchService struct {
+	restaurants repositories.RestaurantRepository
+	foods       repositories.FoodRepository
+	addons      repositories.AddonRepository
+	tables      repositories.TableRepository
+	categories  repositories.CategoryRepository
+}
+
+func NewSearchService(restaurants repositories.RestaurantRepository, foods repositories.FoodRepository, addons repositories.AddonRepository, tables repositories.TableRepository, categories repositories.CategoryRepository) *SearchService {
+	return &SearchService{restaurants: restaurants, foods: foods, addons: addons, tables: tables, categories: categories}
+}
+
+// SearchTypes are the kinds of items GlobalSearch looks through, in the order they are returned
+var SearchTypes = []string{"restaurants", "foods", "addons", "tables", "categories"}
+
+// GlobalSearchResults groups the hits of a global search by type, each group ranked most relevant first
+type GlobalSearchResults struct {
+	Restaurants []RestaurantSearchResult `json:"restaurants"`
+	Foods       []FoodSearchResult       `json:"foods"`
+	Addons      []AddonSearchResult      `json:"addons"`
+	Tables      []TableSearchResult      `json:"tables"`
+	Categories  []CategorySearchResult   `json:"categories"`
+}
+
+// GlobalSearch is the response of a global search. Facets always count the matches of every type,
+// even the ones left out of the results, so clients can show how many hits each tab would have
+type GlobalSearch struct {
+	Query        string              `json:"query"`
+	RestaurantID uint                `json:"restaurant_id,omitempty"`
+	Total        int64               `json:"total"`
+	Facets       map[string]int64    `json:"facets"`
+	Results      GlobalSearchResults `json:"results"`
+}
+
+// Search looks for the query across restaurants, foods, addons, tables and categories at once.
+// A non zero restaurantID restricts every type to that restaurant, and types restricts which
+// groups are filled in (all of them when empty)
+func (s *SearchService) Search(ctx context.Context, query string, restaurantID uint, types []string) (_ *GlobalSearch, err error) {
+	ctx, span := tracing.Start(ctx, "SearchService.Search", attribute.Int64("restaurant.id", int64(restaurantID)))
+	defer tracing.End(span, &err)
+
+	restaurants := s.restaurants.WithContext(ctx)
+	foods := s.foods.WithContext(ctx)
+	addons := s.addons.WithContext(ctx)
+	tables := s.tables.WithContext(ctx)
+	categories := s.categories.WithContext(ctx)
+	counters := map[string]func(query string, restaurantID uint) (int64, error){
+		"restaurants": restaurants.CountSearch,
+		"foods":       foods.CountSearch,
+		"addons":      addons.CountSearch,
+		"tables":      tables.CountSearch,
+		"categories":  categories.CountSearch,
+	}
+
+	result := &GlobalSearch{
+		Query:        query,
+		RestaurantID: restaurantID,
+		Facets:       make(map[string]int64),
+		Results: GlobalSearchResults{
+			Restaurants: []RestaurantSearchResult{},
+			Foods:       []FoodSearchResult{},
+			Addons:      []AddonSearchResult{},
+			Tables:      []TableSearchResult{},
+			Categories:  []CategorySearchResult{},
+		},
+	}
+
+	for _, searchType := range SearchTypes {
+		count, err := counters[searchType](query, restaurantID)
+		if err != nil {
+			return nil, err
+		}
+		result.Facets[searchType] = count
+		result.Total += count
+	}
+
+	wanted := make(map[string]bool)
+	for _, searchType := range types {
+		wanted[searchType] = true
+	}
+	include := func(searchType string) bool {
+		return len(wanted) == 0 || wanted[searchType]
+	}
+
+	if include("restaurants") && result.Facets["restaurants"] > 0 {
+		if result.Results.Restaurants, err = searchRestaurants(restaurants, query, restaurantID); err != nil {
+			return nil, err
+		}
+	}
+	if include("foods") && result.Facets["foods"] > 0 {
+		if result.Results.Foods, err = searchFoods(foods, query, restaurantID); err != nil {
+			return nil, err
+		}
+	}
+	if include("addons") && result.Facets["addons"] > 0 {
+		if result.Results.Addons, err = searchAddons(addons, query, restaurantID); err != nil {
+			return nil, err
+		}
+	}
+	if include("tables") && result.Facets["tables"] > 0 {
+		if result.Results.Tables, err = searchTables(tables, query, restaurantID); err != nil {
+			return nil, err
+		}
+	}
+	if include("categories") && result.Facets["categories"] > 0 {
+		if result.Results.Categories, err = searchCategories(categories, query, restaurantID); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// hitIDs returns the ids of the hits in ranking order
+func hitIDs(hits []repositories.SearchHit) []uint {
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"madang_api/models"
+	"madang_api/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StaffService struct {
+	db     *gorm.DB
+	brands *BrandService // owners of a brand own all of its branches
+}
+
+func NewStaffService(db *gorm.DB, brands *BrandService) *StaffService {
+	return &StaffService{db: db, brands: brands}
+}
+
+// Permissions a staff role can be granted on its restaurant
+const (
+	PermissionViewOrders   = "orders.view"
+	PermissionManageOrders = "orders.manage"
+	PermissionViewTables   = "tables.view"
+	PermissionManageTables = "tables.manage"
+	PermissionViewMenu     = "menu.view"
+	PermissionManageMenu   = "menu.manage"
+	PermissionManageStaff  = "staff.manage"
+
+	PermissionManageRestaurant = "restaurant.manage" // edit the details of the restaurant
+	PermissionOwnRestaurant    = "restaurant.own"    // delete the restaurant or hand it over to someone else
+)
+
+// RolePermissions lists what each staff role may do on its restaurant
+var RolePermissions = map[string][]string{
+	"owner":   {PermissionViewOrders, PermissionManageOrders, PermissionViewTables, PermissionManageTables, PermissionViewMenu, PermissionManageMenu, PermissionManageStaff, PermissionManageRestaurant, PermissionOwnRestaurant},
+	"manager": {PermissionViewOrders, PermissionManageOrders, PermissionViewTables, PermissionManageTables, PermissionViewMenu, PermissionManageMenu, PermissionManageStaff, PermissionManageRestaurant},
+	"waiter":  {PermissionViewOrders, PermissionManageOrders, PermissionViewTables, PermissionManageTables, PermissionViewMenu},
+	"chef":    {PermissionViewOrders, PermissionManageOrders, PermissionViewMenu, PermissionManageMenu},
+	"cashier": {PermissionViewOrders, PermissionManageOrders, PermissionViewTables, PermissionViewMenu},
+}
+
+// HasPermission reports whether a user may perform the given action on a restaurant.
+// Admins may do anything, the restaurant's own manager (Restaurant.UserID) and the owner of its brand are
+// treated as its owner, and everyone else needs an active staff membership whose role grants the permission
+func (s *StaffService) HasPermission(ctx context.Context, user models.User, restaurantID uint, permission string) (bool, error) {
+	if user.Role == "admin" {
+		return true, nil
+	}
+
+	db := s.db.WithContext(ctx)
+	var restaurant models.Restaurant
+	if err := db.Select("id", "user_id", "brand_id").First(&restaurant, restaurantID).Error; err != nil {
+		return false, err
+	}
+	if restaurant.UserID == user.ID || s.ownsBrandOf(ctx, user, &restaurant) {
+		return true, nil
+	}
+
+	var staff models.RestaurantStaff
+	if err := db.Where("restaurant_id = ? AND user_id = ? AND status = ?", restaurantID, user.ID, "active").First(&staff).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return roleHasPermission(staff.Role, permission), nil
+}
+
+func roleHasPermission(role string, permission string) bool {
+	for _, granted := range RolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ownsBrandOf reports whether the user owns the brand the restaurant is a branch of
+func (s *StaffService) ownsBrandOf(ctx context.Context, user models.User, restaurant *models.Restaurant) bool {
+	return restaurant.BrandID != nil && s.brands.IsBrandOwner(ctx, user, *restaurant.BrandID)
+}
+
+// isRestaurantOwner reports whether the user owns the restaurant, either as its manager, as the owner of
+// its brand or through an owner membership
+func (s *StaffService) isRestaurantOwner(user models.User, restaurantID uint) bool {
+	if user.Role == "admin" {
+		return true
+	}
+	var restaurant models.Restaurant
+	if err := s.db.Select("id", "user_id", "brand_id").First(&restaurant, restaurantID).Error; err == nil && (restaurant.UserID == user.ID || s.ownsBrandOf(context.Background(), user, &restaurant)) {
+		return true
+	}
+	err := s.db.Where("restaurant_id = ? AND user_id = ? AND status = ? AND role = ?", restaurantID, user.ID, "active", "owner").First(&models.RestaurantStaff{}).Error
+	return err == nil
+}
+
+// InviteStaff invites someone by email to join a restaurant with the given role. The invitation token is
+// returned so it can be delivered to the invitee; if they already have an account they are notified in the app
+func (s *StaffService) InviteStaff(inviter models.User, restaurantID uint, email string, role string) (*models.RestaurantStaff, string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, "", errors.New("email cannot be empty")
+	}
+	if _, ok := RolePermissions[role]; !ok {
+		return nil, "", fmt.Errorf("unknown role %q", role)
+	}
+	if role == "owner" && !s.isRestaurantOwner(inviter, restaurantID) {
+		return nil, "", errors.New("only an owner can invite another owner")
+	}
+
+	var restaurant models.Restaurant
+	if err := s.db.First(&restaurant, restaurantID).Error; err != nil {
+		return nil, "", errors.New("restaurant not found")
+	}
+	if err := s.db.Where("restaurant_id = ? AND lower(email) = ?", restaurantID, email).First(&models.RestaurantStaff{}).Error; err == nil {
+		return nil, "", errors.New("this email is already part of the restaurant staff or invited")
+	}
+
+	token, err := utils.GenerateToken(24)
+	if err != nil {
+		return nil, "", err
+	}
+	staff := models.RestaurantStaff{
+		RestaurantID: restaurantID,
+		Email:        email,
+		Role:         role,
+		Status:       "invited",
+		InviteToken:  token,
+		InvitedBy:    inviter.ID,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&staff).Error; err != nil {
+			return err
+		}
+		var invitee models.User
+		if err := tx.Where("lower(email) = ?", email).First(&invitee).Error; err == nil {
+			return notify(tx, invitee.ID, "staff_invitation", "Staff invitation",
+				fmt.Sprintf("You have been invited to join %s as %s.", restaurant.Name, role))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &staff, token, nil
+}
+
+// AcceptInvitation links a pending invitation to the logged in user. The invitation must have been sent to their email
+func (s *StaffService) AcceptInvitation(user models.User, token string) (*models.RestaurantStaff, error) {
+	var staff models.RestaurantStaff
+	if err := s.db.Where("invite_token = ? AND status = ?", token, "invited").First(&staff).Error; err != nil {
+		return nil, errors.New("invitation not found or already used")
+	}
+	if !strings.EqualFold(staff.Email, user.Email) {
+		return nil, errors.New("this invitation was sent to another email")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&staff).Updates(map[string]interface{}{
+		"user_id":      user.ID,
+		"status":       "active",
+		"invite_token": "",
+		"accepted_at":  now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return s.GetStaff(staff.ID)
+}
+
+// GetMyInvitations retrieves the pending invitations sent to the user's email
+func (s *StaffService) GetMyInvitations(user models.User) ([]models.RestaurantStaff, error) {
+	var invitations []models.RestaurantStaff
+	if err := s.db.Where("lower(email) = ? AND status = ?", strings.ToLower(user.Email), "invited").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// GetStaff retrieves a staff membership by its ID
+func (s *StaffService) GetStaff(id uint) (*models.RestaurantStaff, error) {
+	var staff models.RestaurantStaff
+	if err := s.db.First(&staff, id).Error; err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+// GetRestaurantStaff retrieves the staff (members and pending invitations) of a restaurant
+func (s *StaffService) GetRestaurantStaff(restaurantID uint) ([]models.RestaurantStaff, error) {
+	var staff []models.RestaurantStaff
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Order("id").Find(&staff).Error; err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// UpdateStaffRole changes the role of a staff member
+func (s *StaffService) UpdateStaffRole(actor models.User, id uint, role string) (*models.RestaurantStaff, error) {
+	staff, err := s.GetStaff(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := RolePermissions[role]; !ok {
+		return nil, fmt.Errorf("unknown role %q", role)
+	}
+	if (role == "owner" || staff.Role == "owner") && !s.isRestaurantOwner(actor, staff.RestaurantID) {
+		return nil, errors.New("only an owner can change the owner role")
+	}
+
+	if err := s.db.Model(staff).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// RemoveStaff removes a staff member or cancels a pending invitation
+func (s *StaffService) RemoveStaff(actor models.User, id uint) error {
+	staff, err := s.GetStaff(id)
+	if err != nil {
+		return err
+	}
+	if staff.Role == "owner" && !s.isRestaurantOwner(actor, staff.RestaurantID) {
+		return errors.New("only an owner can remove another owner")
+	}
+	return s.db.Delete(staff).Error
+}
@@ -1,23 +1,31 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"madang_api/config"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
 )
 
-type TableService struct{}
+type TableService struct {
+	tables          repositories.TableRepository
+	recommendations repositories.RecommendationRepository
+}
+
+func NewTableService(tables repositories.TableRepository, recommendations repositories.RecommendationRepository) *TableService {
+	return &TableService{tables: tables, recommendations: recommendations}
+}
 
 // Add a new table item return the table or error if it exist and also check if the table exist for that restaurant
 func (s *TableService) AddTable(table *models.Table) (*models.Table, error) {
-	var existingTable models.Table
 	//Check if the table exist for that restaurant
-	if err := config.DB.Where("restaurant_id = ? AND name = ? ", existingTable.RestaurantID, existingTable.Name).First(&models.Table{}).Error; err == nil {
+	if _, err := s.tables.FindByName(table.RestaurantID, table.Name); err == nil {
 		return nil, errors.New("table already exists for this restaurant")
 	}
 
 	//Add the table item
-	if err := config.DB.Create(&table).Error; err != nil {
+	if err := s.tables.Create(table); err != nil {
 		return nil, err
 	}
 
@@ -26,7 +34,7 @@ func (s *TableService) AddTable(table *models.Table) (*models.Table, error) {
 
 // UpdateTable updates an existing table item and returns the updated table item or an error if it fails
 func (s *TableService) UpdateTable(table *models.Table) (*models.Table, error) {
-	if err := config.DB.Save(&table).Error; err != nil {
+	if err := s.tables.Save(table); err != nil {
 		return nil, err
 	}
 	return table, nil
@@ -34,57 +42,90 @@ func (s *TableService) UpdateTable(table *models.Table) (*models.Table, error) {
 
 // Delete a Table by ID
 func (s *TableService) DeleteTable(id uint) error {
-	var table models.Table
-	if err := config.DB.First(&table, id).Error; err != nil {
+	if _, err := s.tables.FindByID(id); err != nil {
 		return err
 	}
-	if err := config.DB.Delete(&table).Error; err != nil {
-		return err
-	}
-	return nil
+	return s.tables.Delete(id)
 }
 
 // GetTable retrieves a table item by its ID and returns the table item or an error if it fails
 func (s *TableService) GetTable(id uint) (*models.Table, error) {
-	var table models.Table
-	if err := config.DB.First(&table, id).Error; err != nil {
-		return nil, err
-	}
-	return &table, nil
+	return s.tables.FindByID(id)
 }
 
-// GetAllTables retrieves all table items and returns a slice of table items or an error if it fails
-func (s *TableService) GetAllTables() ([]models.Table, error) {
-	var tables []models.Table
-	if err := config.DB.Find(&tables).Error; err != nil {
-		return nil, err
-	}
-	return tables, nil
+// GetAllTables retrieves a page of table items matching the query and returns it with its pagination or an error if it fails
+func (s *TableService) GetAllTables(query *utils.ListQuery) ([]models.Table, *utils.Pagination, error) {
+	return s.tables.List(query)
 }
 
 // GetRestaurantTables retrieves all table items for a specific restaurant and returns a slice of table items or an error if it fails
-func (s *TableService) GetRestaurantTables(restaurantID uint) ([]models.Table, error) {
-	var tables []models.Table
-	if err := config.DB.Where("restaurant_id = ?", restaurantID).Find(&tables).Error; err != nil {
+func (s *TableService) GetRestaurantTables(ctx context.Context, restaurantID uint) ([]models.Table, error) {
+	return s.tables.WithContext(ctx).FindByRestaurant(restaurantID)
+}
+
+// TableSearchResult is a table matched by SearchTables with its relevance and highlighted snippet
+type TableSearchResult struct {
+	models.Table
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchTables runs a full-text search over the table name and category name, matching words as prefixes
+// and tolerating typos in the name. Results come back most relevant first
+func (s *TableService) SearchTables(query string, restaurantID uint) ([]TableSearchResult, error) {
+	return searchTables(s.tables, query, restaurantID)
+}
+
+// searchTables is SearchTables on any table repository, such as one bound to a request context
+func searchTables(repository repositories.TableRepository, query string, restaurantID uint) ([]TableSearchResult, error) {
+	hits, err := repository.Search(query, restaurantID)
+	if err != nil {
 		return nil, err
 	}
-	return tables, nil
-}
 
-// implement search for table it should be by name or fhe name of the category the table belongs too
-func (s *TableService) SearchTables(query string) ([]models.Table, error) {
-	var tables []models.Table
-	if err := config.DB.Where("name LIKE ? OR category_id IN (SELECT id FROM categories WHERE name LIKE ?)", "%"+query+"%", "%"+query+"%").Find(&tables).Error; err != nil {
+	tables, err := repository.FindByIDs(hitIDs(hits))
+	if err != nil {
 		return nil, err
 	}
-	return tables, nil
+	byID := make(map[uint]models.Table)
+	for _, table := range tables {
+		byID[table.ID] = table
+	}
+
+	results := []TableSearchResult{}
+	for _, hit := range hits {
+		if table, ok := byID[hit.ID]; ok {
+			results = append(results, TableSearchResult{Table: table, Rank: hit.Rank, Snippet: hit.Snippet})
+		}
+	}
+	return results, nil
 }
 
-// get recommended tables for a particular restaurant which is the 5 most recent tables
-func (s *TableService) GetRecommendedTables(restaurantID uint) ([]models.Table, error) {
-	var tables []models.Table
-	if err := config.DB.Where("restaurant_id = ?", restaurantID).Order("id desc").Limit(5).Find(&tables).Error; err != nil {
+// get recommended tables of a restaurant for a user, as computed by the recommendation job.
+// Until the job has run for the restaurant the best rated tables are returned
+func (s *TableService) GetRecommendedTables(ctx context.Context, userID uint, restaurantID uint) ([]models.Table, error) {
+	ids, err := s.recommendations.WithContext(ctx).ItemIDs(userID, restaurantID, "table", recommendedLimit)
+	if err != nil {
 		return nil, err
 	}
+	repository := s.tables.WithContext(ctx)
+	if len(ids) == 0 {
+		return repository.BestRated(restaurantID, recommendedLimit)
+	}
+
+	tables, err := repository.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Table)
+	for _, table := range tables {
+		byID[table.ID] = table
+	}
+	tables = []models.Table{}
+	for _, id := range ids {
+		if table, ok := byID[id]; ok {
+			tables = append(tables, table)
+		}
+	}
 	return tables, nil
 }
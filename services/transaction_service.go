@@ -1,49 +1,51 @@
 package services
 
 import (
-	"madang_api/config"
 	"madang_api/models"
+	"madang_api/repositories"
+	"madang_api/utils"
 )
 
-type TransactionService struct{}
+type TransactionService struct {
+	transactions repositories.TransactionRepository
+}
+
+func NewTransactionService(transactions repositories.TransactionRepository) *TransactionService {
+	return &TransactionService{transactions: transactions}
+}
 
-func (s *TransactionService) GetTransactions() ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	result := config.DB.Find(&transactions)
-	return transactions, result.Error
+func (s *TransactionService) GetTransactions(query *utils.ListQuery) ([]models.Transaction, *utils.Pagination, error) {
+	return s.transactions.List(query)
 }
 
 func (s *TransactionService) GetTransaction(id uint) (models.Transaction, error) {
-	var transaction models.Transaction
-	result := config.DB.First(&transaction, id)
-	return transaction, result.Error
+	transaction, err := s.transactions.FindByID(id)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	return *transaction, nil
 }
 
 func (s *TransactionService) CreateTransaction(transaction *models.Transaction) (*models.Transaction, error) {
-	result := config.DB.Create(transaction)
-	if result.Error != nil {
-		return nil, result.Error
+	if err := s.transactions.Create(transaction); err != nil {
+		return nil, err
 	}
 
 	return transaction, nil
 }
 
 func (s *TransactionService) UpdateTransaction(transaction *models.Transaction) (*models.Transaction, error) {
-	result := config.DB.Save(transaction)
-	if result.Error != nil {
-		return nil, result.Error
+	if err := s.transactions.Save(transaction); err != nil {
+		return nil, err
 	}
 
 	return transaction, nil
 }
 
 func (s *TransactionService) DeleteTransaction(id uint) error {
-	result := config.DB.Delete(&models.Transaction{}, id)
-	return result.Error
+	return s.transactions.Delete(id)
 }
 
 func (s *TransactionService) GetRestaurantTransactions(restaurantID uint) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	result := config.DB.Where("restaurant_id = ?", restaurantID).Find(&transactions)
-	return transactions, result.Error
+	return s.transactions.FindByRestaurant(restaurantID)
 }
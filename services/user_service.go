@@ -1,24 +1,47 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"madang_api/config"
+	"madang_api/metrics"
 	"madang_api/models"
-	"os"
+	"madang_api/repositories"
+	"madang_api/tracing"
+	"madang_api/utils"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-type UserService struct{}
+// ErrInvalidCredentials refuses a login. Unknown emails, wrong passwords and locked accounts all get it, so
+// the answer does not tell which emails have an account
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// unknownUserHash is checked the password against when there is no account to check it against, so a
+// login takes as long whether the email is known or not
+var unknownUserHash, _ = bcrypt.GenerateFromPassword([]byte("no account has this password"), 10)
+
+type UserService struct {
+	config *config.Config // signs the access tokens
+	users  repositories.UserRepository
+}
+
+func NewUserService(cfg *config.Config, users repositories.UserRepository) *UserService {
+	return &UserService{config: cfg, users: users}
+}
 
 // RegisterUser creates a new user record
-func (s *UserService) RegisterUser(user *models.User) error {
-	var existingUser models.User
-	config.DB.Where("email = ?", user.Email).First(&existingUser)
+func (s *UserService) RegisterUser(ctx context.Context, user *models.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.RegisterUser")
+	defer tracing.End(span, &err)
+	users := s.users.WithContext(ctx)
+
 	//check if email already exists
-	if existingUser.Email != "" {
+	if _, err := users.FindByEmail(user.Email); err == nil {
 		return errors.New("email already exists")
 	}
 
@@ -31,22 +54,23 @@ func (s *UserService) RegisterUser(user *models.User) error {
 	user.Password = string(hashedPassword)
 
 	newUser := models.User{Name: user.Name, Email: user.Email, Phone: user.Phone, Password: user.Password, Avatar: user.Avatar, Role: user.Role, Active: user.Active, EmailVerificationOTP: user.EmailVerificationOTP}
-	result := config.DB.Create(&newUser)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+	return users.Create(&newUser)
 
 }
 
 // Implement email otp validation
-func (s *UserService) VerifyEmailOTP(email string, otp string) (*models.User, error) {
-	var user models.User
-	result := config.DB.Where("email = ?", email).First(&user)
-	if result.Error != nil {
-		return nil, result.Error
+func (s *UserService) VerifyEmailOTP(ctx context.Context, email string, otp string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.VerifyEmailOTP")
+	defer tracing.End(span, &err)
+	users := s.users.WithContext(ctx)
+
+	user, err := users.FindByEmail(email)
+	if err != nil {
+		metrics.OTPVerified(metrics.OutcomeUnknownEmail)
+		return nil, err
 	}
 	if user.EmailVerificationOTP != otp {
+		metrics.OTPVerified(metrics.OutcomeInvalidOTP)
 		return nil, errors.New("invalid OTP")
 	}
 
@@ -57,7 +81,7 @@ func (s *UserService) VerifyEmailOTP(email string, otp string) (*models.User, er
 	})
 
 	// Sign and get the complete encoded token as a string using the secret
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	tokenString, err := token.SignedString([]byte(s.config.JWTSecret))
 
 	if err != nil {
 		return nil, err
@@ -67,25 +91,44 @@ func (s *UserService) VerifyEmailOTP(email string, otp string) (*models.User, er
 	user.EmailVerified = true
 	user.Active = true
 	user.EmailVerificationOTP = ""
-	result = config.DB.Save(&user)
-	return &user, nil
+	if err := users.Save(user); err != nil {
+		return nil, err
+	}
+	metrics.OTPVerified(metrics.OutcomeVerified)
+	return user, nil
 }
 
-// LoginUser authenticates a user and returns the user object if successful with token generated and stored in the user model
-func (s *UserService) LoginUser(email string, password string) (*models.User, error) {
-	var user models.User
-	result := config.DB.Where("email = ?", email).First(&user)
-	if result.Error != nil {
-		return nil, result.Error
+// LoginUser authenticates a user and returns the user object if successful with token generated and stored in the user model.
+// Wrong passwords in a row lock the account for a while, see failLogin
+func (s *UserService) LoginUser(ctx context.Context, email string, password string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.LoginUser")
+	defer tracing.End(span, &err)
+
+	users := s.users.WithContext(ctx)
+	user, err := users.FindByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = bcrypt.CompareHashAndPassword(unknownUserHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
 	}
-	//check if email is verified
+	//check if password is correct. A locked account refuses even the right one
+	passwordErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, ErrInvalidCredentials
+	}
+	if passwordErr != nil {
+		return nil, s.failLogin(ctx, user)
+	}
+	//check if email is verified, only once the password proved who is asking
 	if !user.EmailVerified {
 		return nil, errors.New("email not verified")
 	}
-	//check if password is correct
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
-		return nil, errors.New("invalid credentials")
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := users.ResetFailedLogins(user.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	//generate for the user a jwt token
@@ -95,52 +138,52 @@ func (s *UserService) LoginUser(email string, password string) (*models.User, er
 	})
 
 	// Sign and get the complete encoded token as a string using the secret
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	tokenString, err := token.SignedString([]byte(s.config.JWTSecret))
 
 	if err != nil {
 		return nil, err
 	}
 	//store the token in the user model
 	user.Token = tokenString
-	return &user, nil
+	return user, nil
 }
 
-// GetUserByID retrieves a user by ID
-func (s *UserService) GetUserByID(id uint) (*models.User, error) {
-	var user models.User
-	result := config.DB.First(&user, id)
-	if result.Error != nil {
-		return nil, result.Error
+// failLogin counts a wrong password for the user and locks the account on the LoginMaxFailures-th in a
+// row, for LoginLockout. It returns the error the login fails with
+func (s *UserService) failLogin(ctx context.Context, user *models.User) error {
+	if s.config.LoginMaxFailures == 0 {
+		return ErrInvalidCredentials
+	}
+	lockedUntil, err := s.users.WithContext(ctx).RecordFailedLogin(user.ID, int(s.config.LoginMaxFailures), time.Now().Add(s.config.LoginLockout))
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil && time.Now().Before(*lockedUntil) {
+		slog.WarnContext(ctx, "Account locked after too many failed logins", "user_id", user.ID, "until", lockedUntil.UTC().Format(time.RFC3339))
 	}
-	return &user, nil
+	return ErrInvalidCredentials
+}
+
+// GetUserByID retrieves a user by ID
+func (s *UserService) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return s.users.WithContext(ctx).FindByID(id)
 }
 
 // UpdateUser updates a user record
-func (s *UserService) UpdateUser(user *models.User) error {
-	result := config.DB.Save(user)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+func (s *UserService) UpdateUser(ctx context.Context, user *models.User) error {
+	return s.users.WithContext(ctx).Save(user)
 }
 
 // DeleteUser deletes a user record
-func (s *UserService) DeleteUser(id uint) error {
-	var user models.User
-	result := config.DB.First(&user, id)
-	if result.Error != nil {
-		return result.Error
-	}
-	config.DB.Delete(&user)
-	return nil
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	users := s.users.WithContext(ctx)
+	if _, err := users.FindByID(id); err != nil {
+		return err
+	}
+	return users.Delete(id)
 }
 
-// GetAllUsers retrieves a list of users
-func (s *UserService) GetAllUsers() ([]models.User, error) {
-	var users []models.User
-	result := config.DB.Find(&users)
-	if result.Error != nil {
-		return nil, result.Error
-	}
-	return users, nil
+// GetAllUsers retrieves a page of users matching the query
+func (s *UserService) GetAllUsers(ctx context.Context, query *utils.ListQuery) ([]models.User, *utils.Pagination, error) {
+	return s.users.WithContext(ctx).List(query)
 }
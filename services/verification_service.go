@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"madang_api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VerificationService struct {
+	db *gorm.DB
+}
+
+func NewVerificationService(db *gorm.DB) *VerificationService {
+	return &VerificationService{db: db}
+}
+
+// RestaurantVerificationHistory is everything that happened to a restaurant's verification
+type RestaurantVerificationHistory struct {
+	RestaurantID       uint                             `json:"restaurant_id"`
+	VerificationStatus string                           `json:"verification_status"`
+	Verified           bool                             `json:"verified"`
+	VerifiedAt         *time.Time                       `json:"verified_at"`
+	Active             bool                             `json:"active"`
+	Submissions        []models.RestaurantVerification  `json:"submissions"`
+	StatusHistory      []models.RestaurantStatusHistory `json:"status_history"`
+}
+
+// SubmitVerification lets the manager of a restaurant send its details and documents for review
+func (s *VerificationService) SubmitVerification(verification *models.RestaurantVerification) (*models.RestaurantVerification, error) {
+	var restaurant models.Restaurant
+	if err := s.db.First(&restaurant, verification.RestaurantID).Error; err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	if restaurant.UserID != verification.SubmittedBy {
+		return nil, errors.New("only the manager of the restaurant can request its verification")
+	}
+	if len(verification.Documents) == 0 {
+		return nil, errors.New("at least one document is required")
+	}
+	for _, document := range verification.Documents {
+		if document.Type == "" || document.URL == "" {
+			return nil, errors.New("every document needs a type and a url")
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("restaurant_id = ? AND status = ?", restaurant.ID, "pending").First(&models.RestaurantVerification{}).Error; err == nil {
+			return errors.New("a verification request is already waiting for review")
+		}
+
+		verification.ID = 0
+		verification.Status = "pending"
+		verification.ReviewedBy = nil
+		verification.ReviewedAt = nil
+		verification.Reason = ""
+		if err := tx.Omit("Restaurant").Create(verification).Error; err != nil {
+			return err
+		}
+
+		if err := changeVerificationStatus(tx, &restaurant, "pending", verification.ID, verification.SubmittedBy, "verification requested"); err != nil {
+			return err
+		}
+		return notify(tx, restaurant.UserID, "verification_submitted", "Verification request received",
+			fmt.Sprintf("We received the verification request for %s and will review it shortly.", restaurant.Name))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetVerification(verification.ID)
+}
+
+// GetVerification retrieves a verification request with its documents
+func (s *VerificationService) GetVerification(id uint) (*models.RestaurantVerification, error) {
+	var verification models.RestaurantVerification
+	if err := s.db.Preload("Documents").Preload("Restaurant").First(&verification, id).Error; err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// GetReviewQueue retrieves the verification requests with the given status, oldest first so they are reviewed in order
+func (s *VerificationService) GetReviewQueue(status string) ([]models.RestaurantVerification, error) {
+	if status == "" {
+		status = "pending"
+	}
+	var verifications []models.RestaurantVerification
+	if err := s.db.Where("status = ?", status).Preload("Documents").Preload("Restaurant").Order("created_at, id").Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// GetRestaurantVerificationHistory retrieves the submissions and status changes of a restaurant
+func (s *VerificationService) GetRestaurantVerificationHistory(restaurantID uint) (*RestaurantVerificationHistory, error) {
+	var restaurant models.Restaurant
+	if err := s.db.First(&restaurant, restaurantID).Error; err != nil {
+		return nil, err
+	}
+
+	history := &RestaurantVerificationHistory{
+		RestaurantID:       restaurant.ID,
+		VerificationStatus: restaurant.VerificationStatus,
+		Verified:           restaurant.Verified,
+		VerifiedAt:         restaurant.VerifiedAt,
+		Active:             restaurant.Active,
+	}
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Preload("Documents").Order("id desc").Find(&history.Submissions).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("restaurant_id = ?", restaurantID).Order("id desc").Find(&history.StatusHistory).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ApproveVerification marks a restaurant verified. VerifiedAt is always set by the server
+func (s *VerificationService) ApproveVerification(id uint, adminID uint, reason string) (*models.RestaurantVerification, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		verification, restaurant, err := lockPendingVerification(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(verification).Updates(map[string]interface{}{
+			"status":      "approved",
+			"reviewed_by": adminID,
+			"reviewed_at": now,
+			"reason":      reason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(restaurant).Updates(map[string]interface{}{
+			"verified":    true,
+			"verified_at": now,
+			"active":      true,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := changeVerificationStatus(tx, restaurant, "approved", verification.ID, adminID, reason); err != nil {
+			return err
+		}
+		return notify(tx, restaurant.UserID, "verification_approved", "Restaurant verified",
+			fmt.Sprintf("%s has been verified and is now active.", restaurant.Name))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetVerification(id)
+}
+
+// RejectVerification turns down a verification request. A reason is required so the manager knows what to fix
+func (s *VerificationService) RejectVerification(id uint, adminID uint, reason string) (*models.RestaurantVerification, error) {
+	if reason == "" {
+		return nil, errors.New("a reason is required to reject a verification")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		verification, restaurant, err := lockPendingVerification(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(verification).Updates(map[string]interface{}{
+			"status":      "rejected",
+			"reviewed_by": adminID,
+			"reviewed_at": time.Now(),
+			"reason":      reason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(restaurant).Updates(map[string]interface{}{
+			"verified":    false,
+			"verified_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := changeVerificationStatus(tx, restaurant, "rejected", verification.ID, adminID, reason); err != nil {
+			return err
+		}
+		return notify(tx, restaurant.UserID, "verification_rejected", "Verification rejected",
+			fmt.Sprintf("The verification request for %s was rejected: %s", restaurant.Name, reason))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetVerification(id)
+}
+
+// SetRestaurantActive lets an admin suspend or reactivate a restaurant
+func (s *VerificationService) SetRestaurantActive(restaurantID uint, active bool, adminID uint, reason string) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&restaurant, restaurantID).Error; err != nil {
+			return err
+		}
+		if restaurant.Active == active {
+			return nil
+		}
+
+		from, to, title := "active", "inactive", "Restaurant suspended"
+		if active {
+			from, to, title = "inactive", "active", "Restaurant reactivated"
+		}
+		if err := tx.Model(&restaurant).Update("active", active).Error; err != nil {
+			return err
+		}
+		history := models.RestaurantStatusHistory{
+			RestaurantID: restaurant.ID,
+			FromStatus:   from,
+			ToStatus:     to,
+			ChangedBy:    adminID,
+			Reason:       reason,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		return notify(tx, restaurant.UserID, "restaurant_"+to, title,
+			fmt.Sprintf("%s is now %s. %s", restaurant.Name, to, reason))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+// lockPendingVerification loads a verification and its restaurant for update, making sure it is still waiting for review
+func lockPendingVerification(tx *gorm.DB, id uint) (*models.RestaurantVerification, *models.Restaurant, error) {
+	var verification models.RestaurantVerification
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&verification, id).Error; err != nil {
+		return nil, nil, err
+	}
+	if verification.Status != "pending" {
+		return nil, nil, fmt.Errorf("verification has already been %s", verification.Status)
+	}
+
+	var restaurant models.Restaurant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&restaurant, verification.RestaurantID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &verification, &restaurant, nil
+}
+
+// changeVerificationStatus moves a restaurant to a new verification status and records it in the history
+func changeVerificationStatus(tx *gorm.DB, restaurant *models.Restaurant, status string, verificationID uint, changedBy uint, reason string) error {
+	history := models.RestaurantStatusHistory{
+		RestaurantID:   restaurant.ID,
+		VerificationID: &verificationID,
+		FromStatus:     restaurant.VerificationStatus,
+		ToStatus:       status,
+		ChangedBy:      changedBy,
+		Reason:         reason,
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return err
+	}
+	restaurant.VerificationStatus = status
+	return tx.Model(restaurant).Update("verification_status", status).Error
+}
@@ -0,0 +1,106 @@
+package testutil
+
+import (
+	"testing"
+
+	"madang_api/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Password is the password of every fixture user
+const Password = "password123"
+
+// Fixtures are the rows every test starts with: one verified user per role and an open, verified
+// restaurant of the manager with a category, a food, a table and an addon
+type Fixtures struct {
+	Customer   models.User
+	Manager    models.User
+	Admin      models.User
+	Restaurant models.Restaurant
+	Category   models.Category
+	Food       models.Food
+	Table      models.Table
+	Addon      models.Addon
+}
+
+// User returns the fixture user with the given role
+func (f *Fixtures) User(role string) models.User {
+	switch role {
+	case "manager":
+		return f.Manager
+	case "admin":
+		return f.Admin
+	default:
+		return f.Customer
+	}
+}
+
+// AddUser creates a verified user with the role besides the fixture ones, who logs in with Password
+func (h *Harness) AddUser(t *testing.T, email string, role string) models.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Name: email, Email: email, Password: string(hash), Role: role, Active: true, EmailVerified: true}
+	if err := h.DB.Create(&user).Error; err != nil {
+		t.Fatalf("creating the user %s: %v", email, err)
+	}
+	return user
+}
+
+func seedFixtures(db *gorm.DB) (*Fixtures, error) {
+	// the lowest cost keeps the logins of the tests fast
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fixtures{}
+	user := func(name string, role string) models.User {
+		return models.User{Name: name, Email: role + "@madang.test", Password: string(hash), Role: role, Active: true, EmailVerified: true}
+	}
+	f.Customer = user("Test Customer", "customer")
+	f.Manager = user("Test Manager", "manager")
+	f.Admin = user("Test Admin", "admin")
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, u := range []*models.User{&f.Customer, &f.Manager, &f.Admin} {
+			if err := tx.Create(u).Error; err != nil {
+				return err
+			}
+		}
+
+		// without opening hours the restaurant is always open
+		latitude, longitude := 37.5665, 126.9780
+		f.Restaurant = models.Restaurant{
+			Name: "Test Restaurant", Address: "1 Test Street", Location: "Seoul", State: "Seoul", Country: "KR",
+			UserID: f.Manager.ID, Latitude: &latitude, Longitude: &longitude, Timezone: "UTC",
+			Active: true, Verified: true, VerificationStatus: "approved",
+		}
+		if err := tx.Create(&f.Restaurant).Error; err != nil {
+			return err
+		}
+
+		f.Category = models.Category{Name: "Mains", Type: "food", RestaurantID: f.Restaurant.ID}
+		if err := tx.Create(&f.Category).Error; err != nil {
+			return err
+		}
+		f.Food = models.Food{Name: "Bibimbap", Description: "Rice with vegetables", Price: 12, RestaurantID: f.Restaurant.ID, CategoryId: f.Category.ID, Available: true}
+		if err := tx.Create(&f.Food).Error; err != nil {
+			return err
+		}
+		f.Table = models.Table{Name: "Window", Number: 1, Capacity: 4, Price: 5, RestaurantID: f.Restaurant.ID, CategoryId: f.Category.ID}
+		if err := tx.Create(&f.Table).Error; err != nil {
+			return err
+		}
+		f.Addon = models.Addon{Name: "Flowers", Type: "flower", Price: 3, RestaurantID: f.Restaurant.ID}
+		return tx.Create(&f.Addon).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,157 @@
+// Package testutil runs the API end to end for the integration tests. It starts an isolated Postgres,
+// applies the migrations, seeds the fixtures and builds the router exactly as main does
+package testutil
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"madang_api/app"
+	"madang_api/config"
+	"madang_api/migrations"
+	"madang_api/seed"
+	"madang_api/tracing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Harness is a running API on a freshly seeded database
+type Harness struct {
+	DB        *gorm.DB
+	Container *app.Container
+	Router    *gin.Engine
+	Fixtures  Fixtures
+}
+
+// database is the Postgres shared by every test of the package, started on first use
+var database struct {
+	once     sync.Once
+	db       *gorm.DB
+	embedded *embeddedpostgres.EmbeddedPostgres
+	err      error
+}
+
+// Main runs the tests of a package and stops the embedded database afterwards. Call it from TestMain
+func Main(m *testing.M) {
+	code := m.Run()
+	if database.embedded != nil {
+		if err := database.embedded.Stop(); err != nil {
+			log.Printf("Failed to stop the test database: %v", err)
+		}
+	}
+	os.Exit(code)
+}
+
+// New gives the test an API on a database holding only the fixtures, with the configuration changed by
+// configure when the test needs to, e.g. to turn the rate limits on. The test is skipped when no database
+// can be started, unless TEST_DATABASE_URL names one. Tests using it must not run in parallel since they share the database
+func New(t *testing.T, configure ...func(cfg *config.Config)) *Harness {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration test skipped in short mode")
+	}
+
+	database.once.Do(func() {
+		database.db, database.err = open()
+	})
+	if database.err != nil {
+		// a database that was asked for (as in CI) must not turn the tests into silent skips
+		if os.Getenv("TEST_DATABASE_URL") != "" {
+			t.Fatalf("opening TEST_DATABASE_URL: %v", database.err)
+		}
+		t.Skipf("no test database: %v", database.err)
+	}
+
+	if err := seed.Reset(database.db); err != nil {
+		t.Fatalf("resetting the test database: %v", err)
+	}
+	fixtures, err := seedFixtures(database.db)
+	if err != nil {
+		t.Fatalf("seeding the test database: %v", err)
+	}
+
+	cfg := &config.Config{
+		Env:                    "test",
+		JWTSecret:              "test-jwt-secret",
+		CursorSecret:           "test-cursor-secret",
+		RecommendationInterval: time.Hour,
+		FeedSectionTimeout:     2 * time.Second,
+		FeedCacheTTL:           time.Minute,
+	}
+	for _, change := range configure {
+		change(cfg)
+	}
+	container := app.NewContainer(cfg, database.db)
+	return &Harness{DB: database.db, Container: container, Router: container.Router(), Fixtures: *fixtures}
+}
+
+// open connects to TEST_DATABASE_URL when it is set, otherwise to a new embedded Postgres, and migrates it
+func open() (*gorm.DB, error) {
+	gin.SetMode(gin.TestMode)
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		var err error
+		if dsn, err = startEmbedded(); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	if _, err := migrations.Up(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// startEmbedded downloads (once, into the user cache) and starts a Postgres on a free port
+func startEmbedded() (string, error) {
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	runtime, err := os.MkdirTemp("", "madang-test-db")
+	if err != nil {
+		return "", err
+	}
+
+	embedded := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(port).
+		Database("madang_test").
+		Username("madang").
+		Password("madang").
+		RuntimePath(runtime).
+		StartTimeout(time.Minute).
+		Logger(io.Discard))
+	if err := embedded.Start(); err != nil {
+		os.RemoveAll(runtime)
+		return "", fmt.Errorf("starting embedded postgres: %w", err)
+	}
+	database.embedded = embedded
+	return fmt.Sprintf("host=localhost port=%d user=madang password=madang dbname=madang_test sslmode=disable", port), nil
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,101 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Response is a recorded response of the API with its envelope decoded
+type Response struct {
+	Code       int             `json:"-"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Error      string          `json:"error"`
+	Data       json.RawMessage `json:"data"`
+	Pagination json.RawMessage `json:"pagination"`
+	RequestID  string          `json:"request_id"`
+	Header     http.Header     `json:"-"`
+	Body       string          `json:"-"`
+}
+
+// Request sends a request to the router, with the token as bearer when it is not empty and the body
+// encoded as JSON when it is not nil
+func (h *Harness) Request(t *testing.T, method string, path string, token string, body interface{}) *Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encoding the body of %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(content)
+	}
+	request := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return h.Do(t, request)
+}
+
+// Do sends a request built by the test to the router
+func (h *Harness) Do(t *testing.T, request *http.Request) *Response {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	h.Router.ServeHTTP(recorder, request)
+
+	response := &Response{Code: recorder.Code, Header: recorder.Header(), Body: recorder.Body.String()}
+	if recorder.Body.Len() > 0 {
+		// not every response is an envelope, the raw body stays available
+		_ = json.Unmarshal(recorder.Body.Bytes(), response)
+	}
+	return response
+}
+
+// LoginAs logs in the fixture user with the given role through the API and returns its token
+func (h *Harness) LoginAs(t *testing.T, role string) string {
+	t.Helper()
+	return h.Login(t, h.Fixtures.User(role).Email)
+}
+
+// Login logs in the user with the email and the fixture password through the API and returns its token
+func (h *Harness) Login(t *testing.T, email string) string {
+	t.Helper()
+
+	response := h.Request(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": email, "password": Password})
+	response.Expect(t, http.StatusOK)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	response.Decode(t, &login)
+	if login.Token == "" {
+		t.Fatalf("login of %s returned no token: %s", email, response.Body)
+	}
+	return login.Token
+}
+
+// Expect fails the test when the response does not have the status
+func (r *Response) Expect(t *testing.T, status int) *Response {
+	t.Helper()
+	if r.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, r.Code, r.Body)
+	}
+	return r
+}
+
+// Decode decodes the data of the response into v
+func (r *Response) Decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		t.Fatalf("decoding the data of the response: %v: %s", err, r.Body)
+	}
+}
@@ -0,0 +1,90 @@
+// Package tracing sets up OpenTelemetry tracing. Spans are started by the tracing middleware for every
+// request, by the services on the checkout and authentication paths and by GORM for every query run with
+// the context of a traced request. The trace context of incoming requests is taken from their traceparent
+// header, so the spans join the trace of the caller
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"madang_api/buildinfo"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName = "madang_api"
+	tracerName  = "madang_api"
+)
+
+// Setup exports the spans with the exporter: "otlp" sends them over HTTP to endpoint (the OTLP
+// environment variables apply when it is empty), "stdout" writes them to the standard output and "none"
+// records nothing. The returned function flushes the spans left and stops the exporter
+func Setup(ctx context.Context, exporter string, endpoint string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("creating the stdout exporter: %w", err)
+		}
+		spanExporter = stdout
+	case "otlp":
+		var options []otlptracehttp.Option
+		if endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		otlp, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("creating the OTLP exporter: %w", err)
+		}
+		spanExporter = otlp
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %q, expected otlp, stdout or none", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(buildinfo.Get().Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer is the tracer of the application, from the provider set up last
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span named name as a child of the span of ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, marking it failed when *err is set. Defer it with the address of the named error
+// returned by the function
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cursorPosition is the last row of a page, the next page starts right after it
+type cursorPosition struct {
+	CreatedAt  *time.Time `json:"t,omitempty"` // only set for lists paged by (created_at, id)
+	ID         uint       `json:"id"`
+	Descending bool       `json:"d,omitempty"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// CursorSigner signs the cursors with the key of one API, so clients cannot forge positions. The
+// middlewares attach it to every request with SetCursorSigner and ParseListQuery picks it up
+type CursorSigner struct {
+	secret []byte
+}
+
+func NewCursorSigner(secret string) CursorSigner {
+	return CursorSigner{secret: []byte(secret)}
+}
+
+const cursorSignerKey = "cursor_signer"
+
+// SetCursorSigner makes the lists of the request sign their cursors with signer
+func SetCursorSigner(c *gin.Context, signer CursorSigner) {
+	c.Set(cursorSignerKey, signer)
+}
+
+func (s CursorSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encode turns a position into an opaque "payload.signature" cursor
+func (s CursorSigner) encode(position cursorPosition) string {
+	raw, _ := json.Marshal(position)
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + s.sign(payload)
+}
+
+// decode checks the signature of a cursor and returns its position
+func (s CursorSigner) decode(cursor string) (*cursorPosition, error) {
+	payload, signature, ok := strings.Cut(cursor, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return nil, errInvalidCursor
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var position cursorPosition
+	if err := json.Unmarshal(raw, &position); err != nil || position.ID == 0 {
+		return nil, errInvalidCursor
+	}
+	return &position, nil
+}
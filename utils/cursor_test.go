@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	signer := NewCursorSigner("test-secret")
+	createdAt := time.Date(2024, 1, 3, 12, 30, 0, 0, time.UTC)
+
+	for _, position := range []cursorPosition{
+		{ID: 42},
+		{ID: 7, Descending: true},
+		{ID: 9, CreatedAt: &createdAt, Descending: true},
+	} {
+		decoded, err := signer.decode(signer.encode(position))
+		if err != nil {
+			t.Fatalf("decoding the cursor of %+v: %v", position, err)
+		}
+		if decoded.ID != position.ID || decoded.Descending != position.Descending || (decoded.CreatedAt == nil) != (position.CreatedAt == nil) {
+			t.Fatalf("expected %+v, got %+v", position, decoded)
+		}
+		if position.CreatedAt != nil && !decoded.CreatedAt.Equal(*position.CreatedAt) {
+			t.Fatalf("expected the cursor at %s, got %s", position.CreatedAt, decoded.CreatedAt)
+		}
+	}
+}
+
+func TestCursorRejectsForgeries(t *testing.T) {
+	signer := NewCursorSigner("test-secret")
+	cursor := signer.encode(cursorPosition{ID: 42})
+	payload, signature, _ := strings.Cut(cursor, ".")
+	forged := signer.encode(cursorPosition{ID: 1000})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	invalid := map[string]string{
+		"empty":             "",
+		"no signature":      payload,
+		"garbage":           "not-a-cursor",
+		"other payload":     forgedPayload + "." + signature,
+		"altered signature": payload + "." + strings.ToUpper(signature),
+		"zero id":           signer.encode(cursorPosition{}),
+	}
+	for name, cursor := range invalid {
+		if _, err := signer.decode(cursor); err != errInvalidCursor {
+			t.Errorf("%s: expected an invalid cursor, got %v", name, err)
+		}
+	}
+
+	// rotating the secret invalidates the cursors handed out before
+	if _, err := NewCursorSigner("rotated-secret").decode(cursor); err != errInvalidCursor {
+		t.Errorf("expected the cursor signed with the old secret to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListSpec whitelists what a list endpoint can be sorted and filtered by. The keys are the names used
+// in the query string and the values the columns they map to
+type ListSpec struct {
+	Sorts       map[string]string
+	Filters     map[string]Filter
+	DefaultSort string // e.g. "-created_at", a leading "-" sorts descending
+	// Keyset lists are paged by cursor on (created_at, id), newest first, unless a page number is asked for.
+	// Used for busy tables where rows inserted while scrolling would shift offset pages
+	Keyset bool
+}
+
+// ListQuery is the page, sort and filters a client asked for, already checked against a ListSpec.
+// Either Page or Cursor is used: a request with a cursor parameter (even an empty one) is paged by cursor
+type ListQuery struct {
+	Page       int
+	PageSize   int
+	UseCursor  bool
+	Cursor     string
+	keyset     bool
+	after      *cursorPosition
+	cursors    *CursorSigner
+	descending bool
+	sorts      []string
+	filters    []listFilter
+}
+
+type listFilter struct {
+	column string
+	values []interface{}
+}
+
+// FilterType is the type of the values a filter column holds
+type FilterType int
+
+const (
+	TextFilter FilterType = iota
+	IDFilter
+	IntFilter
+	BoolFilter
+)
+
+// Filter is a column a list can be filtered by. Its values are parsed to the column type when the
+// query is parsed, so a malformed one is a bad request rather than a database error
+type Filter struct {
+	Column string
+	Type   FilterType
+}
+
+func TextColumn(column string) Filter { return Filter{Column: column, Type: TextFilter} }
+func IDColumn(column string) Filter   { return Filter{Column: column, Type: IDFilter} }
+func IntColumn(column string) Filter  { return Filter{Column: column, Type: IntFilter} }
+func BoolColumn(column string) Filter { return Filter{Column: column, Type: BoolFilter} }
+
+// parse converts one value given in the query string for the filter name
+func (f Filter) parse(name string, raw string) (interface{}, error) {
+	switch f.Type {
+	case IDFilter:
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("%s must be a positive number, got %q", name, raw)
+		}
+		return uint(id), nil
+	case IntFilter:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", name, raw)
+		}
+		return value, nil
+	case BoolFilter:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", name, raw)
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Pagination is the metadata returned next to a page of results
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ParseListQuery reads page, page_size, cursor, sort and the whitelisted filters from the query string.
+// sort is a comma separated list of fields, each optionally prefixed with "-" for descending order, and
+// a filter given as a comma separated list matches any of the values
+func ParseListQuery(c *gin.Context, spec ListSpec) (*ListQuery, error) {
+	query := &ListQuery{Page: 1, PageSize: DefaultPageSize}
+	if signer, ok := c.Get(cursorSignerKey); ok {
+		cursors := signer.(CursorSigner)
+		query.cursors = &cursors
+	}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return nil, errors.New("page must be a positive number")
+		}
+		query.Page = page
+	}
+	if raw := c.Query("page_size"); raw != "" {
+		pageSize, err := strconv.Atoi(raw)
+		if err != nil || pageSize < 1 {
+			return nil, errors.New("page_size must be a positive number")
+		}
+		if pageSize > MaxPageSize {
+			pageSize = MaxPageSize
+		}
+		query.PageSize = pageSize
+	}
+
+	sorting := c.Query("sort")
+	if sorting == "" {
+		sorting = spec.DefaultSort
+	}
+	for _, field := range strings.Split(sorting, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+		column, ok := spec.Sorts[field]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %q", field)
+		}
+		query.sorts = append(query.sorts, column+" "+direction)
+	}
+
+	names := make([]string, 0, len(spec.Filters))
+	for name := range spec.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw, ok := c.GetQuery(name)
+		if !ok || raw == "" {
+			continue
+		}
+		filter := spec.Filters[name]
+		var values []interface{}
+		for _, value := range strings.Split(raw, ",") {
+			parsed, err := filter.parse(name, strings.TrimSpace(value))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, parsed)
+		}
+		query.filters = append(query.filters, listFilter{column: filter.Column, values: values})
+	}
+
+	cursor, withCursor := c.GetQuery("cursor")
+	if spec.Keyset {
+		if c.Query("sort") == "" {
+			query.sorts = []string{"created_at DESC"}
+		}
+		// without a cursor, a page number or another sort order still get offset pages
+		if withCursor || (c.Query("page") == "" && len(query.sorts) == 1 && strings.HasPrefix(query.sorts[0], "created_at ")) {
+			if err := query.useKeysetCursor(cursor); err != nil {
+				return nil, err
+			}
+		}
+	} else if withCursor {
+		if c.Query("sort") == "" {
+			// the default sort is replaced by id in the same direction
+			query.sorts = []string{"id ASC"}
+			if strings.HasPrefix(spec.DefaultSort, "-") {
+				query.sorts = []string{"id DESC"}
+			}
+		}
+		if err := query.useCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	return query, nil
+}
+
+// useCursor switches the query to cursor paging. Cursors page through the rows by ID, so they cannot be
+// combined with another sort order
+func (q *ListQuery) useCursor(cursor string) error {
+	switch {
+	case len(q.sorts) == 0 || (len(q.sorts) == 1 && q.sorts[0] == "id ASC"):
+		q.descending = false
+	case len(q.sorts) == 1 && q.sorts[0] == "id DESC":
+		q.descending = true
+	default:
+		return errors.New("cursor paging only supports sorting by id")
+	}
+	return q.setCursor(cursor)
+}
+
+// useKeysetCursor switches the query to cursor paging on (created_at, id)
+func (q *ListQuery) useKeysetCursor(cursor string) error {
+	switch {
+	case len(q.sorts) == 1 && q.sorts[0] == "created_at ASC":
+		q.descending = false
+	case len(q.sorts) == 1 && q.sorts[0] == "created_at DESC":
+		q.descending = true
+	default:
+		return errors.New("cursor paging only supports sorting by created_at")
+	}
+	q.keyset = true
+	return q.setCursor(cursor)
+}
+
+// setCursor decodes the position to continue from. An empty cursor asks for the first page. Cursors are
+// only available to requests the middlewares attached a CursorSigner to
+func (q *ListQuery) setCursor(cursor string) error {
+	if q.cursors == nil {
+		return errors.New("cursor paging is not available")
+	}
+	q.UseCursor = true
+	q.Cursor = cursor
+	if cursor == "" {
+		return nil
+	}
+	position, err := q.cursors.decode(cursor)
+	if err != nil {
+		return err
+	}
+	// a cursor of another kind of list or of the opposite order would skip rows
+	if (position.CreatedAt != nil) != q.keyset || position.Descending != q.descending {
+		return errInvalidCursor
+	}
+	q.after = position
+	return nil
+}
+
+// Where restricts the list to rows whose column equals value, e.g. the restaurant of /restaurant/:id routes
+func (q *ListQuery) Where(column string, value interface{}) *ListQuery {
+	q.filters = append(q.filters, listFilter{column: column, values: []interface{}{value}})
+	return q
+}
+
+// Filter applies the filters to a query, without sorting or paging it
+func (q *ListQuery) Filter(db *gorm.DB) *gorm.DB {
+	for _, filter := range q.filters {
+		if len(filter.values) == 1 {
+			db = db.Where(filter.column+" = ?", filter.values[0])
+		} else {
+			db = db.Where(filter.column+" IN ?", filter.values)
+		}
+	}
+	return db
+}
+
+// Sort applies the requested order to a query. id is always the last sort key so pages are stable
+func (q *ListQuery) Sort(db *gorm.DB) *gorm.DB {
+	if q.UseCursor {
+		direction := "ASC"
+		if q.descending {
+			direction = "DESC"
+		}
+		if q.keyset {
+			return db.Order("created_at " + direction).Order("id " + direction)
+		}
+		return db.Order("id " + direction)
+	}
+	for _, order := range q.sorts {
+		db = db.Order(order)
+	}
+	return db.Order("id")
+}
+
+// Paginate filters, sorts and pages db into out and returns the pagination metadata. Preloads must be
+// given as scopes rather than on db, so they are not applied to the count
+func Paginate[T any](db *gorm.DB, q *ListQuery, out *[]T, scopes ...func(*gorm.DB) *gorm.DB) (*Pagination, error) {
+	db = q.Filter(db)
+	pagination := &Pagination{PageSize: q.PageSize}
+
+	if q.UseCursor {
+		if q.after != nil {
+			comparison := ">"
+			if q.descending {
+				comparison = "<"
+			}
+			if q.keyset {
+				db = db.Where("(created_at, id) "+comparison+" (?, ?)", *q.after.CreatedAt, q.after.ID)
+			} else {
+				db = db.Where("id "+comparison+" ?", q.after.ID)
+			}
+		}
+		// one extra row tells whether there is a next page
+		var rows []T
+		if err := q.Sort(db).Scopes(scopes...).Limit(q.PageSize + 1).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		if len(rows) > q.PageSize {
+			rows = rows[:q.PageSize]
+			pagination.HasMore = true
+		}
+		*out = rows
+		if pagination.HasMore {
+			pagination.NextCursor = q.nextCursor(reflect.ValueOf(rows[len(rows)-1]))
+		}
+		return pagination, nil
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	var rows []T
+	if err := q.Sort(db).Scopes(scopes...).Offset((q.Page - 1) * q.PageSize).Limit(q.PageSize).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	*out = rows
+	pagination.Page = q.Page
+	pagination.Total = &total
+	pagination.TotalPages = int(math.Ceil(float64(total) / float64(q.PageSize)))
+	pagination.HasMore = q.Page < pagination.TotalPages
+	return pagination, nil
+}
+
+// PaginateSlice pages results that had to be filtered in Go (such as restaurants open right now).
+// Cursor paging is not supported there, the page number is used instead
+func PaginateSlice[T any](items []T, q *ListQuery) ([]T, *Pagination) {
+	total := int64(len(items))
+	pagination := &Pagination{
+		Page:       q.Page,
+		PageSize:   q.PageSize,
+		Total:      &total,
+		TotalPages: int(math.Ceil(float64(total) / float64(q.PageSize))),
+	}
+	pagination.HasMore = q.Page < pagination.TotalPages
+
+	start := (q.Page - 1) * q.PageSize
+	if start >= len(items) {
+		return []T{}, pagination
+	}
+	end := start + q.PageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end], pagination
+}
+
+// nextCursor is the cursor of the page after the one ending with row
+func (q *ListQuery) nextCursor(row reflect.Value) string {
+	position := cursorPosition{ID: uint(row.FieldByName("ID").Uint()), Descending: q.descending}
+	if q.keyset {
+		createdAt := row.FieldByName("CreatedAt").Interface().(time.Time)
+		position.CreatedAt = &createdAt
+	}
+	return q.cursors.encode(position)
+}
+
+// PaginatedResponse is SuccessResponse with the pagination metadata of a list
+func PaginatedResponse(c *gin.Context, request int, message string, data interface{}, pagination *Pagination) {
+	c.JSON(request, gin.H{
+		"success":    true,
+		"message":    message,
+		"data":       data,
+		"pagination": pagination,
+	})
+}
+
+// ListQueryError answers a request whose list parameters are invalid
+func ListQueryError(c *gin.Context, err error) {
+	ErrorResponse(c, http.StatusBadRequest, "Invalid list parameters", err.Error())
+}
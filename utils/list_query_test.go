@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testListSpec = ListSpec{
+	Sorts:       map[string]string{"id": "id", "name": "name"},
+	Filters:     map[string]Filter{"status": TextColumn("status"), "user_id": IDColumn("user_id"), "active": BoolColumn("active")},
+	DefaultSort: "-id",
+}
+
+func parseTestQuery(t *testing.T, rawQuery string) (*ListQuery, error) {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?"+rawQuery, nil)
+	SetCursorSigner(c, NewCursorSigner("test-secret"))
+	return ParseListQuery(c, testListSpec)
+}
+
+func TestParseListQueryFilters(t *testing.T) {
+	query, err := parseTestQuery(t, "status=pending,confirmed&user_id=3&active=true&unknown=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(query.filters) != 3 {
+		t.Fatalf("expected the three whitelisted filters, got %+v", query.filters)
+	}
+	// filters are kept in the order of their names
+	active, status, user := query.filters[0], query.filters[1], query.filters[2]
+	if active.values[0] != true || len(status.values) != 2 || user.values[0] != uint(3) {
+		t.Fatalf("unexpected filter values %+v", query.filters)
+	}
+
+	for _, invalid := range []string{"user_id=abc", "user_id=3,x", "user_id=0", "active=maybe"} {
+		if _, err := parseTestQuery(t, invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestParseListQueryPaging(t *testing.T) {
+	query, err := parseTestQuery(t, "page=2&page_size=500&sort=name,-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Page != 2 || query.PageSize != MaxPageSize || len(query.sorts) != 2 || query.sorts[1] != "id DESC" {
+		t.Fatalf("unexpected query %+v", query)
+	}
+
+	for _, invalid := range []string{"page=0", "page_size=x", "sort=password", "cursor=&sort=name"} {
+		if _, err := parseTestQuery(t, invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestParseListQueryNeedsCursorSigner(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?cursor=", nil)
+	if _, err := ParseListQuery(c, testListSpec); err == nil {
+		t.Fatal("expected cursor paging to be refused without a signer")
+	}
+
+	// a cursor only pages the lists of the API that signed it
+	cursor := NewCursorSigner("other-secret").encode(cursorPosition{ID: 42})
+	if _, err := parseTestQuery(t, "sort=id&cursor="+cursor); err != errInvalidCursor {
+		t.Fatalf("expected the cursor of another signer to be refused, got %v", err)
+	}
+}
@@ -1,18 +1,24 @@
 package utils
 
 import (
+	"madang_api/logging"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func ErrorResponse(c *gin.Context, request int, message string, error string) {
-	c.JSON(request, gin.H{
-		"success": false,
-		"message": message,
-		"error":   error,
-	})
+	c.JSON(request, errorBody(c, message, error))
+}
+
+// ErrorResponseWithData is an error response that also carries data, e.g. what failed in detail
+func ErrorResponseWithData(c *gin.Context, request int, message string, error string, data interface{}) {
+	body := errorBody(c, message, error)
+	body["data"] = data
+	c.JSON(request, body)
 }
 
 func SuccessResponse(c *gin.Context, request int, message string, data interface{}) {
@@ -24,11 +30,37 @@ func SuccessResponse(c *gin.Context, request int, message string, data interface
 }
 
 func AbortResponse(c *gin.Context, request int, message string) {
-	c.AbortWithStatusJSON(request, gin.H{
+	AbortErrorResponse(c, request, message, "Unauthorized")
+}
+
+// AbortErrorResponse answers with an error and stops the handlers chain, for the middlewares
+func AbortErrorResponse(c *gin.Context, request int, message string, error string) {
+	c.AbortWithStatusJSON(request, errorBody(c, message, error))
+}
+
+// errorBody is the body of every error response. It carries the request ID so a failure reported by a
+// client can be found in the logs
+// TooManyRequests answers 429 with the Retry-After header in whole seconds, rounded up. It stops the
+// handlers chain, so the middlewares can use it too
+func TooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	AbortErrorResponse(c, http.StatusTooManyRequests, message, "Too Many Requests")
+}
+
+func errorBody(c *gin.Context, message string, error string) gin.H {
+	body := gin.H{
 		"success": false,
 		"message": message,
-		"error":   "Unauthorized",
-	})
+		"error":   error,
+	}
+	if requestID := logging.RequestID(c.Request.Context()); requestID != "" {
+		body["request_id"] = requestID
+	}
+	return body
 }
 
 // ValidateID ensures the ID parameter is present and valid, returning it as a uint
@@ -47,3 +79,19 @@ func ValidateID(c *gin.Context, param string) (uint, bool) {
 
 	return uint(parsedID), true
 }
+
+// OptionalQueryID reads an optional ID query parameter, returning 0 when it is absent
+func OptionalQueryID(c *gin.Context, param string) (uint, bool) {
+	id := c.Query(param)
+	if id == "" {
+		return 0, true
+	}
+
+	parsedID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid "+param+" parameter", err.Error())
+		return 0, false
+	}
+
+	return uint(parsedID), true
+}